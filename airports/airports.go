@@ -0,0 +1,167 @@
+package airports
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	// the time zones are embedded, so that the timelines don't depend on the zoneinfo of the host
+	_ "time/tzdata"
+)
+
+// Airport holds the reference data of an airport
+type Airport struct {
+	Code string
+	// Country is the ISO 3166-1 alpha-2 code of the country of the airport
+	Country string
+	// Zone is the IANA time zone of the airport
+	Zone      string
+	Latitude  float64
+	Longitude float64
+}
+
+var (
+	// iataCode matches the codes of the airports of the files
+	iataCode = regexp.MustCompile(`^[A-Z]{3}$`)
+	// countryCode matches the ISO 3166-1 alpha-2 codes
+	countryCode = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// Table holds the reference data of the airports, by IATA code. It is safe for concurrent use
+type Table struct {
+	// mu guards known, replaced by Add
+	mu    sync.RWMutex
+	known map[string]Airport
+}
+
+// NewTable returns a table of the airports of airports/data.go
+func NewTable() *Table {
+	return &Table{known: known}
+}
+
+// defaultTable is the table of the package functions, read by the paths, timelines and anomaly checks
+var defaultTable = NewTable()
+
+// Lookup returns the reference data of the airport with the IATA code
+func (t *Table) Lookup(code string) (Airport, bool) {
+	t.mu.RLock()
+	airport, ok := t.known[code]
+	t.mu.RUnlock()
+	if !ok {
+		return Airport{}, false
+	}
+
+	airport.Code = code
+	return airport, true
+}
+
+// Location returns the time zone of the airport with the IATA code
+func (t *Table) Location(code string) (*time.Location, error) {
+	airport, ok := t.Lookup(code)
+	if !ok {
+		return nil, fmt.Errorf("unknown time zone of airport %q", code)
+	}
+
+	return time.LoadLocation(airport.Zone)
+}
+
+// Add makes the airports known, replacing the reference data of those already known
+func (t *Table) Add(list []Airport) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// the table is copied, so that the lookups in progress read a consistent one
+	table := make(map[string]Airport, len(t.known)+len(list))
+	for code, airport := range t.known {
+		table[code] = airport
+	}
+	for _, airport := range list {
+		table[airport.Code] = airport
+	}
+	t.known = table
+}
+
+// Load reads an airports file from disk and adds its airports to the known ones
+func (t *Table) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading airports: %w", err)
+	}
+
+	list, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	t.Add(list)
+	return nil
+}
+
+// Lookup returns the reference data of the airport with the IATA code from the default table
+func Lookup(code string) (Airport, bool) {
+	return defaultTable.Lookup(code)
+}
+
+// Location returns the time zone of the airport with the IATA code from the default table
+func Location(code string) (*time.Location, error) {
+	return defaultTable.Location(code)
+}
+
+// Load reads an airports file from disk and adds its airports to the default table. It may only be called at
+// startup: the airports of the paths already computed and cached would no longer match the table
+func Load(path string) error {
+	return defaultTable.Load(path)
+}
+
+// Parse decodes an airports file, a JSON object whose "airports" holds the code, country, zone, latitude and
+// longitude of every airport
+func Parse(data []byte) ([]Airport, error) {
+	var file struct {
+		Airports []struct {
+			Code      string  `json:"code"`
+			Country   string  `json:"country"`
+			Zone      string  `json:"zone"`
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		} `json:"airports"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decoding airports: %w", err)
+	}
+
+	list := make([]Airport, len(file.Airports))
+	for i, airport := range file.Airports {
+		switch {
+		case !iataCode.MatchString(airport.Code):
+			return nil, fmt.Errorf("airport %d: the code must be an IATA code of 3 uppercase letters", i)
+		case !countryCode.MatchString(airport.Country):
+			return nil, fmt.Errorf("airport %s: the country must be an ISO 3166-1 alpha-2 code", airport.Code)
+		case math.Abs(airport.Latitude) > 90 || math.Abs(airport.Longitude) > 180:
+			return nil, fmt.Errorf("airport %s: the coordinates are out of range", airport.Code)
+		}
+		if _, err := time.LoadLocation(airport.Zone); err != nil || airport.Zone == "" {
+			return nil, fmt.Errorf("airport %s: unknown time zone %q", airport.Code, airport.Zone)
+		}
+		list[i] = Airport(airport)
+	}
+	return list, nil
+}
+
+// earthRadius is the mean radius of the Earth, in kilometers
+const earthRadius = 6371.0
+
+// Distance returns the great-circle distance between the airports, in kilometers
+func Distance(from, to Airport) float64 {
+	lat1, lat2 := radians(from.Latitude), radians(to.Latitude)
+	dLat, dLon := lat2-lat1, radians(to.Longitude-from.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
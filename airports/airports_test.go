@@ -0,0 +1,67 @@
+package airports_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/airports"
+)
+
+func TestAirports_Parse(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantLen   int
+		wantError error
+	}{
+		{name: "should_return_the_airports", data: `{"airports": [{"code": "QQA", "country": "FR", "zone": "Europe/Paris", "latitude": 48.7, "longitude": 2.4}]}`, wantLen: 1},
+		{name: "should_return_no_airport", data: `{}`},
+		{name: "should_return_error_when_the_code_is_invalid", data: `{"airports": [{"code": "qqa", "country": "FR", "zone": "Europe/Paris"}]}`, wantError: errors.New("airport 0: the code must be an IATA code of 3 uppercase letters")},
+		{name: "should_return_error_when_the_country_is_invalid", data: `{"airports": [{"code": "QQA", "country": "France", "zone": "Europe/Paris"}]}`, wantError: errors.New("airport QQA: the country must be an ISO 3166-1 alpha-2 code")},
+		{name: "should_return_error_when_the_zone_is_unknown", data: `{"airports": [{"code": "QQA", "country": "FR", "zone": "Europe/Atlantis"}]}`, wantError: errors.New(`airport QQA: unknown time zone "Europe/Atlantis"`)},
+		{name: "should_return_error_when_the_zone_is_missing", data: `{"airports": [{"code": "QQA", "country": "FR"}]}`, wantError: errors.New(`airport QQA: unknown time zone ""`)},
+		{name: "should_return_error_when_the_coordinates_are_out_of_range", data: `{"airports": [{"code": "QQA", "country": "FR", "zone": "Europe/Paris", "latitude": 91}]}`, wantError: errors.New("airport QQA: the coordinates are out of range")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := airports.Parse([]byte(tt.data))
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLen, len(list))
+		})
+	}
+}
+
+func TestAirports_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "airports.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"airports": [{"code": "QQB", "country": "JP", "zone": "Asia/Tokyo", "latitude": 35.5, "longitude": 139.8}]}`), 0o600))
+
+	// the airports are loaded in a table of their own, the default one is shared by the other tests
+	table := airports.NewTable()
+	_, ok := table.Lookup("QQB")
+	assert.Assert(t, !ok)
+	require.NoError(t, table.Load(path))
+
+	airport, ok := table.Lookup("QQB")
+	require.True(t, ok)
+	assert.DeepEqual(t, airports.Airport{Code: "QQB", Country: "JP", Zone: "Asia/Tokyo", Latitude: 35.5, Longitude: 139.8}, airport)
+	location, err := table.Location("QQB")
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", location.String())
+	_, ok = table.Lookup("SFO")
+	assert.Assert(t, ok, "the built-in airports should be kept")
+	_, ok = airports.Lookup("QQB")
+	assert.Assert(t, !ok, "the default table should be left untouched")
+
+	assert.ErrorContains(t, table.Load(filepath.Join(t.TempDir(), "missing.json")), "reading airports")
+}
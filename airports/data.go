@@ -0,0 +1,117 @@
+package airports
+
+// known holds the airports with reference data, by IATA code
+var known = map[string]Airport{
+	// North America
+	"ANC": {Country: "US", Zone: "America/Anchorage", Latitude: 61.17, Longitude: -150.00},
+	"ATL": {Country: "US", Zone: "America/New_York", Latitude: 33.64, Longitude: -84.43},
+	"BOS": {Country: "US", Zone: "America/New_York", Latitude: 42.36, Longitude: -71.01},
+	"BWI": {Country: "US", Zone: "America/New_York", Latitude: 39.18, Longitude: -76.67},
+	"CLT": {Country: "US", Zone: "America/New_York", Latitude: 35.21, Longitude: -80.94},
+	"DCA": {Country: "US", Zone: "America/New_York", Latitude: 38.85, Longitude: -77.04},
+	"DEN": {Country: "US", Zone: "America/Denver", Latitude: 39.86, Longitude: -104.67},
+	"DFW": {Country: "US", Zone: "America/Chicago", Latitude: 32.90, Longitude: -97.04},
+	"DTW": {Country: "US", Zone: "America/Detroit", Latitude: 42.21, Longitude: -83.35},
+	"EWR": {Country: "US", Zone: "America/New_York", Latitude: 40.69, Longitude: -74.17},
+	"FLL": {Country: "US", Zone: "America/New_York", Latitude: 26.07, Longitude: -80.15},
+	"GSO": {Country: "US", Zone: "America/New_York", Latitude: 36.10, Longitude: -79.94},
+	"HNL": {Country: "US", Zone: "Pacific/Honolulu", Latitude: 21.32, Longitude: -157.92},
+	"IAD": {Country: "US", Zone: "America/New_York", Latitude: 38.94, Longitude: -77.46},
+	"IAH": {Country: "US", Zone: "America/Chicago", Latitude: 29.98, Longitude: -95.34},
+	"IND": {Country: "US", Zone: "America/Indiana/Indianapolis", Latitude: 39.72, Longitude: -86.29},
+	"JFK": {Country: "US", Zone: "America/New_York", Latitude: 40.64, Longitude: -73.78},
+	"LAS": {Country: "US", Zone: "America/Los_Angeles", Latitude: 36.08, Longitude: -115.15},
+	"LAX": {Country: "US", Zone: "America/Los_Angeles", Latitude: 33.94, Longitude: -118.41},
+	"LGA": {Country: "US", Zone: "America/New_York", Latitude: 40.78, Longitude: -73.87},
+	"MCO": {Country: "US", Zone: "America/New_York", Latitude: 28.43, Longitude: -81.31},
+	"MDW": {Country: "US", Zone: "America/Chicago", Latitude: 41.79, Longitude: -87.75},
+	"MIA": {Country: "US", Zone: "America/New_York", Latitude: 25.79, Longitude: -80.29},
+	"MSP": {Country: "US", Zone: "America/Chicago", Latitude: 44.88, Longitude: -93.22},
+	"ORD": {Country: "US", Zone: "America/Chicago", Latitude: 41.98, Longitude: -87.90},
+	"PDX": {Country: "US", Zone: "America/Los_Angeles", Latitude: 45.59, Longitude: -122.60},
+	"PHL": {Country: "US", Zone: "America/New_York", Latitude: 39.87, Longitude: -75.24},
+	"PHX": {Country: "US", Zone: "America/Phoenix", Latitude: 33.43, Longitude: -112.01},
+	"SAN": {Country: "US", Zone: "America/Los_Angeles", Latitude: 32.73, Longitude: -117.19},
+	"SEA": {Country: "US", Zone: "America/Los_Angeles", Latitude: 47.45, Longitude: -122.31},
+	"SFO": {Country: "US", Zone: "America/Los_Angeles", Latitude: 37.62, Longitude: -122.38},
+	"SLC": {Country: "US", Zone: "America/Denver", Latitude: 40.79, Longitude: -111.98},
+	"TPA": {Country: "US", Zone: "America/New_York", Latitude: 27.98, Longitude: -82.53},
+	"YUL": {Country: "CA", Zone: "America/Toronto", Latitude: 45.47, Longitude: -73.74},
+	"YVR": {Country: "CA", Zone: "America/Vancouver", Latitude: 49.19, Longitude: -123.18},
+	"YYC": {Country: "CA", Zone: "America/Edmonton", Latitude: 51.13, Longitude: -114.01},
+	"YYZ": {Country: "CA", Zone: "America/Toronto", Latitude: 43.68, Longitude: -79.63},
+	"CUN": {Country: "MX", Zone: "America/Cancun", Latitude: 21.04, Longitude: -86.87},
+	"MEX": {Country: "MX", Zone: "America/Mexico_City", Latitude: 19.44, Longitude: -99.07},
+	"PTY": {Country: "PA", Zone: "America/Panama", Latitude: 9.07, Longitude: -79.38},
+
+	// South America
+	"BOG": {Country: "CO", Zone: "America/Bogota", Latitude: 4.70, Longitude: -74.15},
+	"EZE": {Country: "AR", Zone: "America/Argentina/Buenos_Aires", Latitude: -34.82, Longitude: -58.54},
+	"GIG": {Country: "BR", Zone: "America/Sao_Paulo", Latitude: -22.81, Longitude: -43.25},
+	"GRU": {Country: "BR", Zone: "America/Sao_Paulo", Latitude: -23.43, Longitude: -46.47},
+	"LIM": {Country: "PE", Zone: "America/Lima", Latitude: -12.02, Longitude: -77.11},
+	"SCL": {Country: "CL", Zone: "America/Santiago", Latitude: -33.39, Longitude: -70.79},
+
+	// Europe
+	"AMS": {Country: "NL", Zone: "Europe/Amsterdam", Latitude: 52.31, Longitude: 4.76},
+	"ARN": {Country: "SE", Zone: "Europe/Stockholm", Latitude: 59.65, Longitude: 17.92},
+	"ATH": {Country: "GR", Zone: "Europe/Athens", Latitude: 37.94, Longitude: 23.94},
+	"BCN": {Country: "ES", Zone: "Europe/Madrid", Latitude: 41.30, Longitude: 2.08},
+	"BRU": {Country: "BE", Zone: "Europe/Brussels", Latitude: 50.90, Longitude: 4.48},
+	"CDG": {Country: "FR", Zone: "Europe/Paris", Latitude: 49.01, Longitude: 2.55},
+	"CPH": {Country: "DK", Zone: "Europe/Copenhagen", Latitude: 55.62, Longitude: 12.66},
+	"DUB": {Country: "IE", Zone: "Europe/Dublin", Latitude: 53.42, Longitude: -6.27},
+	"FCO": {Country: "IT", Zone: "Europe/Rome", Latitude: 41.80, Longitude: 12.25},
+	"FRA": {Country: "DE", Zone: "Europe/Berlin", Latitude: 50.03, Longitude: 8.56},
+	"HEL": {Country: "FI", Zone: "Europe/Helsinki", Latitude: 60.32, Longitude: 24.96},
+	"IST": {Country: "TR", Zone: "Europe/Istanbul", Latitude: 41.26, Longitude: 28.74},
+	"KEF": {Country: "IS", Zone: "Atlantic/Reykjavik", Latitude: 63.99, Longitude: -22.62},
+	"LGW": {Country: "GB", Zone: "Europe/London", Latitude: 51.15, Longitude: -0.19},
+	"LHR": {Country: "GB", Zone: "Europe/London", Latitude: 51.47, Longitude: -0.45},
+	"LIS": {Country: "PT", Zone: "Europe/Lisbon", Latitude: 38.77, Longitude: -9.13},
+	"MAD": {Country: "ES", Zone: "Europe/Madrid", Latitude: 40.47, Longitude: -3.56},
+	"MUC": {Country: "DE", Zone: "Europe/Berlin", Latitude: 48.35, Longitude: 11.79},
+	"MXP": {Country: "IT", Zone: "Europe/Rome", Latitude: 45.63, Longitude: 8.72},
+	"ORY": {Country: "FR", Zone: "Europe/Paris", Latitude: 48.72, Longitude: 2.38},
+	"OSL": {Country: "NO", Zone: "Europe/Oslo", Latitude: 60.19, Longitude: 11.10},
+	"VIE": {Country: "AT", Zone: "Europe/Vienna", Latitude: 48.11, Longitude: 16.57},
+	"WAW": {Country: "PL", Zone: "Europe/Warsaw", Latitude: 52.17, Longitude: 20.97},
+	"ZRH": {Country: "CH", Zone: "Europe/Zurich", Latitude: 47.46, Longitude: 8.55},
+
+	// Middle East and Africa
+	"ADD": {Country: "ET", Zone: "Africa/Addis_Ababa", Latitude: 8.98, Longitude: 38.80},
+	"AUH": {Country: "AE", Zone: "Asia/Dubai", Latitude: 24.43, Longitude: 54.65},
+	"CAI": {Country: "EG", Zone: "Africa/Cairo", Latitude: 30.12, Longitude: 31.41},
+	"CPT": {Country: "ZA", Zone: "Africa/Johannesburg", Latitude: -33.97, Longitude: 18.60},
+	"DOH": {Country: "QA", Zone: "Asia/Qatar", Latitude: 25.27, Longitude: 51.61},
+	"DXB": {Country: "AE", Zone: "Asia/Dubai", Latitude: 25.25, Longitude: 55.36},
+	"JNB": {Country: "ZA", Zone: "Africa/Johannesburg", Latitude: -26.14, Longitude: 28.25},
+	"LOS": {Country: "NG", Zone: "Africa/Lagos", Latitude: 6.58, Longitude: 3.32},
+	"NBO": {Country: "KE", Zone: "Africa/Nairobi", Latitude: -1.32, Longitude: 36.93},
+	"TLV": {Country: "IL", Zone: "Asia/Jerusalem", Latitude: 32.01, Longitude: 34.89},
+
+	// Asia
+	"BKK": {Country: "TH", Zone: "Asia/Bangkok", Latitude: 13.69, Longitude: 100.75},
+	"BOM": {Country: "IN", Zone: "Asia/Kolkata", Latitude: 19.09, Longitude: 72.87},
+	"CGK": {Country: "ID", Zone: "Asia/Jakarta", Latitude: -6.13, Longitude: 106.66},
+	"DEL": {Country: "IN", Zone: "Asia/Kolkata", Latitude: 28.57, Longitude: 77.10},
+	"HKG": {Country: "HK", Zone: "Asia/Hong_Kong", Latitude: 22.31, Longitude: 113.91},
+	"HND": {Country: "JP", Zone: "Asia/Tokyo", Latitude: 35.55, Longitude: 139.78},
+	"ICN": {Country: "KR", Zone: "Asia/Seoul", Latitude: 37.46, Longitude: 126.44},
+	"KUL": {Country: "MY", Zone: "Asia/Kuala_Lumpur", Latitude: 2.75, Longitude: 101.71},
+	"MNL": {Country: "PH", Zone: "Asia/Manila", Latitude: 14.51, Longitude: 121.02},
+	"NRT": {Country: "JP", Zone: "Asia/Tokyo", Latitude: 35.77, Longitude: 140.39},
+	"PEK": {Country: "CN", Zone: "Asia/Shanghai", Latitude: 40.08, Longitude: 116.58},
+	"PVG": {Country: "CN", Zone: "Asia/Shanghai", Latitude: 31.14, Longitude: 121.81},
+	"SIN": {Country: "SG", Zone: "Asia/Singapore", Latitude: 1.36, Longitude: 103.99},
+	"TPE": {Country: "TW", Zone: "Asia/Taipei", Latitude: 25.08, Longitude: 121.23},
+
+	// Oceania
+	"AKL": {Country: "NZ", Zone: "Pacific/Auckland", Latitude: -37.01, Longitude: 174.79},
+	"BNE": {Country: "AU", Zone: "Australia/Brisbane", Latitude: -27.38, Longitude: 153.12},
+	"MEL": {Country: "AU", Zone: "Australia/Melbourne", Latitude: -37.67, Longitude: 144.84},
+	"NAN": {Country: "FJ", Zone: "Pacific/Fiji", Latitude: -17.76, Longitude: 177.44},
+	"PER": {Country: "AU", Zone: "Australia/Perth", Latitude: -31.94, Longitude: 115.97},
+	"PPT": {Country: "PF", Zone: "Pacific/Tahiti", Latitude: -17.55, Longitude: -149.61},
+	"SYD": {Country: "AU", Zone: "Australia/Sydney", Latitude: -33.95, Longitude: 151.18},
+}
@@ -0,0 +1,232 @@
+package anomaly
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/volume/service/user-flight-tracking/airports"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// Defaults applied when the configuration omits a value, in kilometers per hour
+const (
+	// DefaultMaxGroundSpeed is above the ground speed of the subsonic airliners, even with strong tailwinds
+	DefaultMaxGroundSpeed = 1200
+	// DefaultMaxSurfaceSpeed is above the speed of the high-speed trains, to move between airports by land
+	DefaultMaxSurfaceSpeed = 300
+)
+
+// Kinds of the anomalies
+const (
+	// KindSupersonic is a leg covering its distance faster than the maximum ground speed
+	KindSupersonic = "supersonic"
+	// KindOverlap is a leg departing before the previous one arrives
+	KindOverlap = "overlap"
+	// KindTeleportation is a leg departing from another airport than the previous arrival, too far to get there in time
+	KindTeleportation = "teleportation"
+	// KindDuplicate is the same flight claimed more than once
+	KindDuplicate = "duplicate"
+)
+
+// weights are the points added to the risk score by every anomaly of a kind
+var weights = map[string]int{
+	KindSupersonic:    40,
+	KindOverlap:       35,
+	KindTeleportation: 30,
+	KindDuplicate:     20,
+}
+
+// MaxScore is the risk score of the itineraries with the most anomalies
+const MaxScore = 100
+
+// Reason is an anomaly found in an itinerary
+type Reason struct {
+	Kind string
+	// Legs holds the positions of the legs involved, in the order of the request
+	Legs    []int
+	Message string
+}
+
+// Report is the result of the analysis of an itinerary
+type Report struct {
+	// Score is the risk of the itinerary, from 0 when no anomaly is found to MaxScore
+	Score   int
+	Reasons []Reason
+}
+
+// Detector analyzes the itineraries for legs that couldn't physically happen
+type Detector struct {
+	// MaxGroundSpeed and MaxSurfaceSpeed are the fastest plausible speeds of a flight and of a transfer
+	// between airports, in kilometers per hour
+	MaxGroundSpeed  float64
+	MaxSurfaceSpeed float64
+}
+
+// NewDetector returns a detector of the impossible itineraries
+func NewDetector(maxGroundSpeed, maxSurfaceSpeed float64) (*Detector, error) {
+	switch {
+	case maxGroundSpeed <= 0:
+		return nil, errors.New("maxGroundSpeed")
+	case maxSurfaceSpeed <= 0:
+		return nil, errors.New("maxSurfaceSpeed")
+	}
+
+	return &Detector{
+		MaxGroundSpeed:  maxGroundSpeed,
+		MaxSurfaceSpeed: maxSurfaceSpeed,
+	}, nil
+}
+
+// timedLeg is a leg of the itinerary with its times
+type timedLeg struct {
+	index     int
+	leg       models.Leg
+	departure time.Time
+	arrival   time.Time
+}
+
+// Analyze returns the anomalies of the legs. The speeds and the chronology are only checked on the timed legs
+// between known airports
+func (d *Detector) Analyze(legs []models.Leg) Report {
+	duplicates, repeated := d.duplicates(legs)
+	reasons := duplicates
+
+	var timed []timedLeg
+	for i, leg := range legs {
+		departure, arrival, ok := leg.Times()
+		if !ok {
+			continue
+		}
+
+		if reason, ok := d.supersonic(i, leg, arrival.Sub(departure)); ok {
+			reasons = append(reasons, reason)
+		}
+		// a repeated leg would overlap its first occurrence, it is already reported as a duplicate
+		if !repeated[i] {
+			timed = append(timed, timedLeg{index: i, leg: leg, departure: departure, arrival: arrival})
+		}
+	}
+
+	sort.SliceStable(timed, func(i, j int) bool { return timed[i].departure.Before(timed[j].departure) })
+	for i := 1; i < len(timed); i++ {
+		if reason, ok := d.chronology(timed[i-1], timed[i]); ok {
+			reasons = append(reasons, reason)
+		}
+	}
+
+	report := Report{Reasons: reasons}
+	for _, reason := range reasons {
+		report.Score += weights[reason.Kind]
+	}
+	if report.Score > MaxScore {
+		report.Score = MaxScore
+	}
+	return report
+}
+
+// duplicates returns the flights claimed more than once, and the positions of their repetitions
+func (d *Detector) duplicates(legs []models.Leg) ([]Reason, map[int]bool) {
+	var (
+		keys      []string
+		positions = make(map[string][]int)
+	)
+	for i, leg := range legs {
+		key := strings.Join([]string{leg.Origin, leg.Destination, leg.Carrier, leg.FlightNumber, leg.Departure}, "|")
+		if _, ok := positions[key]; !ok {
+			keys = append(keys, key)
+		}
+		positions[key] = append(positions[key], i)
+	}
+
+	var reasons []Reason
+	repeated := make(map[int]bool)
+	for _, key := range keys {
+		indexes := positions[key]
+		if len(indexes) < 2 {
+			continue
+		}
+
+		leg := legs[indexes[0]]
+		reasons = append(reasons, Reason{
+			Kind:    KindDuplicate,
+			Legs:    indexes,
+			Message: fmt.Sprintf("the flight %s is claimed %d times", describe(leg), len(indexes)),
+		})
+		for _, i := range indexes[1:] {
+			repeated[i] = true
+		}
+	}
+	return reasons, repeated
+}
+
+// supersonic reports whether the leg is faster than the maximum ground speed
+func (d *Detector) supersonic(index int, leg models.Leg, block time.Duration) (Reason, bool) {
+	distance, ok := distance(leg.Origin, leg.Destination)
+	if !ok {
+		return Reason{}, false
+	}
+
+	speed := distance / block.Hours()
+	if speed <= d.MaxGroundSpeed {
+		return Reason{}, false
+	}
+
+	return Reason{
+		Kind:    KindSupersonic,
+		Legs:    []int{index},
+		Message: fmt.Sprintf("the flight %s covers %.0f km in %s, at %.0f km/h", describe(leg), distance, block, speed),
+	}, true
+}
+
+// chronology checks the leg departing after the previous one: it can't depart before the previous arrival,
+// nor from an airport too far from it to be reached in time by land
+func (d *Detector) chronology(previous, next timedLeg) (Reason, bool) {
+	legs := []int{previous.index, next.index}
+	if next.departure.Before(previous.arrival) {
+		return Reason{
+			Kind:    KindOverlap,
+			Legs:    legs,
+			Message: fmt.Sprintf("the flight %s departs before the arrival of %s", describe(next.leg), describe(previous.leg)),
+		}, true
+	}
+
+	if next.leg.Origin == previous.leg.Destination {
+		return Reason{}, false
+	}
+
+	transfer := next.departure.Sub(previous.arrival)
+	if distance, ok := distance(previous.leg.Destination, next.leg.Origin); ok && transfer > 0 && distance/transfer.Hours() <= d.MaxSurfaceSpeed {
+		return Reason{}, false
+	}
+
+	return Reason{
+		Kind:    KindTeleportation,
+		Legs:    legs,
+		Message: fmt.Sprintf("the flight %s departs from %s %s after arriving at %s", describe(next.leg), next.leg.Origin, transfer, previous.leg.Destination),
+	}, true
+}
+
+// distance returns the distance between the airports, ok is false when any of them is unknown
+func distance(origin, destination string) (float64, bool) {
+	from, ok := airports.Lookup(origin)
+	if !ok {
+		return 0, false
+	}
+	to, ok := airports.Lookup(destination)
+	if !ok {
+		return 0, false
+	}
+	return airports.Distance(from, to), true
+}
+
+// describe returns the flight number and the airports of the leg, e.g. "UA88 SFO-ATL"
+func describe(leg models.Leg) string {
+	route := leg.Origin + "-" + leg.Destination
+	if leg.FlightNumber == "" {
+		return route
+	}
+	return leg.Carrier + leg.FlightNumber + " " + route
+}
@@ -0,0 +1,126 @@
+package anomaly_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/anomaly"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+func TestAnomaly_NewDetector(t *testing.T) {
+	tests := []struct {
+		name            string
+		maxGroundSpeed  float64
+		maxSurfaceSpeed float64
+		wantError       error
+	}{
+		{name: "should_return_success", maxGroundSpeed: anomaly.DefaultMaxGroundSpeed, maxSurfaceSpeed: anomaly.DefaultMaxSurfaceSpeed},
+		{name: "should_return_error_without_ground_speed", maxSurfaceSpeed: anomaly.DefaultMaxSurfaceSpeed, wantError: errors.New("maxGroundSpeed")},
+		{name: "should_return_error_without_surface_speed", maxGroundSpeed: anomaly.DefaultMaxGroundSpeed, wantError: errors.New("maxSurfaceSpeed")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := anomaly.NewDetector(tt.maxGroundSpeed, tt.maxSurfaceSpeed)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAnomaly_Analyze(t *testing.T) {
+	detector, err := anomaly.NewDetector(anomaly.DefaultMaxGroundSpeed, anomaly.DefaultMaxSurfaceSpeed)
+	require.NoError(t, err)
+
+	sfoAtl := models.Leg{Origin: "SFO", Destination: "ATL", Carrier: "DL", FlightNumber: "1204", Departure: "2023-06-13T07:00", Arrival: "2023-06-13T14:30"}
+
+	tests := []struct {
+		name      string
+		legs      []models.Leg
+		wantScore int
+		wantKinds []string
+		wantLegs  [][]int
+	}{
+		{
+			name: "should_not_flag_a_plausible_itinerary",
+			legs: []models.Leg{
+				{Origin: "ATL", Destination: "EWR", Departure: "2023-06-13T16:00", Arrival: "2023-06-13T18:15"},
+				sfoAtl,
+				{Origin: "JFK", Destination: "LHR", Departure: "2023-06-13T21:30", Arrival: "2023-06-14T09:40"},
+			},
+		},
+		{
+			name: "should_flag_supersonic_flights",
+			legs: []models.Leg{
+				{Origin: "SFO", Destination: "NRT", Departure: "2023-06-13T11:00", Arrival: "2023-06-14T06:00"},
+			},
+			wantScore: 40,
+			wantKinds: []string{anomaly.KindSupersonic},
+			wantLegs:  [][]int{{0}},
+		},
+		{
+			name: "should_flag_overlapping_legs",
+			legs: []models.Leg{
+				sfoAtl,
+				{Origin: "ATL", Destination: "EWR", Departure: "2023-06-13T14:00", Arrival: "2023-06-13T16:15"},
+			},
+			wantScore: 35,
+			wantKinds: []string{anomaly.KindOverlap},
+			wantLegs:  [][]int{{0, 1}},
+		},
+		{
+			name: "should_flag_departures_from_an_unreachable_airport",
+			legs: []models.Leg{
+				{Origin: "JFK", Destination: "LHR", Departure: "2023-06-13T16:00", Arrival: "2023-06-14T04:10"},
+				sfoAtl,
+			},
+			wantScore: 30,
+			wantKinds: []string{anomaly.KindTeleportation},
+			wantLegs:  [][]int{{1, 0}},
+		},
+		{
+			name: "should_flag_duplicated_legs_once",
+			legs: []models.Leg{
+				sfoAtl,
+				{Origin: "ATL", Destination: "EWR"},
+				sfoAtl,
+			},
+			wantScore: 20,
+			wantKinds: []string{anomaly.KindDuplicate},
+			wantLegs:  [][]int{{0, 2}},
+		},
+		{
+			name: "should_cap_the_score",
+			legs: []models.Leg{
+				{Origin: "SFO", Destination: "NRT", Departure: "2023-06-13T11:00", Arrival: "2023-06-14T06:00"},
+				{Origin: "SFO", Destination: "NRT", Departure: "2023-06-13T11:00", Arrival: "2023-06-14T06:00"},
+				{Origin: "LHR", Destination: "CDG", Departure: "2023-06-14T20:00", Arrival: "2023-06-14T22:10"},
+			},
+			wantScore: anomaly.MaxScore,
+			wantKinds: []string{anomaly.KindDuplicate, anomaly.KindSupersonic, anomaly.KindSupersonic, anomaly.KindTeleportation},
+			wantLegs:  [][]int{{0, 1}, {0}, {1}, {0, 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := detector.Analyze(tt.legs)
+
+			assert.Equal(t, tt.wantScore, report.Score)
+			require.Equal(t, len(tt.wantKinds), len(report.Reasons), "%+v", report.Reasons)
+			for i, reason := range report.Reasons {
+				assert.Equal(t, tt.wantKinds[i], reason.Kind)
+				assert.DeepEqual(t, tt.wantLegs[i], reason.Legs)
+				assert.Assert(t, reason.Message != "")
+			}
+		})
+	}
+}
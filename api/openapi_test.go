@@ -0,0 +1,225 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/config"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// documentedModels maps the component schemas of the OpenAPI document to the models they describe
+var documentedModels = map[string]interface{}{
+	"PathRequest":  models.PathRequest{},
+	"PathResponse": models.PathResponse{},
+
+	"AnomalyReport":    models.AnomalyReport{},
+	"RouteSuggestions": models.RouteSuggestions{},
+	"ReconcileRequest": models.ReconcileRequest{},
+	"Reconciliation":   models.Reconciliation{},
+	"DiffRequest":      models.DiffRequest{},
+	"ItineraryDiff":    models.ItineraryDiff{},
+
+	"ValidationErrorResponse": models.ValidationErrorResponse{},
+	"JobResponse":             models.JobResponse{},
+
+	"WebhookSubscriptionRequest":  models.WebhookSubscriptionRequest{},
+	"WebhookSubscriptionResponse": models.WebhookSubscriptionResponse{},
+	"WebhookEvent":                models.WebhookEvent{},
+	"DeadLetterResponse":          models.DeadLetterResponse{},
+
+	"AuditEntryResponse": models.AuditEntryResponse{},
+	"ErasureResponse":    models.ErasureResponse{},
+}
+
+type openAPIDocument struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPISchema struct {
+	Ref                  string                   `json:"$ref"`
+	Type                 string                   `json:"type"`
+	Required             []string                 `json:"required"`
+	Properties           map[string]openAPISchema `json:"properties"`
+	Items                *openAPISchema           `json:"items"`
+	AdditionalProperties *openAPISchema           `json:"additionalProperties"`
+}
+
+func loadOpenAPIDocument(t *testing.T) openAPIDocument {
+	t.Helper()
+
+	var doc openAPIDocument
+	require.NoError(t, json.Unmarshal(OpenAPISpec(), &doc), "the OpenAPI document should be valid JSON")
+	return doc
+}
+
+func TestOpenAPI_RoutesMatchSpec(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+
+	router, _, err := newRouter(config.Config{})
+	require.NoError(t, err)
+
+	registered := map[string]bool{}
+	err = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			registered[strings.ToLower(method)+" "+template] = true
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	documented := map[string]bool{}
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			documented[method+" "+path] = true
+		}
+	}
+
+	for operation := range registered {
+		assert.Assert(t, documented[operation], "route %q is not documented in openapi.json", operation)
+	}
+	for operation := range documented {
+		assert.Assert(t, registered[operation], "operation %q of openapi.json has no handler", operation)
+	}
+}
+
+func TestOpenAPI_SchemasMatchModels(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+
+	for name, model := range documentedModels {
+		t.Run(name, func(t *testing.T) {
+			schema, ok := doc.Components.Schemas[name]
+			require.True(t, ok, "schema %q is missing", name)
+			compareSchema(t, doc, name, schema, reflect.TypeOf(model))
+		})
+	}
+}
+
+func TestOpenAPI_ServeSpec(t *testing.T) {
+	handler, _, err := Routes(config.Config{})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, string(OpenAPISpec()), recorder.Body.String())
+}
+
+// compareSchema fails when the schema does not describe the JSON encoding of typ
+func compareSchema(t *testing.T, doc openAPIDocument, path string, schema openAPISchema, typ reflect.Type) {
+	t.Helper()
+
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		resolved, ok := doc.Components.Schemas[name]
+		require.True(t, ok, "%s: unknown reference %q", path, schema.Ref)
+		compareSchema(t, doc, path, resolved, typ)
+		return
+	}
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if typ.PkgPath() == "time" && typ.Name() == "Time" {
+			assert.Equal(t, "string", schema.Type, "%s: type", path)
+			return
+		}
+		assert.Equal(t, "object", schema.Type, "%s: type", path)
+
+		fields, required := jsonFields(typ)
+		assert.DeepEqual(t, sortedKeys(fields), sortedKeys(schema.Properties))
+		for name, field := range fields {
+			if property, ok := schema.Properties[name]; ok {
+				compareSchema(t, doc, path+"."+name, property, field.Type)
+			}
+		}
+		for _, name := range schema.Required {
+			assert.Assert(t, required[name], "%s: %q is required in the spec but optional in the model", path, name)
+		}
+	case reflect.Slice, reflect.Array:
+		assert.Equal(t, "array", schema.Type, "%s: type", path)
+		require.NotNil(t, schema.Items, "%s: missing items", path)
+		compareSchema(t, doc, path+"[]", *schema.Items, typ.Elem())
+	case reflect.Map:
+		assert.Equal(t, "object", schema.Type, "%s: type", path)
+		if schema.AdditionalProperties != nil {
+			compareSchema(t, doc, path+"{}", *schema.AdditionalProperties, typ.Elem())
+		}
+	case reflect.String:
+		assert.Equal(t, "string", schema.Type, "%s: type", path)
+	case reflect.Bool:
+		assert.Equal(t, "boolean", schema.Type, "%s: type", path)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		assert.Equal(t, "integer", schema.Type, "%s: type", path)
+	case reflect.Float32, reflect.Float64:
+		assert.Equal(t, "number", schema.Type, "%s: type", path)
+	case reflect.Interface:
+		// any value is accepted
+	default:
+		t.Errorf("%s: unsupported kind %s", path, typ.Kind())
+	}
+}
+
+// jsonFields returns the JSON encoded fields of a struct and whether they are always present
+func jsonFields(typ reflect.Type) (map[string]reflect.StructField, map[string]bool) {
+	fields := map[string]reflect.StructField{}
+	required := map[string]bool{}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fields[name] = field
+		required[name] = !strings.Contains(options, "omitempty")
+	}
+
+	return fields, required
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,40 +1,665 @@
 package api
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
 	"github.com/rs/cors"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/volume/service/user-flight-tracking/airports"
+	"github.com/volume/service/user-flight-tracking/anomaly"
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/cache"
+	"github.com/volume/service/user-flight-tracking/completion"
+	"github.com/volume/service/user-flight-tracking/config"
+	"github.com/volume/service/user-flight-tracking/connections"
 	"github.com/volume/service/user-flight-tracking/controllers"
+	"github.com/volume/service/user-flight-tracking/duplicates"
 	"github.com/volume/service/user-flight-tracking/gateways"
+	"github.com/volume/service/user-flight-tracking/idempotency"
+	"github.com/volume/service/user-flight-tracking/jobqueue"
 	"github.com/volume/service/user-flight-tracking/mediators"
+	"github.com/volume/service/user-flight-tracking/metrics"
+	"github.com/volume/service/user-flight-tracking/middlewares"
+	"github.com/volume/service/user-flight-tracking/network"
+	"github.com/volume/service/user-flight-tracking/privacy"
+	"github.com/volume/service/user-flight-tracking/ratelimit"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+	"github.com/volume/service/user-flight-tracking/tracing"
+	"github.com/volume/service/user-flight-tracking/webhooks"
 )
 
+// Defaults applied when the configuration omits a value
+const (
+	defaultServiceName  = "user-flight-tracking"
+	defaultJobWorkers   = 4
+	defaultJobQueueSize = 100
+	defaultJobRetention = time.Hour
+
+	defaultWebhookAttempts   = 5
+	defaultWebhookMinBackoff = time.Second
+	defaultWebhookMaxBackoff = time.Minute
+	defaultWebhookTimeout    = 10 * time.Second
+
+	defaultCacheCapacity = 10000
+	defaultCacheTTL      = 5 * time.Minute
+
+	defaultIdempotencyWindow = 24 * time.Hour
+
+	defaultAuditTable         = "audit_log"
+	defaultAuditMemoryEntries = 10000
+)
+
+// Shutdown releases the resources held by the routes, e.g. flushing pending traces
+type Shutdown func(ctx context.Context) error
+
 // Routes prepares the mux router to be served
-func Routes() http.Handler {
+func Routes(cfg config.Config) (http.Handler, Shutdown, error) {
+	router, shutdown, err := newRouter(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cors.AllowAll().Handler(router), shutdown, nil
+}
+
+// newRouter registers the routes and their middlewares
+func newRouter(cfg config.Config) (*mux.Router, Shutdown, error) {
+	var closers []func(ctx context.Context) error
+	shutdown := func(ctx context.Context) error {
+		var errs []error
+		for _, closer := range closers {
+			errs = append(errs, closer(ctx))
+		}
+		return errors.Join(errs...)
+	}
+
 	// initialize controllers
-	flightTrackerController := generateControllers()
+	jobQueue, err := generateJobQueue(cfg.Jobs)
+	if err != nil {
+		return nil, nil, err
+	}
+	closers = append(closers, jobQueue.Shutdown)
+	subscriptions := webhooks.NewMemorySubscriptionStore()
+	dispatcher, err := generateDispatcher(cfg.Webhooks, subscriptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	closers = append(closers, dispatcher.Shutdown)
+	if cfg.Airports.File != "" {
+		if err := airports.Load(cfg.Airports.File); err != nil {
+			return nil, nil, fmt.Errorf("airports: %w", err)
+		}
+	}
+	registry := metrics.NewRegistry()
+	pathCache, err := generateCache(cfg.Cache, registry)
+	if err != nil {
+		return nil, nil, err
+	}
+	auditor, auditReader, closeAudit, err := generateAudit(cfg.Audit)
+	if err != nil {
+		return nil, nil, err
+	}
+	closers = append(closers, closeAudit)
+	redaction, err := privacy.ParseRedaction(cfg.Privacy.PathLogging)
+	if err != nil {
+		return nil, nil, fmt.Errorf("privacy pathLogging: %w", err)
+	}
+	redactor, err := privacy.NewRedactor(redaction, cfg.Privacy.PathLoggingKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("privacy pathLoggingKey: %w", err)
+	}
+	// the ttl has been validated by generateCache
+	cacheTTL, _ := durationOrDefault(cfg.Cache.TTL, defaultCacheTTL)
+	cacheIndex, err := privacy.NewCacheIndex(pathCache, cacheTTL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cache index: %w", err)
+	}
+	connectionTimes, err := generateConnections(cfg.Connections)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connections: %w", err)
+	}
+	duplicateLegs, err := duplicates.ParsePolicy(cfg.Legs.Duplicates)
+	if err != nil {
+		return nil, nil, fmt.Errorf("legs duplicates: %w", err)
+	}
+	routesNetwork, completer, err := generateNetwork(cfg.Network)
+	if err != nil {
+		return nil, nil, fmt.Errorf("network: %w", err)
+	}
+	flightTrackerController, completionController, reconciliationController, diffController, jobsController, webhooksController := generateControllers(jobQueue, subscriptions, dispatcher, pathCache, cacheIndex, auditor, redactor, connectionTimes, duplicateLegs, completer)
+	auditController, _ := controllers.NewAudit(log.WithField("controller", "Audit"), auditReader)
+	anomaliesController, err := generateAnomalies(cfg.Anomalies, auditor)
+	if err != nil {
+		return nil, nil, fmt.Errorf("anomalies: %w", err)
+	}
+	routesController, _ := controllers.NewRoutes(log.WithField("controller", "Routes"), routesNetwork)
+	idempotencyMiddleware, idempotencyIndex, err := generateIdempotency(cfg.Idempotency)
+	if err != nil {
+		return nil, nil, err
+	}
+	usersController, _ := controllers.NewUsers(
+		log.WithField("controller", "Users"),
+		generateEraser(jobQueue, dispatcher, cacheIndex, idempotencyIndex, auditor),
+		auditor,
+	)
 
 	router := mux.NewRouter()
 
-	// routes
-	router.HandleFunc("/calculate", flightTrackerController.GetPath).Methods(http.MethodPost)
+	// middlewares applied to every route
+	requestLogging, err := middlewares.NewRequestLogging(log.WithField("middleware", "RequestLogging"))
+	if err != nil {
+		return nil, nil, err
+	}
+	router.Use(requestLogging.Handle)
+	if cfg.Tracing.Exporter != "" {
+		tracer, err := generateTracer(cfg.Tracing)
+		if err != nil {
+			return nil, nil, err
+		}
+		closers = append(closers, tracer.Shutdown)
+		tracingMiddleware, _ := middlewares.NewTracing(tracer)
+		router.Use(tracingMiddleware.Handle)
+	}
 
-	return cors.AllowAll().Handler(router)
+	// public routes
+	router.HandleFunc("/openapi.json", serveOpenAPI).Methods(http.MethodGet)
+	router.Handle("/metrics", registry).Methods(http.MethodGet)
+
+	// middlewares applied to the protected routes
+	protected := router.NewRoute().Subrouter()
+	if cfg.Auth.Enabled() {
+		authentication, err := generateAuthentication(cfg.Auth)
+		if err != nil {
+			return nil, nil, err
+		}
+		protected.Use(authentication.Handle)
+	}
+	tenancyMiddleware, err := generateTenancy(cfg, registry)
+	if err != nil {
+		return nil, nil, err
+	}
+	protected.Use(tenancyMiddleware.Handle)
+	// retries replayed by the idempotency middleware don't consume the rate limits and quotas again
+	protected.Use(idempotencyMiddleware.Handle)
+	if cfg.RateLimit.Enabled() {
+		rateLimit, err := generateRateLimit(cfg.RateLimit)
+		if err != nil {
+			return nil, nil, err
+		}
+		protected.Use(rateLimit.Handle)
+	}
+
+	// protected routes
+	protected.HandleFunc("/calculate", flightTrackerController.GetPath).Methods(http.MethodPost)
+	protected.HandleFunc("/analyze", anomaliesController.Analyze).Methods(http.MethodPost)
+	protected.HandleFunc("/complete", completionController.Complete).Methods(http.MethodPost)
+	protected.HandleFunc("/reconcile", reconciliationController.Reconcile).Methods(http.MethodPost)
+	protected.HandleFunc("/diff", diffController.Diff).Methods(http.MethodPost)
+	protected.HandleFunc("/routes", routesController.Suggest).Methods(http.MethodGet)
+	protected.HandleFunc("/jobs", jobsController.Create).Methods(http.MethodPost)
+	protected.HandleFunc("/jobs/{id}", jobsController.Get).Methods(http.MethodGet)
+	protected.HandleFunc("/jobs/{id}", jobsController.Delete).Methods(http.MethodDelete)
+	protected.HandleFunc("/jobs/{id}/result", jobsController.GetResult).Methods(http.MethodGet)
+	protected.HandleFunc("/webhooks", webhooksController.Create).Methods(http.MethodPost)
+	protected.HandleFunc("/webhooks", webhooksController.List).Methods(http.MethodGet)
+	protected.HandleFunc("/webhooks/dead-letters", webhooksController.ListDeadLetters).Methods(http.MethodGet)
+	protected.HandleFunc("/webhooks/{id}", webhooksController.Delete).Methods(http.MethodDelete)
+	protected.HandleFunc("/audit", auditController.List).Methods(http.MethodGet)
+	protected.HandleFunc("/users/{userID}", usersController.Erase).Methods(http.MethodDelete)
+
+	return router, shutdown, nil
 }
 
 // generateControllers constructs the needed controller with dependency injected
-func generateControllers() controllers.FlightTracker {
+func generateControllers(
+	jobQueue *jobqueue.Queue,
+	subscriptions webhooks.SubscriptionStore,
+	dispatcher *webhooks.Dispatcher,
+	pathCache cache.Backend,
+	cacheIndex privacy.Tracker,
+	auditor audit.Recorder,
+	redactor privacy.Redactor,
+	connectionTimes *connections.Table,
+	duplicateLegs duplicates.Policy,
+	completer *completion.Completer,
+) (controllers.FlightTracker, controllers.Completion, controllers.Reconciliation, controllers.ItineraryDiff, controllers.Jobs, controllers.Webhooks) {
 	// ------------------------ flightTracker ------------------------
-	flightTrackerGateway, _ := gateways.NewFlightTracker(log.WithField("gateway", "FlightTracker"))
-	flightTrackerMediator, _ := mediators.NewFlightTracker(log.WithField("mediator", "FlightTracker"), flightTrackerGateway)
+	flightTrackerGateway, _ := gateways.NewFlightTracker(log.WithField("gateway", "FlightTracker"), redactor, connectionTimes)
+	flightTrackerMediator, _ := mediators.NewFlightTracker(
+		log.WithField("mediator", "FlightTracker"),
+		flightTrackerGateway,
+		dispatcher,
+		pathCache,
+		cacheIndex,
+		duplicateLegs,
+	)
 	flightTrackerController, _ := controllers.NewFlightTracker(
 		log.WithField("controller", "FlightTracker"),
 		flightTrackerMediator,
+		auditor,
+	)
+
+	// ------------------------ completion ------------------------
+	completionController, _ := controllers.NewCompletion(
+		log.WithField("controller", "Completion"),
+		completer,
+		flightTrackerMediator,
+		auditor,
+	)
+
+	// ------------------------ reconciliation ------------------------
+	reconciliationController, _ := controllers.NewReconciliation(
+		log.WithField("controller", "Reconciliation"),
+		flightTrackerMediator,
+		auditor,
+	)
+
+	// ------------------------ diff ------------------------
+	diffController, _ := controllers.NewItineraryDiff(
+		log.WithField("controller", "ItineraryDiff"),
+		flightTrackerMediator,
+		auditor,
+	)
+
+	// ------------------------ jobs ------------------------
+	jobsController, _ := controllers.NewJobs(
+		log.WithField("controller", "Jobs"),
+		flightTrackerMediator,
+		jobQueue,
+		auditor,
+	)
+
+	// ------------------------ webhooks ------------------------
+	webhooksController, _ := controllers.NewWebhooks(
+		log.WithField("controller", "Webhooks"),
+		subscriptions,
+		dispatcher,
+	)
+
+	return flightTrackerController, completionController, reconciliationController, diffController, jobsController, webhooksController
+}
+
+// generateEraser constructs the erasure of the travelers from every store holding their data
+func generateEraser(
+	jobQueue *jobqueue.Queue,
+	dispatcher *webhooks.Dispatcher,
+	cacheIndex *privacy.CacheIndex,
+	idempotencyIndex *privacy.CacheIndex,
+	auditor *audit.Auditor,
+) *privacy.Eraser {
+	eraser, _ := privacy.NewEraser(log.WithField("component", "Eraser"), map[string]privacy.Target{
+		"jobs": privacy.TargetFunc(func(ctx context.Context, userID string) (int, error) {
+			return jobQueue.DeleteSubject(privacy.Subject(ctx, userID)), nil
+		}),
+		"cache":       cacheIndex,
+		"idempotency": idempotencyIndex,
+		"deadLetters": dispatcher,
+		"audit":       auditor,
+	})
+	return eraser
+}
+
+// generateJobQueue constructs the worker pool of the asynchronous calculations
+func generateJobQueue(cfg config.Jobs) (*jobqueue.Queue, error) {
+	workers, size := cfg.Workers, cfg.QueueSize
+	if workers == 0 {
+		workers = defaultJobWorkers
+	}
+	if size == 0 {
+		size = defaultJobQueueSize
+	}
+	retention, err := durationOrDefault(cfg.Retention, defaultJobRetention)
+	if err != nil {
+		return nil, fmt.Errorf("jobs retention: %w", err)
+	}
+
+	queue, err := jobqueue.NewQueue(log.WithField("component", "JobQueue"), workers, size, retention)
+	if err != nil {
+		return nil, fmt.Errorf("jobs: %w", err)
+	}
+	return queue, nil
+}
+
+// generateCache constructs the in-memory path cache, counting its hits and misses by tenant in registry
+func generateCache(cfg config.Cache, registry *metrics.Registry) (cache.Backend, error) {
+	capacity := cfg.Capacity
+	if capacity == 0 {
+		capacity = defaultCacheCapacity
+	}
+	ttl, err := durationOrDefault(cfg.TTL, defaultCacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("cache ttl: %w", err)
+	}
+
+	lru, err := cache.NewLRU(capacity, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+
+	requests := registry.Counter("flight_tracking_path_cache_requests_total", "Lookups of the path cache by tenant and result.", "tenant", "result")
+	return cache.WithMetrics(lru, requests), nil
+}
+
+// generateAudit constructs the audit log writing to the configured sinks, and returns the sink queried by
+// the audit endpoint: the database, else the file, else the in-memory sink used when none is configured
+func generateAudit(cfg config.Audit) (*audit.Auditor, audit.Reader, func(ctx context.Context) error, error) {
+	var (
+		sinks     []audit.Sink
+		reader    audit.Reader
+		resources []io.Closer
+	)
+	closeAll := func(context.Context) error {
+		var errs []error
+		for _, resource := range resources {
+			errs = append(errs, resource.Close())
+		}
+		return errors.Join(errs...)
+	}
+
+	if cfg.File != "" {
+		file, err := audit.NewFileSink(cfg.File)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("audit file: %w", err)
+		}
+		resources = append(resources, file)
+		sinks, reader = append(sinks, file), file
+	}
+	if cfg.Database.Driver != "" {
+		db, err := sql.Open(cfg.Database.Driver, cfg.Database.DSN)
+		if err != nil {
+			_ = closeAll(context.Background())
+			return nil, nil, nil, fmt.Errorf("audit database: %w", err)
+		}
+		resources = append(resources, db)
+
+		table := cfg.Database.Table
+		if table == "" {
+			table = defaultAuditTable
+		}
+		numbered := cfg.Database.Driver == "postgres"
+		database, err := audit.NewSQLSink(db, table, numbered)
+		if err != nil {
+			_ = closeAll(context.Background())
+			return nil, nil, nil, fmt.Errorf("audit database: %w", err)
+		}
+		sinks, reader = append(sinks, database), database
+	}
+	if len(sinks) == 0 {
+		memory, _ := audit.NewMemorySink(defaultAuditMemoryEntries)
+		sinks, reader = append(sinks, memory), memory
+	}
+
+	auditor, err := audit.NewAuditor(log.WithField("component", "Audit"), sinks...)
+	if err != nil {
+		_ = closeAll(context.Background())
+		return nil, nil, nil, err
+	}
+	return auditor, reader, closeAll, nil
+}
+
+// generateAnomalies constructs the controller of the analysis of the itineraries
+func generateAnomalies(cfg config.Anomalies, auditor audit.Recorder) (controllers.Anomalies, error) {
+	maxGroundSpeed, maxSurfaceSpeed := cfg.MaxGroundSpeedKmh, cfg.MaxSurfaceSpeedKmh
+	if maxGroundSpeed == 0 {
+		maxGroundSpeed = anomaly.DefaultMaxGroundSpeed
+	}
+	if maxSurfaceSpeed == 0 {
+		maxSurfaceSpeed = anomaly.DefaultMaxSurfaceSpeed
+	}
+
+	detector, err := anomaly.NewDetector(maxGroundSpeed, maxSurfaceSpeed)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.NewAnomalies(log.WithField("controller", "Anomalies"), detector, auditor)
+}
+
+// generateNetwork loads the network of the routes file, empty when none is configured, and the completer of the
+// itineraries over it
+func generateNetwork(cfg config.Network) (*network.Network, *completion.Completer, error) {
+	routes := network.New(nil)
+	if cfg.RoutesFile != "" {
+		var err error
+		if routes, err = network.LoadRoutes(cfg.RoutesFile); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	maxInferredLegs := cfg.MaxInferredLegs
+	if maxInferredLegs == 0 {
+		maxInferredLegs = completion.DefaultMaxInferredLegs
+	}
+	completer, err := completion.NewCompleter(routes, maxInferredLegs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return routes, completer, nil
+}
+
+// generateConnections constructs the table of the minimum connection times
+func generateConnections(cfg config.Connections) (*connections.Table, error) {
+	domestic, err := durationOrDefault(cfg.Domestic, connections.DefaultDomestic)
+	if err != nil {
+		return nil, fmt.Errorf("domestic: %w", err)
+	}
+	international, err := durationOrDefault(cfg.International, connections.DefaultInternational)
+	if err != nil {
+		return nil, fmt.Errorf("international: %w", err)
+	}
+	tightMargin, err := durationOrDefault(cfg.TightMargin, connections.DefaultTightMargin)
+	if err != nil {
+		return nil, fmt.Errorf("tightMargin: %w", err)
+	}
+
+	airports := make(map[string]connections.Times, len(cfg.Airports))
+	for airport, times := range cfg.Airports {
+		var parsed connections.Times
+		if parsed.Domestic, err = durationOrDefault(times.Domestic, 0); err != nil {
+			return nil, fmt.Errorf("airport %s domestic: %w", airport, err)
+		}
+		if parsed.International, err = durationOrDefault(times.International, 0); err != nil {
+			return nil, fmt.Errorf("airport %s international: %w", airport, err)
+		}
+		airports[airport] = parsed
+	}
+
+	return connections.NewTable(connections.Times{Domestic: domestic, International: international}, airports, tightMargin)
+}
+
+// generateDispatcher constructs the background delivery of the webhook events
+func generateDispatcher(cfg config.Webhooks, subscriptions webhooks.SubscriptionStore) (*webhooks.Dispatcher, error) {
+	attempts := cfg.MaxAttempts
+	if attempts == 0 {
+		attempts = defaultWebhookAttempts
+	}
+
+	minBackoff, err := durationOrDefault(cfg.MinBackoff, defaultWebhookMinBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("webhooks minBackoff: %w", err)
+	}
+	maxBackoff, err := durationOrDefault(cfg.MaxBackoff, defaultWebhookMaxBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("webhooks maxBackoff: %w", err)
+	}
+	timeout, err := durationOrDefault(cfg.Timeout, defaultWebhookTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("webhooks timeout: %w", err)
+	}
+
+	dispatcher, err := webhooks.NewDispatcher(
+		log.WithField("component", "WebhookDispatcher"),
+		subscriptions,
+		webhooks.NewClient(timeout),
+		attempts,
+		minBackoff,
+		maxBackoff,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("webhooks: %w", err)
+	}
+	return dispatcher, nil
+}
+
+// generateAuthentication constructs the authentication middleware from the configured methods
+func generateAuthentication(cfg config.Auth) (*middlewares.Authentication, error) {
+	var authenticators []auth.Authenticator
+
+	if len(cfg.APIKeys) > 0 {
+		apiKeys, err := auth.NewAPIKeyAuthenticator(cfg.APIKeys)
+		if err != nil {
+			return nil, fmt.Errorf("api keys: %w", err)
+		}
+		authenticators = append(authenticators, apiKeys)
+	}
+
+	if cfg.JWKSFile != "" {
+		keys, err := auth.LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		jwt, err := auth.NewJWTAuthenticator(keys, cfg.Issuer, cfg.Audience)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: %w", err)
+		}
+		authenticators = append(authenticators, jwt)
+	}
+
+	return middlewares.NewAuthentication(log.WithField("middleware", "Authentication"), authenticators...)
+}
+
+// generateRateLimit constructs the rate limiting middleware from the configured limits
+func generateRateLimit(cfg config.RateLimit) (*middlewares.RateLimit, error) {
+	var (
+		defaultLimiter ratelimit.Limiter
+		quota          *ratelimit.Quota
+		err            error
+	)
+
+	if cfg.Default.Enabled() {
+		if defaultLimiter, err = newLimiter(cfg.Default); err != nil {
+			return nil, fmt.Errorf("default rate limit: %w", err)
+		}
+	}
+
+	routes := make(map[string]ratelimit.Limiter, len(cfg.Routes))
+	for route, limit := range cfg.Routes {
+		if routes[route], err = newLimiter(limit); err != nil {
+			return nil, fmt.Errorf("rate limit of %s: %w", route, err)
+		}
+	}
+
+	if cfg.DailyLegQuota > 0 {
+		if quota, err = ratelimit.NewQuota(ratelimit.NewMemoryQuotaStore(), cfg.DailyLegQuota); err != nil {
+			return nil, fmt.Errorf("daily leg quota: %w", err)
+		}
+	}
+
+	return middlewares.NewRateLimit(log.WithField("middleware", "RateLimit"), defaultLimiter, routes, quota)
+}
+
+// newLimiter constructs a token bucket, the burst defaults to one second of requests
+func newLimiter(limit config.Limit) (ratelimit.Limiter, error) {
+	burst := limit.Burst
+	if burst == 0 {
+		burst = int(math.Ceil(limit.RequestsPerSecond))
+	}
+
+	return ratelimit.NewTokenBucket(limit.RequestsPerSecond, burst)
+}
+
+// routeFeatures holds the optional tenant feature of the routes that depend on one
+var routeFeatures = map[string]string{
+	"/jobs":                  tenancy.FeatureJobs,
+	"/jobs/{id}":             tenancy.FeatureJobs,
+	"/jobs/{id}/result":      tenancy.FeatureJobs,
+	"/webhooks":              tenancy.FeatureWebhooks,
+	"/webhooks/dead-letters": tenancy.FeatureWebhooks,
+	"/webhooks/{id}":         tenancy.FeatureWebhooks,
+	"/analyze":               tenancy.FeatureAnalyze,
+	"/complete":              tenancy.FeatureComplete,
+	"/reconcile":             tenancy.FeatureReconcile,
+	"/diff":                  tenancy.FeatureDiff,
+	"/routes":                tenancy.FeatureRoutes,
+}
+
+// generateTenancy constructs the Tenancy middleware from the configured tenants, counting the requests by tenant in registry
+func generateTenancy(cfg config.Config, registry *metrics.Registry) (*middlewares.Tenancy, error) {
+	tenants, err := tenancy.NewRegistry(cfg.Tenants)
+	if err != nil {
+		return nil, fmt.Errorf("tenants: %w", err)
+	}
+	for _, key := range cfg.Auth.APIKeys {
+		if _, ok := tenants.Lookup(key.Tenant); key.Tenant != "" && !ok {
+			return nil, fmt.Errorf("api key %q: unknown tenant %q", key.ID, key.Tenant)
+		}
+	}
+
+	requests := registry.Counter("flight_tracking_requests_total", "Requests of the protected routes by tenant, route and status code.", "tenant", "route", "code")
+	return middlewares.NewTenancy(log.WithField("middleware", "Tenancy"), tenants, routeFeatures, requests)
+}
+
+// generateIdempotency constructs the Idempotency middleware with an in-memory store, and the index of the stored
+// responses of every traveler
+func generateIdempotency(cfg config.Idempotency) (*middlewares.Idempotency, *privacy.CacheIndex, error) {
+	window, err := durationOrDefault(cfg.Window, defaultIdempotencyWindow)
+	if err != nil {
+		return nil, nil, fmt.Errorf("idempotency window: %w", err)
+	}
+
+	store := idempotency.NewMemoryStore()
+	index, err := privacy.NewCacheIndex(privacy.DeleterFunc(store.Release), window)
+	if err != nil {
+		return nil, nil, fmt.Errorf("idempotency index: %w", err)
+	}
+	middleware, err := middlewares.NewIdempotency(log.WithField("middleware", "Idempotency"), store, index, window)
+	if err != nil {
+		return nil, nil, err
+	}
+	return middleware, index, nil
+}
+
+// generateTracer constructs the tracer exporting to the configured backend
+func generateTracer(cfg config.Tracing) (*tracing.Tracer, error) {
+	service := cfg.ServiceName
+	if service == "" {
+		service = defaultServiceName
+	}
+
+	var (
+		exporter tracing.Exporter
+		err      error
+	)
+	switch cfg.Exporter {
+	case "stdout":
+		exporter, err = tracing.NewStdoutExporter(os.Stdout)
+	case "otlp":
+		exporter, err = tracing.NewOTLPExporter(cfg.Endpoint, service, &http.Client{Timeout: 10 * time.Second})
+	default:
+		err = fmt.Errorf("unknown exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tracing: %w", err)
+	}
+
+	return tracing.NewTracer(service, exporter)
+}
 
-	return flightTrackerController
+// durationOrDefault parses a configured duration such as "1m30s", returning fallback when it is empty
+func durationOrDefault(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return time.ParseDuration(value)
 }
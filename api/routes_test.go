@@ -0,0 +1,55 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/config"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+func TestRoutes_TenantFeatures(t *testing.T) {
+	key := sha256.Sum256([]byte("acme-key"))
+	router, _, err := newRouter(config.Config{
+		Auth: config.Auth{APIKeys: []config.APIKey{
+			{ID: "acme-backend", Hash: hex.EncodeToString(key[:]), Tenant: "acme"},
+		}},
+		Tenants: []config.Tenant{{ID: "acme", Features: []string{tenancy.FeatureCSV}}},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+	}{
+		{name: "failure_response_when_analyze_is_not_enabled", method: http.MethodPost, target: "/analyze", wantStatus: http.StatusForbidden},
+		{name: "failure_response_when_complete_is_not_enabled", method: http.MethodPost, target: "/complete", wantStatus: http.StatusForbidden},
+		{name: "failure_response_when_reconcile_is_not_enabled", method: http.MethodPost, target: "/reconcile", wantStatus: http.StatusForbidden},
+		{name: "failure_response_when_diff_is_not_enabled", method: http.MethodPost, target: "/diff", wantStatus: http.StatusForbidden},
+		{name: "failure_response_when_routes_is_not_enabled", method: http.MethodGet, target: "/routes?from=SFO&to=EWR", wantStatus: http.StatusForbidden},
+		{name: "failure_response_when_jobs_is_not_enabled", method: http.MethodPost, target: "/jobs", wantStatus: http.StatusForbidden},
+		{name: "failure_response_when_webhooks_is_not_enabled", method: http.MethodGet, target: "/webhooks", wantStatus: http.StatusForbidden},
+		{name: "should_serve_the_routes_without_feature", method: http.MethodPost, target: "/calculate", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.target, strings.NewReader("{}"))
+			request.Header.Set(auth.APIKeyHeader, "acme-key")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.wantStatus, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+// Audited actions
+const (
+	ActionCalculate = "path.calculate"
+	// ActionAnalyze is the analysis of an itinerary for anomalies
+	ActionAnalyze = "itinerary.analyze"
+	// ActionComplete is the completion of an itinerary with legs of the known network
+	ActionComplete = "itinerary.complete"
+	// ActionReconcile is the reconciliation of the itineraries of a traveler reported by several sources
+	ActionReconcile = "itinerary.reconcile"
+	// ActionDiff is the comparison of two versions of an itinerary
+	ActionDiff = "itinerary.diff"
+	// ActionErase is the tombstone left by the erasure of a traveler, it keeps the erased user id
+	ActionErase = "user.erase"
+)
+
+// Outcomes of the audited actions
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// anonymous is the actor of the requests without identity
+const anonymous = "anonymous"
+
+// Entry records who performed an action on the itinerary of a traveler and how it ended
+type Entry struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"requestId,omitempty"`
+	// Actor is the authenticated caller, "anonymous" when authentication is disabled
+	Actor  string `json:"actor"`
+	Tenant string `json:"tenant,omitempty"`
+	Action string `json:"action"`
+	// UserID identifies the traveler, when the request names one
+	UserID string `json:"userId,omitempty"`
+	// RequestHash is the hex SHA-256 of the decoded request, empty when the request could not be decoded
+	RequestHash string `json:"requestHash,omitempty"`
+	Outcome     string `json:"outcome"`
+	// Status is the HTTP status code of the response
+	Status int `json:"status"`
+}
+
+// Filter selects entries, empty fields match every entry
+type Filter struct {
+	Tenant string
+	Actor  string
+	Action string
+	UserID string
+	// From and To bound the time of the entries, inclusive and exclusive
+	From time.Time
+	To   time.Time
+	// Limit is the maximum number of entries returned, unlimited when zero
+	Limit int
+}
+
+// Matches reports whether the entry is selected by the filter, regardless of Limit
+func (f Filter) Matches(e Entry) bool {
+	switch {
+	case f.Tenant != "" && e.Tenant != f.Tenant,
+		f.Actor != "" && e.Actor != f.Actor,
+		f.Action != "" && e.Action != f.Action,
+		f.UserID != "" && e.UserID != f.UserID,
+		!f.From.IsZero() && e.Time.Before(f.From),
+		!f.To.IsZero() && !e.Time.Before(f.To):
+		return false
+	}
+	return true
+}
+
+// Sink appends entries to the audit log, implementations must be safe for concurrent use and never
+// modify or delete the entries written, other than to erase the references to a traveler
+type Sink interface {
+	Write(ctx context.Context, entry Entry) error
+}
+
+// Eraser is implemented by the sinks able to erase the references to a traveler
+type Eraser interface {
+	// Erase clears the user id and request hash of the entries of userID in tenant, tombstones excepted,
+	// and returns the number of entries erased
+	Erase(ctx context.Context, tenant, userID string) (int, error)
+}
+
+// Reader queries the audit log
+type Reader interface {
+	// Query returns the entries selected by filter, oldest first
+	Query(ctx context.Context, filter Filter) ([]Entry, error)
+}
+
+// Recorder records the audited actions
+type Recorder interface {
+	Record(ctx context.Context, entry Entry)
+}
+
+// Auditor writes every recorded entry to all of its sinks
+type Auditor struct {
+	Logger *log.Entry
+	Sinks  []Sink
+	Now    func() time.Time
+}
+
+// NewAuditor returns a recorder writing to sinks
+func NewAuditor(log *log.Entry, sinks ...Sink) (*Auditor, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case len(sinks) == 0:
+		return nil, errors.New("sinks")
+	}
+
+	return &Auditor{
+		Logger: log,
+		Sinks:  sinks,
+		Now:    time.Now,
+	}, nil
+}
+
+// Record completes the entry with its id, time, request id, actor and tenant taken from ctx, and writes it.
+// Sink failures are logged, they don't fail the audited request.
+func (a *Auditor) Record(ctx context.Context, entry Entry) {
+	entry.ID = newID()
+	entry.Time = a.Now().UTC()
+	entry.RequestID = logging.RequestID(ctx)
+	entry.Actor = auth.Owner(ctx)
+	if entry.Actor == "" {
+		entry.Actor = anonymous
+	}
+	entry.Tenant = tenancy.ID(ctx)
+
+	for _, sink := range a.Sinks {
+		if err := sink.Write(ctx, entry); err != nil {
+			logging.FromContext(ctx, a.Logger).WithError(err).WithField("audit_id", entry.ID).Error("error writing audit entry")
+		}
+	}
+}
+
+// Erase clears the references to userID in the tenant of ctx from every sink able to, and returns the number
+// of entries erased. The sinks holding the same entries, the largest count is returned.
+func (a *Auditor) Erase(ctx context.Context, userID string) (int, error) {
+	var (
+		erased int
+		errs   []error
+	)
+	for _, sink := range a.Sinks {
+		eraser, ok := sink.(Eraser)
+		if !ok {
+			continue
+		}
+		count, err := eraser.Erase(ctx, tenancy.ID(ctx), userID)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if count > erased {
+			erased = count
+		}
+	}
+	return erased, errors.Join(errs...)
+}
+
+// Outcome returns the outcome of a response with status
+func Outcome(status int) string {
+	if status < http.StatusBadRequest {
+		return OutcomeSuccess
+	}
+	return OutcomeFailure
+}
+
+// erasable reports whether the entry references userID in tenant and isn't a tombstone
+func erasable(e Entry, tenant, userID string) bool {
+	return userID != "" && e.Tenant == tenant && e.UserID == userID && e.Action != ActionErase
+}
+
+// anonymize returns the entry without its references to the traveler
+func anonymize(e Entry) Entry {
+	e.UserID, e.RequestHash = "", ""
+	return e
+}
+
+func newID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
@@ -0,0 +1,185 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+func TestAudit_NewAuditor(t *testing.T) {
+	sink, err := audit.NewMemorySink(1)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		sinks     []audit.Sink
+		wantError error
+	}{
+		{name: "should_return_success", logger: log.NewEntry(nil), sinks: []audit.Sink{sink}},
+		{name: "should_return_error_when_the_logger_is_nil", sinks: []audit.Sink{sink}, wantError: errors.New("logger")},
+		{name: "should_return_error_when_there_are_no_sinks", logger: log.NewEntry(nil), wantError: errors.New("sinks")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := audit.NewAuditor(tt.logger, tt.sinks...)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// failingSink rejects every entry
+type failingSink struct{}
+
+func (failingSink) Write(context.Context, audit.Entry) error { return errors.New("disk full") }
+
+func TestAudit_Record(t *testing.T) {
+	now := time.Date(2023, 6, 13, 10, 0, 0, 0, time.UTC)
+	sink, err := audit.NewMemorySink(10)
+	require.NoError(t, err)
+
+	auditor, err := audit.NewAuditor(log.NewEntry(log.New()), failingSink{}, sink)
+	require.NoError(t, err)
+	auditor.Now = func() time.Time { return now }
+
+	t.Run("should_complete_the_entry_from_the_context", func(t *testing.T) {
+		ctx := logging.WithRequestID(context.Background(), "req-1")
+		ctx = auth.NewContext(ctx, auth.Identity{Subject: "agent", Method: auth.MethodAPIKey})
+		ctx = tenancy.NewContext(ctx, tenancy.Tenant{ID: "acme"})
+
+		auditor.Record(ctx, audit.Entry{Action: audit.ActionCalculate, UserID: "u-1", Outcome: audit.Outcome(http.StatusOK), Status: http.StatusOK})
+
+		entries, err := sink.Query(context.Background(), audit.Filter{})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(entries))
+		assert.Assert(t, entries[0].ID != "")
+		assert.Equal(t, now, entries[0].Time)
+		assert.Equal(t, "req-1", entries[0].RequestID)
+		assert.Equal(t, "api_key:agent", entries[0].Actor)
+		assert.Equal(t, "acme", entries[0].Tenant)
+		assert.Equal(t, audit.OutcomeSuccess, entries[0].Outcome)
+	})
+
+	t.Run("should_record_anonymous_callers", func(t *testing.T) {
+		auditor.Record(context.Background(), audit.Entry{Action: audit.ActionCalculate, Outcome: audit.Outcome(http.StatusNotFound)})
+
+		entries, err := sink.Query(context.Background(), audit.Filter{Actor: "anonymous"})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(entries))
+		assert.Equal(t, audit.OutcomeFailure, entries[0].Outcome)
+		assert.Equal(t, "", entries[0].Tenant)
+	})
+}
+
+func TestAudit_MemorySink(t *testing.T) {
+	sink, err := audit.NewMemorySink(2)
+	require.NoError(t, err)
+
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, sink.Write(context.Background(), audit.Entry{ID: id}))
+	}
+
+	entries, err := sink.Query(context.Background(), audit.Filter{})
+	require.NoError(t, err)
+	assert.DeepEqual(t, []audit.Entry{{ID: "2"}, {ID: "3"}}, entries)
+
+	_, err = audit.NewMemorySink(0)
+	assert.Error(t, err, "capacity")
+}
+
+func TestAudit_FileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	start := time.Date(2023, 6, 13, 10, 0, 0, 0, time.UTC)
+
+	sink, err := audit.NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(context.Background(), audit.Entry{ID: "1", Time: start, UserID: "u-1", Status: 200}))
+	require.NoError(t, sink.Close())
+
+	// reopening appends to the existing entries
+	sink, err = audit.NewFileSink(path)
+	require.NoError(t, err)
+	defer sink.Close()
+	require.NoError(t, sink.Write(context.Background(), audit.Entry{ID: "2", Time: start.Add(time.Minute), UserID: "u-2"}))
+	require.NoError(t, sink.Write(context.Background(), audit.Entry{ID: "3", Time: start.Add(2 * time.Minute), UserID: "u-1"}))
+
+	entries, err := sink.Query(context.Background(), audit.Filter{UserID: "u-1"})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(entries))
+	assert.Equal(t, "1", entries[0].ID)
+	assert.Equal(t, start, entries[0].Time)
+	assert.Equal(t, 200, entries[0].Status)
+	assert.Equal(t, "3", entries[1].ID)
+
+	entries, err = sink.Query(context.Background(), audit.Filter{From: start.Add(time.Minute), Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, "2", entries[0].ID)
+}
+
+func TestAudit_Erase(t *testing.T) {
+	acme := tenancy.NewContext(context.Background(), tenancy.Tenant{ID: "acme"})
+	entries := []audit.Entry{
+		{ID: "1", Tenant: "acme", Action: audit.ActionCalculate, UserID: "u-1", RequestHash: "abc"},
+		{ID: "2", Tenant: "acme", Action: audit.ActionCalculate, UserID: "u-2", RequestHash: "def"},
+		{ID: "3", Tenant: "globex", Action: audit.ActionCalculate, UserID: "u-1", RequestHash: "abc"},
+		{ID: "4", Tenant: "acme", Action: audit.ActionErase, UserID: "u-1"},
+	}
+	want := []audit.Entry{
+		{ID: "1", Tenant: "acme", Action: audit.ActionCalculate},
+		entries[1],
+		entries[2],
+		entries[3],
+	}
+
+	memory, err := audit.NewMemorySink(10)
+	require.NoError(t, err)
+	file, err := audit.NewFileSink(filepath.Join(t.TempDir(), "audit.jsonl"))
+	require.NoError(t, err)
+	defer file.Close()
+
+	auditor, err := audit.NewAuditor(log.NewEntry(log.New()), memory, file, failingSink{})
+	require.NoError(t, err)
+	for _, entry := range entries {
+		require.NoError(t, memory.Write(context.Background(), entry))
+		require.NoError(t, file.Write(context.Background(), entry))
+	}
+
+	erased, err := auditor.Erase(acme, "u-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, erased)
+
+	for _, sink := range []audit.Reader{memory, file} {
+		got, err := sink.Query(context.Background(), audit.Filter{})
+		require.NoError(t, err)
+		assert.DeepEqual(t, want, got)
+	}
+
+	// the file is still appended to after its rewrite
+	require.NoError(t, file.Write(context.Background(), audit.Entry{ID: "5"}))
+	got, err := file.Query(context.Background(), audit.Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, 5, len(got))
+
+	erased, err = auditor.Erase(acme, "u-1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, erased)
+}
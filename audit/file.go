@@ -0,0 +1,167 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// maxLineSize bounds the size of an entry read back from the file
+const maxLineSize = 1 << 20
+
+// FileSink appends the entries to a JSON Lines file, one entry per line
+type FileSink struct {
+	Path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens path for appending, creating it when it doesn't exist
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{Path: path, file: file}, nil
+}
+
+// Write appends the entry and flushes it to disk
+func (s *FileSink) Write(_ context.Context, entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(line); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Query scans the file for the entries selected by filter, oldest first
+func (s *FileSink) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64<<10), maxLineSize)
+
+	var entries []Entry
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if filter.Limit > 0 && len(entries) >= filter.Limit {
+			break
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", s.Path, line, err)
+		}
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+// Erase clears the references to userID in tenant by rewriting the file, which is replaced atomically
+func (s *FileSink) Erase(_ context.Context, tenant, userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		erased int
+		buffer bytes.Buffer
+	)
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return 0, fmt.Errorf("%s:%d: %w", s.Path, i+1, err)
+		}
+		if !erasable(entry, tenant, userID) {
+			buffer.Write(line)
+			continue
+		}
+
+		anonymized, err := json.Marshal(anonymize(entry))
+		if err != nil {
+			return 0, err
+		}
+		buffer.Write(append(anonymized, '\n'))
+		erased++
+	}
+	if erased == 0 {
+		return 0, nil
+	}
+
+	if err := s.replace(buffer.Bytes()); err != nil {
+		return 0, err
+	}
+	return erased, nil
+}
+
+// replace atomically replaces the content of the file with data and reopens it for appending,
+// the caller must hold the mutex
+func (s *FileSink) replace(data []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		_ = temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		_ = temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(temp.Name(), s.Path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_ = s.file.Close()
+	s.file = file
+	return nil
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// MemorySink keeps the most recent entries in memory, the oldest are dropped once the capacity is reached
+type MemorySink struct {
+	Capacity int
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewMemorySink returns a sink keeping up to capacity entries
+func NewMemorySink(capacity int) (*MemorySink, error) {
+	if capacity <= 0 {
+		return nil, errors.New("capacity")
+	}
+	return &MemorySink{Capacity: capacity}, nil
+}
+
+// Write appends the entry
+func (s *MemorySink) Write(_ context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.entries) >= s.Capacity {
+		s.entries = s.entries[1:]
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// Query returns the entries selected by filter, oldest first
+func (s *MemorySink) Query(_ context.Context, filter Filter) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []Entry
+	for _, entry := range s.entries {
+		if filter.Limit > 0 && len(entries) >= filter.Limit {
+			break
+		}
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// Erase clears the references to userID in tenant
+func (s *MemorySink) Erase(_ context.Context, tenant, userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var erased int
+	for i := range s.entries {
+		if erasable(s.entries[i], tenant, userID) {
+			s.entries[i] = anonymize(s.entries[i])
+			erased++
+		}
+	}
+	return erased, nil
+}
@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// validTable restricts the table names interpolated in the statements
+var validTable = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// columns are the columns of the audit table, in the order of the Entry fields
+const columns = "id, time, request_id, actor, tenant, action, user_id, request_hash, outcome, status"
+
+// SQLSink inserts the entries in a database table, see the README for its schema.
+// Rows are never deleted and only updated to erase a traveler.
+type SQLSink struct {
+	DB    *sql.DB
+	Table string
+	// Numbered selects the $1, $2... placeholders of PostgreSQL instead of ?
+	Numbered bool
+}
+
+// NewSQLSink returns a sink writing to table through db
+func NewSQLSink(db *sql.DB, table string, numbered bool) (*SQLSink, error) {
+	switch {
+	case db == nil:
+		return nil, errors.New("db")
+	case !validTable.MatchString(table):
+		return nil, errors.New("table")
+	}
+
+	return &SQLSink{DB: db, Table: table, Numbered: numbered}, nil
+}
+
+// Write inserts the entry
+func (s *SQLSink) Write(ctx context.Context, e Entry) error {
+	placeholders := make([]string, 10)
+	for i := range placeholders {
+		placeholders[i] = s.placeholder(i + 1)
+	}
+
+	query := "INSERT INTO " + s.Table + " (" + columns + ") VALUES (" + strings.Join(placeholders, ", ") + ")"
+	_, err := s.DB.ExecContext(ctx, query,
+		e.ID, e.Time, e.RequestID, e.Actor, e.Tenant, e.Action, e.UserID, e.RequestHash, e.Outcome, e.Status)
+	return err
+}
+
+// Erase clears the references to userID in tenant, which requires the UPDATE privilege on the user_id and
+// request_hash columns
+func (s *SQLSink) Erase(ctx context.Context, tenant, userID string) (int, error) {
+	if userID == "" {
+		return 0, nil
+	}
+
+	query := "UPDATE " + s.Table + " SET user_id = '', request_hash = ''" +
+		" WHERE tenant = " + s.placeholder(1) + " AND user_id = " + s.placeholder(2) + " AND action <> " + s.placeholder(3)
+	result, err := s.DB.ExecContext(ctx, query, tenant, userID, ActionErase)
+	if err != nil {
+		return 0, err
+	}
+
+	erased, err := result.RowsAffected()
+	return int(erased), err
+}
+
+// Query selects the entries matching filter, oldest first
+func (s *SQLSink) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, condition+" "+s.placeholder(len(args)))
+	}
+	if filter.Tenant != "" {
+		where("tenant =", filter.Tenant)
+	}
+	if filter.Actor != "" {
+		where("actor =", filter.Actor)
+	}
+	if filter.Action != "" {
+		where("action =", filter.Action)
+	}
+	if filter.UserID != "" {
+		where("user_id =", filter.UserID)
+	}
+	if !filter.From.IsZero() {
+		where("time >=", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("time <", filter.To)
+	}
+
+	query := "SELECT " + columns + " FROM " + s.Table
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY time, id"
+	if filter.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(filter.Limit)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.ID, &e.Time, &e.RequestID, &e.Actor, &e.Tenant, &e.Action, &e.UserID, &e.RequestHash, &e.Outcome, &e.Status); err != nil {
+			return nil, fmt.Errorf("scanning audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+func (s *SQLSink) placeholder(n int) string {
+	if s.Numbered {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
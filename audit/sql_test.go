@@ -0,0 +1,171 @@
+package audit_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/audit"
+)
+
+// fakeDB records the statements run through it and answers the queries with rows
+type fakeDB struct {
+	mu         sync.Mutex
+	statements []string
+	args       [][]driver.Value
+	rows       [][]driver.Value
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+func (db *fakeDB) record(statement string, args []driver.Value) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.statements = append(db.statements, statement)
+	db.args = append(db.args, args)
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.record(s.query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.record(s.query, args)
+	return &fakeRows{rows: s.db.rows}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id", "time", "request_id", "actor", "tenant", "action", "user_id", "request_hash", "outcome", "status"}
+}
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+func TestAudit_NewSQLSink(t *testing.T) {
+	db := sql.OpenDB(&fakeDB{})
+	defer db.Close()
+
+	tests := []struct {
+		name      string
+		db        *sql.DB
+		table     string
+		wantError error
+	}{
+		{name: "should_return_success", db: db, table: "compliance.audit_log"},
+		{name: "should_return_error_when_the_db_is_nil", table: "audit_log", wantError: errors.New("db")},
+		{name: "should_return_error_when_the_table_is_invalid", db: db, table: "audit_log; DROP TABLE audit_log", wantError: errors.New("table")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := audit.NewSQLSink(tt.db, tt.table, false)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAudit_SQLSink(t *testing.T) {
+	at := time.Date(2023, 6, 13, 10, 0, 0, 0, time.UTC)
+	entry := audit.Entry{
+		ID: "1", Time: at, RequestID: "req-1", Actor: "api_key:agent", Tenant: "acme", Action: audit.ActionCalculate,
+		UserID: "u-1", RequestHash: "abc", Outcome: audit.OutcomeSuccess, Status: 200,
+	}
+
+	t.Run("should_insert_the_entry", func(t *testing.T) {
+		fake := &fakeDB{}
+		db := sql.OpenDB(fake)
+		defer db.Close()
+
+		sink, err := audit.NewSQLSink(db, "audit_log", true)
+		require.NoError(t, err)
+		require.NoError(t, sink.Write(context.Background(), entry))
+
+		assert.DeepEqual(t, []string{
+			"INSERT INTO audit_log (id, time, request_id, actor, tenant, action, user_id, request_hash, outcome, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)",
+		}, fake.statements)
+		assert.DeepEqual(t, []driver.Value{"1", at, "req-1", "api_key:agent", "acme", audit.ActionCalculate, "u-1", "abc", audit.OutcomeSuccess, int64(200)}, fake.args[0])
+	})
+
+	t.Run("should_query_the_entries", func(t *testing.T) {
+		fake := &fakeDB{rows: [][]driver.Value{
+			{"1", at, "req-1", "api_key:agent", "acme", audit.ActionCalculate, "u-1", "abc", audit.OutcomeSuccess, int64(200)},
+		}}
+		db := sql.OpenDB(fake)
+		defer db.Close()
+
+		sink, err := audit.NewSQLSink(db, "audit_log", false)
+		require.NoError(t, err)
+
+		entries, err := sink.Query(context.Background(), audit.Filter{Tenant: "acme", UserID: "u-1", From: at, Limit: 10})
+		require.NoError(t, err)
+
+		assert.DeepEqual(t, []audit.Entry{entry}, entries)
+		assert.DeepEqual(t, []string{
+			"SELECT id, time, request_id, actor, tenant, action, user_id, request_hash, outcome, status FROM audit_log WHERE tenant = ? AND user_id = ? AND time >= ? ORDER BY time, id LIMIT 10",
+		}, fake.statements)
+		assert.DeepEqual(t, []driver.Value{"acme", "u-1", at}, fake.args[0])
+	})
+
+	t.Run("should_erase_the_references_to_a_traveler", func(t *testing.T) {
+		fake := &fakeDB{}
+		db := sql.OpenDB(fake)
+		defer db.Close()
+
+		sink, err := audit.NewSQLSink(db, "audit_log", true)
+		require.NoError(t, err)
+
+		erased, err := sink.Erase(context.Background(), "acme", "u-1")
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, erased)
+		assert.DeepEqual(t, []string{
+			"UPDATE audit_log SET user_id = '', request_hash = '' WHERE tenant = $1 AND user_id = $2 AND action <> $3",
+		}, fake.statements)
+		assert.DeepEqual(t, []driver.Value{"acme", "u-1", audit.ActionErase}, fake.args[0])
+	})
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/volume/service/user-flight-tracking/config"
+)
+
+// APIKeyHeader is the header carrying a static API key
+const APIKeyHeader = "X-API-Key"
+
+type hashedKey struct {
+	id     string
+	hash   []byte
+	roles  []string
+	tenant string
+}
+
+// apiKeyAuthenticator validates static API keys against their SHA-256 hashes
+type apiKeyAuthenticator struct {
+	keys []hashedKey
+}
+
+// NewAPIKeyAuthenticator returns an Authenticator for the configured API keys
+func NewAPIKeyAuthenticator(keys []config.APIKey) (Authenticator, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("keys")
+	}
+
+	hashed := make([]hashedKey, 0, len(keys))
+	for _, key := range keys {
+		hash, err := hex.DecodeString(key.Hash)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("invalid hash for api key %q", key.ID)
+		}
+		hashed = append(hashed, hashedKey{id: key.ID, hash: hash, roles: key.Roles, tenant: key.Tenant})
+	}
+
+	return &apiKeyAuthenticator{keys: hashed}, nil
+}
+
+// Authenticate reads the key from the X-API-Key header or an "ApiKey" authorization scheme
+func (a *apiKeyAuthenticator) Authenticate(r *http.Request) (Identity, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		if scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "ApiKey") {
+			key = strings.TrimSpace(value)
+		}
+	}
+	if key == "" {
+		return Identity{}, ErrNoCredentials
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare(sum[:], k.hash) == 1 {
+			return Identity{Subject: k.id, Method: MethodAPIKey, Roles: k.roles, Tenant: k.tenant}, nil
+		}
+	}
+
+	return Identity{}, ErrInvalidCredentials
+}
+
+// HashAPIKey returns the hex encoded SHA-256 of a key, as expected in the configuration
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,109 @@
+package auth_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/config"
+)
+
+func TestAuth_NewAPIKeyAuthenticator(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      []config.APIKey
+		wantError error
+	}{
+		{
+			name:      "should_return_success",
+			keys:      []config.APIKey{{ID: "batch", Hash: auth.HashAPIKey("secret")}},
+			wantError: nil,
+		},
+		{
+			name:      "should_return_error_when_there_are_no_keys",
+			keys:      nil,
+			wantError: errors.New("keys"),
+		},
+		{
+			name:      "should_return_error_when_the_hash_is_invalid",
+			keys:      []config.APIKey{{ID: "batch", Hash: "secret"}},
+			wantError: errors.New(`invalid hash for api key "batch"`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := auth.NewAPIKeyAuthenticator(tt.keys)
+			if err != nil {
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+			}
+		})
+	}
+}
+
+func TestAuth_APIKeyAuthenticate(t *testing.T) {
+	a, err := auth.NewAPIKeyAuthenticator([]config.APIKey{
+		{ID: "batch", Hash: auth.HashAPIKey("secret"), Roles: []string{"admin"}},
+		{ID: "agency", Hash: auth.HashAPIKey("agency-secret"), Tenant: "acme"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		header       string
+		value        string
+		wantIdentity auth.Identity
+		wantError    error
+	}{
+		{
+			name:         "should_return_identity_from_api_key_header",
+			header:       auth.APIKeyHeader,
+			value:        "secret",
+			wantIdentity: auth.Identity{Subject: "batch", Method: auth.MethodAPIKey, Roles: []string{"admin"}},
+		},
+		{
+			name:         "should_return_identity_from_authorization_header",
+			header:       "Authorization",
+			value:        "ApiKey secret",
+			wantIdentity: auth.Identity{Subject: "batch", Method: auth.MethodAPIKey, Roles: []string{"admin"}},
+		},
+		{
+			name:         "should_return_identity_bound_to_the_tenant_of_the_key",
+			header:       auth.APIKeyHeader,
+			value:        "agency-secret",
+			wantIdentity: auth.Identity{Subject: "agency", Method: auth.MethodAPIKey, Tenant: "acme"},
+		},
+		{
+			name:      "should_return_error_when_the_key_is_unknown",
+			header:    auth.APIKeyHeader,
+			value:     "other",
+			wantError: auth.ErrInvalidCredentials,
+		},
+		{
+			name:      "should_return_error_when_there_is_no_key",
+			header:    "Authorization",
+			value:     "Bearer token",
+			wantError: auth.ErrNoCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/calculate", nil)
+			request.Header.Set(tt.header, tt.value)
+
+			identity, err := a.Authenticate(request)
+			if tt.wantError != nil {
+				assert.Assert(t, errors.Is(err, tt.wantError))
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tt.wantIdentity, identity)
+		})
+	}
+}
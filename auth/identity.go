@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Authentication methods
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// RoleAdmin is the role of the operators allowed to read the audit log
+const RoleAdmin = "admin"
+
+var (
+	// ErrNoCredentials is returned when the request does not carry credentials for an authenticator
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned when the request carries credentials that can not be accepted
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Identity describes the authenticated caller
+type Identity struct {
+	Subject string
+	Method  string
+	Roles   []string
+	// Tenant is the tenant the credentials are bound to, empty when unbound
+	Tenant string
+}
+
+// HasRole reports whether the identity owns the given role
+func (i Identity) HasRole(role string) bool {
+	for _, r := range i.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator resolves the identity of the caller of a request
+type Authenticator interface {
+	Authenticate(r *http.Request) (Identity, error)
+}
+
+type identityKey struct{}
+
+// NewContext returns a copy of ctx carrying the identity
+func NewContext(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the identity stored in ctx, if any
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// Owner returns the key under which the resources created by the caller of ctx are kept,
+// empty for anonymous callers
+func Owner(ctx context.Context) string {
+	if identity, ok := FromContext(ctx); ok {
+		return identity.Method + ":" + identity.Subject
+	}
+	return ""
+}
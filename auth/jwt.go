@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// leeway tolerates small clock differences when checking time based claims
+const leeway = 30 * time.Second
+
+// claims are the registered JWT claims understood by the service
+type claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	Roles     []string `json:"roles"`
+	Scope     string   `json:"scope"`
+	Tenant    string   `json:"tenant"`
+}
+
+// audience accepts both the string and the array forms of the "aud" claim
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (a audience) contains(aud string) bool {
+	for _, v := range a {
+		if v == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// jwtAuthenticator validates bearer tokens signed by one of the keys of a JWKS
+type jwtAuthenticator struct {
+	keys     map[string]crypto.PublicKey
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// NewJWTAuthenticator returns an Authenticator validating bearer tokens against the given keys
+func NewJWTAuthenticator(keys map[string]crypto.PublicKey, issuer, audience string) (Authenticator, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("keys")
+	}
+
+	return &jwtAuthenticator{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		now:      time.Now,
+	}, nil
+}
+
+// Authenticate reads a bearer token from the authorization header and validates it
+func (a *jwtAuthenticator) Authenticate(r *http.Request) (Identity, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return Identity{}, ErrNoCredentials
+	}
+
+	c, err := a.verify(strings.TrimSpace(token))
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	roles := c.Roles
+	if len(roles) == 0 && c.Scope != "" {
+		roles = strings.Fields(c.Scope)
+	}
+
+	return Identity{Subject: c.Subject, Method: MethodJWT, Roles: roles, Tenant: c.Tenant}, nil
+}
+
+func (a *jwtAuthenticator) verify(token string) (claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims{}, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return claims{}, fmt.Errorf("decoding header: %w", err)
+	}
+
+	key, ok := a.keys[header.Kid]
+	if !ok {
+		return claims{}, fmt.Errorf("unknown key id %q", header.Kid)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return claims{}, fmt.Errorf("decoding signature: %w", err)
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return claims{}, err
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return claims{}, fmt.Errorf("decoding claims: %w", err)
+	}
+
+	now := a.now()
+	switch {
+	case c.Subject == "":
+		return claims{}, errors.New("missing subject")
+	case c.ExpiresAt == 0:
+		return claims{}, errors.New("missing expiration")
+	case now.After(time.Unix(c.ExpiresAt, 0).Add(leeway)):
+		return claims{}, errors.New("token expired")
+	case c.NotBefore != 0 && now.Add(leeway).Before(time.Unix(c.NotBefore, 0)):
+		return claims{}, errors.New("token not yet valid")
+	case a.issuer != "" && c.Issuer != a.issuer:
+		return claims{}, fmt.Errorf("unexpected issuer %q", c.Issuer)
+	case a.audience != "" && !c.Audience.contains(a.audience):
+		return claims{}, errors.New("unexpected audience")
+	}
+
+	return c, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	var (
+		h        hash.Hash
+		hashType crypto.Hash
+	)
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	switch alg[2:] {
+	case "256":
+		h, hashType = sha256.New(), crypto.SHA256
+	case "384":
+		h, hashType = sha512.New384(), crypto.SHA384
+	case "512":
+		h, hashType = sha512.New(), crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("algorithm %q does not match key type", alg)
+		}
+		return rsa.VerifyPKCS1v15(pub, hashType, digest, signature)
+	case "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("algorithm %q does not match key type", alg)
+		}
+		return rsa.VerifyPSS(pub, hashType, digest, signature, nil)
+	case "ES":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("algorithm %q does not match key type", alg)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+}
@@ -0,0 +1,165 @@
+package auth_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/auth"
+)
+
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func jwks(t *testing.T, rsaKey *rsa.PublicKey, ecKey *ecdsa.PublicKey) []byte {
+	t.Helper()
+
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	data, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecKey.X.Bytes()), "y": encode(ecKey.Y.Bytes())},
+		},
+	})
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestAuth_JWTAuthenticate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keys, err := auth.ParseJWKS(jwks(t, &rsaKey.PublicKey, &ecKey.PublicKey))
+	require.NoError(t, err)
+
+	a, err := auth.NewJWTAuthenticator(keys, "issuer", "flight-tracking")
+	require.NoError(t, err)
+
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sub":   "user-1",
+			"iss":   "issuer",
+			"aud":   []string{"flight-tracking"},
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": []string{"admin"},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		token        string
+		wantIdentity auth.Identity
+		wantError    error
+	}{
+		{
+			name:         "should_return_identity_from_rsa_token",
+			token:        signToken(t, "RS256", "rsa", rsaKey, valid()),
+			wantIdentity: auth.Identity{Subject: "user-1", Method: auth.MethodJWT, Roles: []string{"admin"}},
+		},
+		{
+			name:         "should_return_identity_from_ec_token",
+			token:        signToken(t, "ES256", "ec", ecKey, valid()),
+			wantIdentity: auth.Identity{Subject: "user-1", Method: auth.MethodJWT, Roles: []string{"admin"}},
+		},
+		{
+			name: "should_return_identity_bound_to_the_tenant_claim",
+			token: signToken(t, "RS256", "rsa", rsaKey, func() map[string]interface{} {
+				c := valid()
+				c["tenant"] = "acme"
+				return c
+			}()),
+			wantIdentity: auth.Identity{Subject: "user-1", Method: auth.MethodJWT, Roles: []string{"admin"}, Tenant: "acme"},
+		},
+		{
+			name:      "should_return_error_when_the_signature_is_invalid",
+			token:     signToken(t, "RS256", "rsa", otherKey, valid()),
+			wantError: auth.ErrInvalidCredentials,
+		},
+		{
+			name: "should_return_error_when_the_token_is_expired",
+			token: func() string {
+				c := valid()
+				c["exp"] = time.Now().Add(-time.Hour).Unix()
+				return signToken(t, "RS256", "rsa", rsaKey, c)
+			}(),
+			wantError: auth.ErrInvalidCredentials,
+		},
+		{
+			name: "should_return_error_when_the_audience_does_not_match",
+			token: func() string {
+				c := valid()
+				c["aud"] = "other"
+				return signToken(t, "RS256", "rsa", rsaKey, c)
+			}(),
+			wantError: auth.ErrInvalidCredentials,
+		},
+		{
+			name:      "should_return_error_when_the_key_id_is_unknown",
+			token:     signToken(t, "RS256", "unknown", rsaKey, valid()),
+			wantError: auth.ErrInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/calculate", nil)
+			request.Header.Set("Authorization", "Bearer "+tt.token)
+
+			identity, err := a.Authenticate(request)
+			if tt.wantError != nil {
+				assert.Assert(t, errors.Is(err, tt.wantError))
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tt.wantIdentity, identity)
+		})
+	}
+
+	t.Run("should_return_no_credentials_without_bearer_token", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/calculate", nil)
+
+		_, err := a.Authenticate(request)
+		assert.Assert(t, errors.Is(err, auth.ErrNoCredentials))
+	})
+}
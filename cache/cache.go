@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/volume/service/user-flight-tracking/metrics"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+// Backend stores encoded results by key, implementations must be safe for concurrent use
+type Backend interface {
+	// Get returns the value stored under key and whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte) error
+	// Delete removes the value stored under key, if any
+	Delete(ctx context.Context, key string) error
+}
+
+// Key returns the canonical key of a set of legs: the hex SHA-256 of the legs sorted,
+// so that the same itinerary submitted in any order shares its key
+func Key(flights [][]string) string {
+	legs := make([]string, len(flights))
+	for i, flight := range flights {
+		legs[i] = strings.Join(flight, ">")
+	}
+	sort.Strings(legs)
+
+	sum := sha256.Sum256([]byte(strings.Join(legs, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// entry is an element of the LRU list
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// LRU keeps the most recently used values in memory, each one for a limited time
+type LRU struct {
+	Capacity int
+	TTL      time.Duration
+	Now      func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// NewLRU returns an in-memory backend holding up to capacity values, each one for ttl
+func NewLRU(capacity int, ttl time.Duration) (*LRU, error) {
+	switch {
+	case capacity <= 0:
+		return nil, errors.New("capacity")
+	case ttl <= 0:
+		return nil, errors.New("ttl")
+	}
+
+	return &LRU{
+		Capacity: capacity,
+		TTL:      ttl,
+		Now:      time.Now,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}, nil
+}
+
+// Get returns the value of key unless it has expired
+func (c *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	e := element.Value.(*entry)
+	if !c.Now().Before(e.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(element)
+	return e.value, true, nil
+}
+
+// Set stores the value of key, evicting the least recently used value when the capacity is exceeded
+func (c *LRU) Set(_ context.Context, key string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.Now().Add(c.TTL)
+	if element, ok := c.entries[key]; ok {
+		e := element.Value.(*entry)
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.Capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+	}
+
+	return nil
+}
+
+// Delete removes the value of key
+func (c *LRU) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+// Len returns the number of values held, including the expired ones not yet evicted
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// instrumented counts the hits and misses of a backend
+type instrumented struct {
+	Backend
+	requests *metrics.Counter
+}
+
+// WithMetrics returns backend counting its lookups in requests, labeled by tenant and result: "hit", "miss" or "error"
+func WithMetrics(backend Backend, requests *metrics.Counter) Backend {
+	return &instrumented{Backend: backend, requests: requests}
+}
+
+func (c *instrumented) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok, err := c.Backend.Get(ctx, key)
+	switch {
+	case err != nil:
+		c.requests.Inc(tenancy.ID(ctx), "error")
+	case ok:
+		c.requests.Inc(tenancy.ID(ctx), "hit")
+	default:
+		c.requests.Inc(tenancy.ID(ctx), "miss")
+	}
+	return value, ok, err
+}
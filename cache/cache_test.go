@@ -0,0 +1,143 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/cache"
+	"github.com/volume/service/user-flight-tracking/metrics"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+func TestCache_Key(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  [][]string
+		equal bool
+	}{
+		{
+			name:  "should_ignore_the_order_of_the_legs",
+			a:     [][]string{{"SFO", "ATL"}, {"ATL", "EWR"}},
+			b:     [][]string{{"ATL", "EWR"}, {"SFO", "ATL"}},
+			equal: true,
+		},
+		{
+			name: "should_keep_the_direction_of_the_legs",
+			a:    [][]string{{"SFO", "ATL"}},
+			b:    [][]string{{"ATL", "SFO"}},
+		},
+		{
+			name: "should_keep_repeated_legs",
+			a:    [][]string{{"SFO", "ATL"}},
+			b:    [][]string{{"SFO", "ATL"}, {"SFO", "ATL"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.equal, cache.Key(tt.a) == cache.Key(tt.b))
+		})
+	}
+}
+
+func TestCache_NewLRU(t *testing.T) {
+	tests := []struct {
+		name      string
+		capacity  int
+		ttl       time.Duration
+		wantError error
+	}{
+		{name: "should_return_success", capacity: 1, ttl: time.Second},
+		{name: "should_return_error_when_the_capacity_is_not_positive", ttl: time.Second, wantError: errors.New("capacity")},
+		{name: "should_return_error_when_the_ttl_is_not_positive", capacity: 1, wantError: errors.New("ttl")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cache.NewLRU(tt.capacity, tt.ttl)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCache_LRU(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should_evict_the_least_recently_used_value", func(t *testing.T) {
+		lru, err := cache.NewLRU(2, time.Minute)
+		require.NoError(t, err)
+
+		require.NoError(t, lru.Set(ctx, "a", []byte("1")))
+		require.NoError(t, lru.Set(ctx, "b", []byte("2")))
+		_, _, _ = lru.Get(ctx, "a")
+		require.NoError(t, lru.Set(ctx, "c", []byte("3")))
+
+		_, ok, _ := lru.Get(ctx, "b")
+		assert.Assert(t, !ok, "b should have been evicted")
+		value, ok, _ := lru.Get(ctx, "a")
+		assert.Assert(t, ok)
+		assert.Equal(t, "1", string(value))
+		assert.Equal(t, 2, lru.Len())
+	})
+
+	t.Run("should_expire_values_after_the_ttl", func(t *testing.T) {
+		now := time.Date(2023, 6, 13, 0, 0, 0, 0, time.UTC)
+		lru, err := cache.NewLRU(2, time.Minute)
+		require.NoError(t, err)
+		lru.Now = func() time.Time { return now }
+
+		require.NoError(t, lru.Set(ctx, "a", []byte("1")))
+		now = now.Add(59 * time.Second)
+		_, ok, _ := lru.Get(ctx, "a")
+		assert.Assert(t, ok)
+
+		now = now.Add(time.Second)
+		_, ok, _ = lru.Get(ctx, "a")
+		assert.Assert(t, !ok, "a should have expired")
+		assert.Equal(t, 0, lru.Len())
+	})
+
+	t.Run("should_delete_values", func(t *testing.T) {
+		lru, err := cache.NewLRU(2, time.Minute)
+		require.NoError(t, err)
+
+		require.NoError(t, lru.Set(ctx, "a", []byte("1")))
+		require.NoError(t, lru.Delete(ctx, "a"))
+		require.NoError(t, lru.Delete(ctx, "b"))
+
+		_, ok, _ := lru.Get(ctx, "a")
+		assert.Assert(t, !ok, "a should have been deleted")
+		assert.Equal(t, 0, lru.Len())
+	})
+}
+
+func TestCache_WithMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	lru, err := cache.NewLRU(2, time.Minute)
+	require.NoError(t, err)
+	requests := metrics.NewRegistry().Counter("requests_total", "Lookups.", "tenant", "result")
+	backend := cache.WithMetrics(lru, requests)
+
+	_, _, _ = backend.Get(ctx, "a")
+	require.NoError(t, backend.Set(ctx, "a", []byte("1")))
+	_, _, _ = backend.Get(ctx, "a")
+	_, _, _ = backend.Get(ctx, "a")
+
+	_, _, _ = backend.Get(tenancy.NewContext(ctx, tenancy.Tenant{ID: "acme"}), "a")
+
+	assert.Equal(t, uint64(1), requests.Value("", "miss"))
+	assert.Equal(t, uint64(2), requests.Value("", "hit"))
+	assert.Equal(t, uint64(0), requests.Value("", "error"))
+	assert.Equal(t, uint64(1), requests.Value("acme", "hit"))
+}
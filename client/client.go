@@ -0,0 +1,395 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/idempotency"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/tracing"
+)
+
+// Default values of the Config
+const (
+	DefaultTimeout    = 30 * time.Second
+	DefaultMaxRetries = 3
+	DefaultMinBackoff = 200 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+// maxErrorBody limits the bytes of an error response kept in APIError.Message
+const maxErrorBody = 1024
+
+// Config holds the settings of a Client
+type Config struct {
+	// BaseURL is the address of the service, e.g. "http://localhost:8080"
+	BaseURL string
+	// HTTPClient performs the requests, defaults to a client with Timeout
+	HTTPClient *http.Client
+	// Timeout limits every attempt, defaults to DefaultTimeout
+	Timeout time.Duration
+	// MaxRetries is the number of retries of temporary failures, negative disables them
+	MaxRetries int
+	// MinBackoff and MaxBackoff bound the exponential wait between retries
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// APIKey is sent in the X-API-Key header when set
+	APIKey string
+	// BearerToken is sent in the Authorization header when set
+	BearerToken string
+	// UserAgent is sent in the User-Agent header when set
+	UserAgent string
+}
+
+// RoutesQuery holds the parameters of the route suggestions
+type RoutesQuery struct {
+	From, To string
+	// By ranks the routes, "hops" or "distance", the service ranks by hops when empty
+	By string
+	// K is the number of routes, the service default when 0
+	K int
+	// MaxStops bounds the connections of the routes, the service default when nil
+	MaxStops *int
+	// Exclude holds the airports the routes don't pass through
+	Exclude []string
+}
+
+// values returns the query parameters of the suggestions
+func (q RoutesQuery) values() url.Values {
+	values := url.Values{"from": {q.From}, "to": {q.To}}
+	if q.By != "" {
+		values.Set("by", q.By)
+	}
+	if q.K != 0 {
+		values.Set("k", strconv.Itoa(q.K))
+	}
+	if q.MaxStops != nil {
+		values.Set("maxStops", strconv.Itoa(*q.MaxStops))
+	}
+	if len(q.Exclude) > 0 {
+		values.Set("exclude", strings.Join(q.Exclude, ","))
+	}
+	return values
+}
+
+// Client calls the flight tracking API
+type Client struct {
+	baseURL *url.URL
+	cfg     Config
+	http    *http.Client
+}
+
+// New returns a new instance of Client
+func New(cfg Config) (*Client, error) {
+	base, err := url.Parse(strings.TrimSuffix(cfg.BaseURL, "/"))
+	switch {
+	case cfg.BaseURL == "":
+		return nil, errors.New("baseURL")
+	case err != nil || base.Scheme == "" || base.Host == "":
+		return nil, fmt.Errorf("invalid baseURL %q", cfg.BaseURL)
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.MinBackoff == 0 {
+		cfg.MinBackoff = DefaultMinBackoff
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	return &Client{
+		baseURL: base,
+		cfg:     cfg,
+		http:    httpClient,
+	}, nil
+}
+
+// Calculate reconstructs the flight path of the request, calling POST /calculate
+func (c *Client) Calculate(ctx context.Context, req models.PathRequest) (models.PathResponse, error) {
+	var resp models.PathResponse
+	if err := c.do(ctx, http.MethodPost, "/calculate", req, &resp); err != nil {
+		return models.PathResponse{}, err
+	}
+	return resp, nil
+}
+
+// Analyze returns the legs of the request that couldn't physically happen, calling POST /analyze
+func (c *Client) Analyze(ctx context.Context, req models.PathRequest) (models.AnomalyReport, error) {
+	var resp models.AnomalyReport
+	if err := c.do(ctx, http.MethodPost, "/analyze", req, &resp); err != nil {
+		return models.AnomalyReport{}, err
+	}
+	return resp, nil
+}
+
+// SuggestRoutes returns the shortest routes of the known network between two airports, calling GET /routes
+func (c *Client) SuggestRoutes(ctx context.Context, query RoutesQuery) (models.RouteSuggestions, error) {
+	var resp models.RouteSuggestions
+	if err := c.do(ctx, http.MethodGet, "/routes?"+query.values().Encode(), nil, &resp); err != nil {
+		return models.RouteSuggestions{}, err
+	}
+	return resp, nil
+}
+
+// Complete joins the segments of the itinerary of the request with the legs of the known network and returns its
+// path, calling POST /complete
+func (c *Client) Complete(ctx context.Context, req models.PathRequest) (models.PathResponse, error) {
+	var resp models.PathResponse
+	if err := c.do(ctx, http.MethodPost, "/complete", req, &resp); err != nil {
+		return models.PathResponse{}, err
+	}
+	return resp, nil
+}
+
+// Reconcile compares the legs of a traveler reported by several sources, calling POST /reconcile
+func (c *Client) Reconcile(ctx context.Context, req models.ReconcileRequest) (models.Reconciliation, error) {
+	var resp models.Reconciliation
+	if err := c.do(ctx, http.MethodPost, "/reconcile", req, &resp); err != nil {
+		return models.Reconciliation{}, err
+	}
+	return resp, nil
+}
+
+// Diff compares the paths of the old and new versions of an itinerary, calling POST /diff
+func (c *Client) Diff(ctx context.Context, req models.DiffRequest) (models.ItineraryDiff, error) {
+	var resp models.ItineraryDiff
+	if err := c.do(ctx, http.MethodPost, "/diff", req, &resp); err != nil {
+		return models.ItineraryDiff{}, err
+	}
+	return resp, nil
+}
+
+// CreateJob enqueues the calculation of the flight path of the request, calling POST /jobs
+func (c *Client) CreateJob(ctx context.Context, req models.PathRequest) (models.JobResponse, error) {
+	var resp models.JobResponse
+	if err := c.do(ctx, http.MethodPost, "/jobs", req, &resp); err != nil {
+		return models.JobResponse{}, err
+	}
+	return resp, nil
+}
+
+// GetJob returns the status and progress of a job, calling GET /jobs/{id}
+func (c *Client) GetJob(ctx context.Context, id string) (models.JobResponse, error) {
+	var resp models.JobResponse
+	if err := c.do(ctx, http.MethodGet, "/jobs/"+url.PathEscape(id), nil, &resp); err != nil {
+		return models.JobResponse{}, err
+	}
+	return resp, nil
+}
+
+// GetJobResult returns the flight path of a succeeded job, calling GET /jobs/{id}/result.
+// The error matches ErrConflict while the job is not finished, and ErrNotFound when it failed
+func (c *Client) GetJobResult(ctx context.Context, id string) (models.PathResponse, error) {
+	var resp models.PathResponse
+	if err := c.do(ctx, http.MethodGet, "/jobs/"+url.PathEscape(id)+"/result", nil, &resp); err != nil {
+		return models.PathResponse{}, err
+	}
+	return resp, nil
+}
+
+// DeleteJob cancels a pending job and discards it with its result, calling DELETE /jobs/{id}
+func (c *Client) DeleteJob(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/jobs/"+url.PathEscape(id), nil, nil)
+}
+
+// CreateWebhook subscribes a URL to the events of the calculations, calling POST /webhooks
+func (c *Client) CreateWebhook(ctx context.Context, req models.WebhookSubscriptionRequest) (models.WebhookSubscriptionResponse, error) {
+	var resp models.WebhookSubscriptionResponse
+	if err := c.do(ctx, http.MethodPost, "/webhooks", req, &resp); err != nil {
+		return models.WebhookSubscriptionResponse{}, err
+	}
+	return resp, nil
+}
+
+// ListWebhooks returns the subscriptions of the caller, calling GET /webhooks
+func (c *Client) ListWebhooks(ctx context.Context) ([]models.WebhookSubscriptionResponse, error) {
+	var resp []models.WebhookSubscriptionResponse
+	if err := c.do(ctx, http.MethodGet, "/webhooks", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeleteWebhook removes a subscription of the caller, calling DELETE /webhooks/{id}
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/webhooks/"+url.PathEscape(id), nil, nil)
+}
+
+// ListDeadLetters returns the deliveries to the subscriptions of the caller that were abandoned, calling
+// GET /webhooks/dead-letters
+func (c *Client) ListDeadLetters(ctx context.Context) ([]models.DeadLetterResponse, error) {
+	var resp []models.DeadLetterResponse
+	if err := c.do(ctx, http.MethodGet, "/webhooks/dead-letters", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// OpenAPI returns the OpenAPI document of the service, calling GET /openapi.json
+func (c *Client) OpenAPI(ctx context.Context) (json.RawMessage, error) {
+	var doc json.RawMessage
+	if err := c.do(ctx, http.MethodGet, "/openapi.json", nil, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// do sends the request, retrying temporary failures, and decodes the JSON response into out, unless it is nil.
+// Requests other than GET share an Idempotency-Key across their retries, so that they are processed once.
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+	}
+
+	var idempotencyKey string
+	if method != http.MethodGet {
+		idempotencyKey = newIdempotencyKey()
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = c.attempt(ctx, method, path, idempotencyKey, body, out)
+		if err == nil || attempt >= c.cfg.MaxRetries || !retryable(err) {
+			return err
+		}
+
+		wait := c.backoff(attempt)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > wait {
+			wait = apiErr.RetryAfter
+		}
+		// the service asked to wait longer than the caller accepts
+		if wait > c.cfg.MaxBackoff {
+			return err
+		}
+		if sleepErr := sleep(ctx, wait); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, method, path, idempotencyKey string, body []byte, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL.String()+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.cfg.APIKey != "" {
+		req.Header.Set(auth.APIKeyHeader, c.cfg.APIKey)
+	}
+	if c.cfg.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.BearerToken)
+	}
+	if c.cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", c.cfg.UserAgent)
+	}
+	if idempotencyKey != "" {
+		req.Header.Set(idempotency.KeyHeader, idempotencyKey)
+	}
+	tracing.Inject(ctx, req.Header)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(message)),
+			RequestID:  resp.Header.Get("X-Request-ID"),
+		}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+
+	return nil
+}
+
+// backoff returns the exponential wait before the retry following attempt, with jitter
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.cfg.MinBackoff << attempt
+	if wait <= 0 || wait > c.cfg.MaxBackoff {
+		wait = c.cfg.MaxBackoff
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// retryable reports whether the error is a temporary failure
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.temporary()
+	}
+
+	// network errors and timeouts of a single attempt, unless the caller gave up
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func newIdempotencyKey() string {
+	var key [16]byte
+	_, _ = cryptorand.Read(key[:])
+	return hex.EncodeToString(key[:])
+}
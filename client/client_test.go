@@ -0,0 +1,411 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/api"
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/client"
+	"github.com/volume/service/user-flight-tracking/config"
+	"github.com/volume/service/user-flight-tracking/idempotency"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+const apiKey = "secret"
+
+func newServer(t *testing.T, cfg config.Config) *httptest.Server {
+	t.Helper()
+
+	cfg.Auth.APIKeys = []config.APIKey{{ID: "client-test", Hash: auth.HashAPIKey(apiKey)}}
+	handler, shutdown, err := api.Routes(cfg)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(func() {
+		server.Close()
+		_ = shutdown(context.Background())
+	})
+	return server
+}
+
+func newClient(t *testing.T, baseURL string) *client.Client {
+	t.Helper()
+
+	c, err := client.New(client.Config{
+		BaseURL:    baseURL,
+		APIKey:     apiKey,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func TestClient_New(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       client.Config
+		wantError error
+	}{
+		{
+			name:      "should_return_success",
+			cfg:       client.Config{BaseURL: "http://localhost:8080"},
+			wantError: nil,
+		},
+		{
+			name:      "should_return_error_when_the_base_url_is_empty",
+			cfg:       client.Config{},
+			wantError: errors.New("baseURL"),
+		},
+		{
+			name:      "should_return_error_when_the_base_url_is_invalid",
+			cfg:       client.Config{BaseURL: "localhost"},
+			wantError: errors.New(`invalid baseURL "localhost"`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.New(tt.cfg)
+			if err != nil {
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+			}
+		})
+	}
+}
+
+func TestClient_Calculate(t *testing.T) {
+	server := newServer(t, config.Config{})
+	c := newClient(t, server.URL)
+
+	t.Run("should_return_path", func(t *testing.T) {
+		resp, err := c.Calculate(context.Background(), models.PathRequest{
+			Flights: [][]string{{"IND", "EWR"}, {"SFO", "ATL"}, {"GSO", "IND"}, {"ATL", "GSO"}},
+		})
+
+		require.NoError(t, err)
+		assert.DeepEqual(t, models.PathResponse{
+			Start: "SFO",
+			End:   "EWR",
+			Path:  []string{"SFO", "ATL", "GSO", "IND", "EWR"},
+			Legs: []models.Leg{
+				{Origin: "SFO", Destination: "ATL"},
+				{Origin: "ATL", Destination: "GSO"},
+				{Origin: "GSO", Destination: "IND"},
+				{Origin: "IND", Destination: "EWR"},
+			},
+		}, resp)
+	})
+
+	t.Run("should_return_bad_request_error", func(t *testing.T) {
+		_, err := c.Calculate(context.Background(), models.PathRequest{Flights: [][]string{{"IND"}}})
+
+		assert.Assert(t, errors.Is(err, client.ErrBadRequest))
+		var apiErr *client.APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		assert.Assert(t, apiErr.RequestID != "")
+	})
+
+	t.Run("should_return_not_found_error", func(t *testing.T) {
+		_, err := c.Calculate(context.Background(), models.PathRequest{
+			Flights: [][]string{{"IND", "SFO"}, {"SFO", "IND"}},
+		})
+
+		assert.Assert(t, errors.Is(err, client.ErrNotFound))
+	})
+
+	t.Run("should_return_unauthorized_error", func(t *testing.T) {
+		anonymous, err := client.New(client.Config{BaseURL: server.URL})
+		require.NoError(t, err)
+
+		_, err = anonymous.Calculate(context.Background(), models.PathRequest{Flights: [][]string{{"SFO", "ATL"}}})
+
+		assert.Assert(t, errors.Is(err, client.ErrUnauthorized))
+	})
+}
+
+func TestClient_Retries(t *testing.T) {
+	server := newServer(t, config.Config{})
+
+	t.Run("should_retry_server_errors", func(t *testing.T) {
+		var calls int32
+		flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			server.Config.Handler.ServeHTTP(w, r)
+		}))
+		defer flaky.Close()
+
+		resp, err := newClient(t, flaky.URL).Calculate(context.Background(), models.PathRequest{
+			Flights: [][]string{{"SFO", "ATL"}},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "SFO", resp.Start)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should_send_the_same_idempotency_key_on_retries", func(t *testing.T) {
+		var keys []string
+		flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys = append(keys, r.Header.Get(idempotency.KeyHeader))
+			if len(keys) < 2 {
+				http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			server.Config.Handler.ServeHTTP(w, r)
+		}))
+		defer flaky.Close()
+
+		c := newClient(t, flaky.URL)
+		_, err := c.Calculate(context.Background(), models.PathRequest{Flights: [][]string{{"SFO", "ATL"}}})
+		require.NoError(t, err)
+		_, err = c.Calculate(context.Background(), models.PathRequest{Flights: [][]string{{"SFO", "ATL"}}})
+		require.NoError(t, err)
+
+		require.Equal(t, 3, len(keys))
+		assert.Assert(t, keys[0] != "")
+		assert.Equal(t, keys[0], keys[1])
+		assert.Assert(t, keys[1] != keys[2], "every call must use its own key")
+	})
+
+	t.Run("should_give_up_when_retry_after_exceeds_the_backoff", func(t *testing.T) {
+		limited := newServer(t, config.Config{RateLimit: config.RateLimit{
+			Routes: map[string]config.Limit{"/calculate": {RequestsPerSecond: 0.01, Burst: 1}},
+		}})
+		c := newClient(t, limited.URL)
+		req := models.PathRequest{Flights: [][]string{{"SFO", "ATL"}}}
+
+		_, err := c.Calculate(context.Background(), req)
+		require.NoError(t, err)
+		_, err = c.Calculate(context.Background(), req)
+
+		assert.Assert(t, errors.Is(err, client.ErrTooManyRequests))
+		var apiErr *client.APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, 100*time.Second, apiErr.RetryAfter)
+	})
+
+	t.Run("should_not_retry_client_errors", func(t *testing.T) {
+		var calls int32
+		counting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			server.Config.Handler.ServeHTTP(w, r)
+		}))
+		defer counting.Close()
+
+		_, err := newClient(t, counting.URL).Calculate(context.Background(), models.PathRequest{})
+
+		assert.Assert(t, errors.Is(err, client.ErrBadRequest))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestClient_OpenAPI(t *testing.T) {
+	server := newServer(t, config.Config{})
+
+	doc, err := newClient(t, server.URL).OpenAPI(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, string(bytes.TrimSpace(api.OpenAPISpec())), string(doc))
+}
+
+func TestClient_Analyze(t *testing.T) {
+	server := newServer(t, config.Config{})
+	c := newClient(t, server.URL)
+
+	report, err := c.Analyze(context.Background(), models.PathRequest{Flights: [][]string{{"SFO", "ATL"}, {"SFO", "ATL"}}})
+
+	require.NoError(t, err)
+	require.Equal(t, 1, len(report.Reasons))
+	assert.Equal(t, "duplicate", report.Reasons[0].Kind)
+	assert.DeepEqual(t, []int{0, 1}, report.Reasons[0].Legs)
+}
+
+func TestClient_SuggestRoutes(t *testing.T) {
+	routesFile := filepath.Join(t.TempDir(), "routes.json")
+	require.NoError(t, os.WriteFile(routesFile, []byte(`{"routes": [["SFO", "EWR"], ["SFO", "DEN"], ["DEN", "EWR"]]}`), 0o600))
+	server := newServer(t, config.Config{Network: config.Network{RoutesFile: routesFile}})
+	c := newClient(t, server.URL)
+
+	t.Run("should_return_the_routes", func(t *testing.T) {
+		suggestions, err := c.SuggestRoutes(context.Background(), client.RoutesQuery{From: "SFO", To: "EWR"})
+
+		require.NoError(t, err)
+		require.Equal(t, 2, len(suggestions.Routes))
+		assert.DeepEqual(t, []string{"SFO", "EWR"}, suggestions.Routes[0].Path)
+		assert.DeepEqual(t, []string{"SFO", "DEN", "EWR"}, suggestions.Routes[1].Path)
+	})
+
+	t.Run("should_send_the_parameters", func(t *testing.T) {
+		maxStops := 0
+		suggestions, err := c.SuggestRoutes(context.Background(), client.RoutesQuery{From: "SFO", To: "EWR", K: 1, MaxStops: &maxStops})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(suggestions.Routes))
+		assert.Equal(t, 0, suggestions.Routes[0].Stops)
+
+		suggestions, err = c.SuggestRoutes(context.Background(), client.RoutesQuery{From: "SFO", To: "EWR", Exclude: []string{"DEN"}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, len(suggestions.Routes))
+	})
+
+	t.Run("should_return_bad_request_error", func(t *testing.T) {
+		_, err := c.SuggestRoutes(context.Background(), client.RoutesQuery{From: "SFO", To: "EWR", By: "price"})
+
+		assert.Assert(t, errors.Is(err, client.ErrBadRequest))
+	})
+}
+
+func TestClient_Complete(t *testing.T) {
+	routesFile := filepath.Join(t.TempDir(), "routes.json")
+	require.NoError(t, os.WriteFile(routesFile, []byte(`{"routes": [["ATL", "GSO"]]}`), 0o600))
+	server := newServer(t, config.Config{Network: config.Network{RoutesFile: routesFile}})
+	c := newClient(t, server.URL)
+
+	t.Run("should_return_the_completed_path", func(t *testing.T) {
+		resp, err := c.Complete(context.Background(), models.PathRequest{Flights: [][]string{{"SFO", "ATL"}, {"GSO", "EWR"}}})
+
+		require.NoError(t, err)
+		assert.DeepEqual(t, []string{"SFO", "ATL", "GSO", "EWR"}, resp.Path)
+		assert.Assert(t, resp.Legs[1].Inferred)
+	})
+
+	t.Run("should_return_not_found_error", func(t *testing.T) {
+		_, err := c.Complete(context.Background(), models.PathRequest{Flights: [][]string{{"SFO", "ATL"}, {"MIA", "EWR"}}})
+
+		assert.Assert(t, errors.Is(err, client.ErrNotFound))
+	})
+
+	t.Run("should_return_bad_request_error", func(t *testing.T) {
+		_, err := c.Complete(context.Background(), models.PathRequest{Flights: [][]string{{"SFO", "ATL"}, {"SFO", "EWR"}}})
+
+		assert.Assert(t, errors.Is(err, client.ErrBadRequest))
+	})
+}
+
+func TestClient_Reconcile(t *testing.T) {
+	server := newServer(t, config.Config{})
+	c := newClient(t, server.URL)
+
+	t.Run("should_return_the_reconciliation", func(t *testing.T) {
+		resp, err := c.Reconcile(context.Background(), models.ReconcileRequest{Sources: []models.LegSource{
+			{Name: "booking", Legs: []models.Leg{{Origin: "SFO", Destination: "ATL"}, {Origin: "ATL", Destination: "EWR"}}},
+			{Name: "airline", Legs: []models.Leg{{Origin: "SFO", Destination: "ATL"}}},
+		}})
+
+		require.NoError(t, err)
+		require.Equal(t, 2, len(resp.Legs))
+		assert.Equal(t, "matching", resp.Legs[0].Status)
+		assert.Equal(t, "source_only", resp.Legs[1].Status)
+		require.NotNil(t, resp.Path)
+		assert.DeepEqual(t, []string{"SFO", "ATL", "EWR"}, resp.Path.Path)
+		assert.DeepEqual(t, []int{1}, resp.Sources[1].Missing)
+	})
+
+	t.Run("should_return_bad_request_error", func(t *testing.T) {
+		_, err := c.Reconcile(context.Background(), models.ReconcileRequest{Sources: []models.LegSource{
+			{Name: "booking", Legs: []models.Leg{{Origin: "SFO", Destination: "ATL"}}},
+		}})
+
+		assert.Assert(t, errors.Is(err, client.ErrBadRequest))
+	})
+}
+
+func TestClient_Diff(t *testing.T) {
+	server := newServer(t, config.Config{})
+	c := newClient(t, server.URL)
+
+	t.Run("should_return_the_changes", func(t *testing.T) {
+		resp, err := c.Diff(context.Background(), models.DiffRequest{
+			Old: models.PathRequest{Flights: [][]string{{"SFO", "ATL"}, {"ATL", "EWR"}}},
+			New: models.PathRequest{Flights: [][]string{{"SFO", "DEN"}, {"DEN", "EWR"}}},
+		})
+
+		require.NoError(t, err)
+		assert.Assert(t, resp.Start == nil)
+		assert.Equal(t, 2, len(resp.Added))
+		assert.Equal(t, 2, len(resp.Removed))
+	})
+
+	t.Run("should_return_bad_request_error", func(t *testing.T) {
+		_, err := c.Diff(context.Background(), models.DiffRequest{
+			Old: models.PathRequest{Flights: [][]string{{"SFO", "ATL"}}},
+		})
+
+		assert.Assert(t, errors.Is(err, client.ErrBadRequest))
+	})
+}
+
+func TestClient_Jobs(t *testing.T) {
+	server := newServer(t, config.Config{})
+	c := newClient(t, server.URL)
+
+	t.Run("should_return_the_result_of_the_job", func(t *testing.T) {
+		job, err := c.CreateJob(context.Background(), models.PathRequest{Flights: [][]string{{"SFO", "ATL"}, {"ATL", "EWR"}}})
+		require.NoError(t, err)
+		assert.Assert(t, job.ID != "")
+
+		require.Eventually(t, func() bool {
+			job, err = c.GetJob(context.Background(), job.ID)
+			return err == nil && job.Status == "succeeded"
+		}, 5*time.Second, 10*time.Millisecond)
+
+		resp, err := c.GetJobResult(context.Background(), job.ID)
+		require.NoError(t, err)
+		assert.DeepEqual(t, []string{"SFO", "ATL", "EWR"}, resp.Path)
+
+		require.NoError(t, c.DeleteJob(context.Background(), job.ID))
+		_, err = c.GetJob(context.Background(), job.ID)
+		assert.Assert(t, errors.Is(err, client.ErrNotFound))
+	})
+
+	t.Run("should_return_not_found_error_when_the_job_is_unknown", func(t *testing.T) {
+		_, err := c.GetJobResult(context.Background(), "unknown")
+
+		assert.Assert(t, errors.Is(err, client.ErrNotFound))
+	})
+}
+
+func TestClient_Webhooks(t *testing.T) {
+	server := newServer(t, config.Config{})
+	c := newClient(t, server.URL)
+
+	subscription, err := c.CreateWebhook(context.Background(), models.WebhookSubscriptionRequest{
+		URL:    "https://example.com/hooks",
+		Events: []string{models.WebhookEventPathComputed},
+		Secret: "0123456789abcdef",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/hooks", subscription.URL)
+
+	subscriptions, err := c.ListWebhooks(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(subscriptions))
+	assert.Equal(t, subscription.ID, subscriptions[0].ID)
+
+	deadLetters, err := c.ListDeadLetters(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(deadLetters))
+
+	require.NoError(t, c.DeleteWebhook(context.Background(), subscription.ID))
+	err = c.DeleteWebhook(context.Background(), subscription.ID)
+	assert.Assert(t, errors.Is(err, client.ErrNotFound))
+
+	_, err = c.CreateWebhook(context.Background(), models.WebhookSubscriptionRequest{URL: "example.com"})
+	assert.Assert(t, errors.Is(err, client.ErrBadRequest))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/volume/service/user-flight-tracking/gateways"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// errEmptyBatch is returned when a batch input has no requests
+var errEmptyBatch = errors.New("no requests found")
+
+// runBatch reconstructs every NDJSON line as a separate request, the exit code is the worst of all the lines
+func runBatch(opts options, stdin io.Reader, stderr io.Writer, gateway gateways.FlightTracker, w *writer) int {
+	code := exitOK
+	requests := 0
+
+	err := forEachInput(opts, stdin, func(name, format string, r io.Reader) error {
+		if format != formatNDJSON {
+			return fmt.Errorf("%s: batch mode requires ndjson input", name)
+		}
+
+		return forEachLine(r, func(number int, line []byte) error {
+			requests++
+
+			var res result
+			flights, err := decodeRequest(line)
+			if err == nil {
+				res.Response, err = reconstruct(gateway, models.PathRequest{Flights: flights})
+			}
+			res.Line = number
+			res.Err = err
+
+			if err != nil && exitCode(err) > code {
+				code = exitCode(err)
+			}
+			return w.write(res)
+		})
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return exitUsage
+	}
+
+	if requests == 0 {
+		fmt.Fprintln(stderr, "error:", errEmptyBatch)
+		return exitValidation
+	}
+
+	return code
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/volume/service/user-flight-tracking/controllers/translators"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// Input formats
+const (
+	formatJSON   = "json"
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+)
+
+// readLegs reads the legs of a single request
+func readLegs(format string, r io.Reader) ([][]string, error) {
+	switch format {
+	case formatJSON:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return decodeRequest(data)
+	case formatCSV:
+		return readCSV(r)
+	case formatNDJSON:
+		return readNDJSONLegs(r)
+	default:
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+}
+
+// decodeRequest accepts either a PathRequest object or a bare list of pairs
+func decodeRequest(data []byte) ([][]string, error) {
+	data = bytes.TrimSpace(data)
+
+	if bytes.HasPrefix(data, []byte("[")) {
+		var flights [][]string
+		if err := json.Unmarshal(data, &flights); err != nil {
+			return nil, fmt.Errorf("%w: %v", errValidation, err)
+		}
+		return flights, nil
+	}
+
+	var req models.PathRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("%w: %v", errValidation, err)
+	}
+	return req.Pairs(), nil
+}
+
+// readNDJSONLegs reads one leg per line, either as a pair or as an object with origin and destination
+func readNDJSONLegs(r io.Reader) ([][]string, error) {
+	var flights [][]string
+
+	err := forEachLine(r, func(number int, line []byte) error {
+		if bytes.HasPrefix(line, []byte("[")) {
+			var pair []string
+			if err := json.Unmarshal(line, &pair); err != nil {
+				return fmt.Errorf("%w: line %d: %v", errValidation, number, err)
+			}
+			flights = append(flights, pair)
+			return nil
+		}
+
+		var leg struct {
+			Origin      string `json:"origin"`
+			Destination string `json:"destination"`
+		}
+		if err := json.Unmarshal(line, &leg); err != nil {
+			return fmt.Errorf("%w: line %d: %v", errValidation, number, err)
+		}
+		flights = append(flights, []string{leg.Origin, leg.Destination})
+		return nil
+	})
+
+	return flights, err
+}
+
+// forEachLine calls fn with every non blank line and its 1-based number
+func forEachLine(r io.Reader, fn func(number int, line []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for number := 1; scanner.Scan(); number++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := fn(number, line); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// readCSV reads one leg per row, detecting the columns like the CSV uploads of the service
+func readCSV(r io.Reader) ([][]string, error) {
+	req, err := translators.CSVToPathRequest(r)
+	if err != nil {
+		var rowErrs translators.CSVRowErrors
+		if errors.As(err, &rowErrs) {
+			return nil, fmt.Errorf("%w: %v", errValidation, err)
+		}
+		return nil, err
+	}
+
+	return req.Pairs(), nil
+}
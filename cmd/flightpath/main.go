@@ -0,0 +1,240 @@
+// Command flightpath reconstructs flight paths offline, using the same algorithm as the service.
+//
+// Usage:
+//
+//	flightpath [flags] [file ...]
+//
+// The legs are read from the files, or from stdin when none is given, and
+// printed as text, JSON or DOT. In batch mode every NDJSON line is a full
+// request and gets its own result line.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/connections"
+	"github.com/volume/service/user-flight-tracking/controllers/translators"
+	"github.com/volume/service/user-flight-tracking/duplicates"
+	"github.com/volume/service/user-flight-tracking/gateways"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/privacy"
+)
+
+// Exit codes
+const (
+	exitOK         = 0
+	exitUsage      = 1
+	exitValidation = 2
+	exitNoPath     = 3
+)
+
+var (
+	// errValidation marks inputs rejected before running the algorithm
+	errValidation = errors.New("validation error")
+	// errNoPath marks legs that do not form a single path
+	errNoPath = errors.New("no path")
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+type options struct {
+	input   string
+	output  string
+	batch   bool
+	verbose bool
+	files   []string
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	opts, err := parseFlags(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		return exitUsage
+	}
+
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+	if opts.verbose {
+		logger.SetOutput(stderr)
+		logger.SetLevel(log.DebugLevel)
+	}
+
+	connectionTimes, err := connections.NewTable(
+		connections.Times{Domestic: connections.DefaultDomestic, International: connections.DefaultInternational},
+		nil,
+		connections.DefaultTightMargin,
+	)
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return exitUsage
+	}
+
+	gateway, err := gateways.NewFlightTracker(log.NewEntry(logger).WithField("gateway", "FlightTracker"), privacy.Redactor{Redaction: privacy.RedactionPlain}, connectionTimes)
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return exitUsage
+	}
+
+	writer, err := newWriter(opts.output, stdout)
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return exitUsage
+	}
+
+	if opts.batch {
+		return runBatch(opts, stdin, stderr, gateway, writer)
+	}
+
+	var req models.PathRequest
+	err = forEachInput(opts, stdin, func(name, format string, r io.Reader) error {
+		flights, err := readLegs(format, r)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		req.Flights = append(req.Flights, flights...)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return exitCode(err)
+	}
+
+	resp, err := reconstruct(gateway, req)
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return exitCode(err)
+	}
+
+	if err := writer.write(result{Response: resp}); err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return exitUsage
+	}
+
+	return exitOK
+}
+
+func parseFlags(args []string, stderr io.Writer) (options, error) {
+	var opts options
+
+	fs := flag.NewFlagSet("flightpath", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.input, "input", "", "input format: json, csv or ndjson (default: from the file extension, json for stdin)")
+	fs.StringVar(&opts.output, "output", "text", "output format: text, json or dot")
+	fs.BoolVar(&opts.batch, "batch", false, "treat every NDJSON line as a separate request and print one result per line")
+	fs.BoolVar(&opts.verbose, "v", false, "log the algorithm steps to stderr")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: flightpath [flags] [file ...]")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nexit codes: %d ok, %d usage or I/O error, %d invalid legs, %d legs do not form a single path\n",
+			exitOK, exitUsage, exitValidation, exitNoPath)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+	opts.files = fs.Args()
+
+	switch opts.input {
+	case "", formatJSON, formatCSV, formatNDJSON:
+	default:
+		err := fmt.Errorf("unknown input format %q", opts.input)
+		fmt.Fprintln(stderr, "error:", err)
+		return options{}, err
+	}
+	if opts.batch && opts.input != "" && opts.input != formatNDJSON {
+		err := errors.New("batch mode requires ndjson input")
+		fmt.Fprintln(stderr, "error:", err)
+		return options{}, err
+	}
+
+	return opts, nil
+}
+
+// forEachInput calls fn with every input file, or with stdin when there are none
+func forEachInput(opts options, stdin io.Reader, fn func(name, format string, r io.Reader) error) error {
+	if len(opts.files) == 0 {
+		format := opts.input
+		if format == "" {
+			format = formatJSON
+			if opts.batch {
+				format = formatNDJSON
+			}
+		}
+		return fn("stdin", format, stdin)
+	}
+
+	for _, name := range opts.files {
+		format := opts.input
+		if format == "" {
+			format = formatFromExtension(name)
+		}
+
+		if err := readFile(name, format, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readFile(name, format string, fn func(name, format string, r io.Reader) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return fn(name, format, f)
+}
+
+func formatFromExtension(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return formatCSV
+	case ".ndjson", ".jsonl":
+		return formatNDJSON
+	default:
+		return formatJSON
+	}
+}
+
+// reconstruct validates the request and runs the gateway algorithm
+func reconstruct(gateway gateways.FlightTracker, req models.PathRequest) (models.PathResponse, error) {
+	if err := req.Validate(); err != nil {
+		return models.PathResponse{}, fmt.Errorf("%w: %v", errValidation, err)
+	}
+
+	// the repeated flights are merged, as the service does by default; dedupe never rejects an itinerary
+	req, found, _ := duplicates.DefaultPolicy.Apply(req)
+	path, err := gateway.GetFlightsPath(context.Background(), req)
+	if err != nil {
+		return models.PathResponse{}, fmt.Errorf("%w: %v", errNoPath, err)
+	}
+	path.Duplicates = found
+
+	return translators.PathDTOtoModel(path), nil
+}
+
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, errValidation):
+		return exitValidation
+	case errors.Is(err, errNoPath):
+		return exitNoPath
+	default:
+		return exitUsage
+	}
+}
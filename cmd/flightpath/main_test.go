@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestFlightpath_Run(t *testing.T) {
+	dir := t.TempDir()
+	csvFile := filepath.Join(dir, "legs.csv")
+	require.NoError(t, os.WriteFile(csvFile, []byte("user,from,to\nu1,IND,EWR\nu1,SFO,ATL\nu1,GSO,IND\nu1,ATL,GSO\n"), 0o600))
+
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantCode   int
+		wantStdout string
+	}{
+		{
+			name:       "should_print_the_path_from_json_stdin",
+			stdin:      `{"flights": [["IND", "EWR"], ["SFO", "ATL"], ["GSO", "IND"], ["ATL", "GSO"]]}`,
+			wantCode:   exitOK,
+			wantStdout: "SFO -> ATL -> GSO -> IND -> EWR\n",
+		},
+		{
+			name:     "should_print_json_from_a_csv_file_with_header",
+			args:     []string{"-output", "json", csvFile},
+			wantCode: exitOK,
+			wantStdout: `{"start":"SFO","end":"EWR","path":["SFO","ATL","GSO","IND","EWR"],"legs":[` +
+				`{"origin":"SFO","destination":"ATL"},{"origin":"ATL","destination":"GSO"},` +
+				`{"origin":"GSO","destination":"IND"},{"origin":"IND","destination":"EWR"}]}` + "\n",
+		},
+		{
+			name:       "should_print_the_path_from_a_csv_with_departure_and_arrival_headers",
+			args:       []string{"-input", "csv"},
+			stdin:      "Arrival,Departure\nATL,SFO\nEWR,ATL\n",
+			wantCode:   exitOK,
+			wantStdout: "SFO -> ATL -> EWR\n",
+		},
+		{
+			name:       "should_print_dot_from_ndjson_legs",
+			args:       []string{"-input", "ndjson", "-output", "dot"},
+			stdin:      "[\"SFO\", \"ATL\"]\n{\"origin\": \"ATL\", \"destination\": \"EWR\"}\n",
+			wantCode:   exitOK,
+			wantStdout: "digraph path {\n  \"SFO\" [shape=doublecircle];\n  \"EWR\" [shape=doublecircle];\n  \"SFO\" -> \"ATL\" [label=\"1\"];\n  \"ATL\" -> \"EWR\" [label=\"2\"];\n}\n",
+		},
+		{
+			name:     "should_exit_with_validation_code_when_a_leg_is_invalid",
+			stdin:    `[["SFO"]]`,
+			wantCode: exitValidation,
+		},
+		{
+			name:     "should_exit_with_validation_code_when_the_input_is_malformed",
+			stdin:    `{"flights": [`,
+			wantCode: exitValidation,
+		},
+		{
+			name:     "should_exit_with_no_path_code_when_the_legs_are_disconnected",
+			stdin:    `[["SFO", "ATL"], ["ATL", "SFO"]]`,
+			wantCode: exitNoPath,
+		},
+		{
+			name:     "should_exit_with_usage_code_when_the_file_does_not_exist",
+			args:     []string{filepath.Join(dir, "missing.json")},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "should_exit_with_usage_code_when_the_output_is_unknown",
+			args:     []string{"-output", "xml"},
+			wantCode: exitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+			assert.Equal(t, tt.wantCode, code, stderr.String())
+			if tt.wantStdout != "" {
+				assert.Equal(t, tt.wantStdout, stdout.String())
+			}
+		})
+	}
+}
+
+func TestFlightpath_RunBatch(t *testing.T) {
+	stdin := strings.Join([]string{
+		`{"flights": [["SFO", "ATL"], ["ATL", "EWR"]]}`,
+		`[["SFO", "ATL"], ["ATL", "SFO"]]`,
+		``,
+		`[["SFO"]]`,
+	}, "\n")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-batch", "-output", "json"}, strings.NewReader(stdin), &stdout, &stderr)
+
+	assert.Equal(t, exitNoPath, code)
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, `{"line":1,"start":"SFO","end":"EWR","path":["SFO","ATL","EWR"],`+
+		`"legs":[{"origin":"SFO","destination":"ATL"},{"origin":"ATL","destination":"EWR"}]}`, lines[0])
+	assert.Assert(t, strings.HasPrefix(lines[1], `{"line":2,"error":"no path: `))
+	assert.Assert(t, strings.HasSuffix(lines[1], `"kind":"no_path"}`))
+	assert.Assert(t, strings.HasPrefix(lines[2], `{"line":4,"error":"validation error: `))
+}
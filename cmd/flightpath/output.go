@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// Output formats
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputDOT  = "dot"
+)
+
+// result is the outcome of a request, Line is only set in batch mode
+type result struct {
+	Line     int
+	Response models.PathResponse
+	Err      error
+}
+
+type writer struct {
+	format string
+	out    io.Writer
+}
+
+func newWriter(format string, out io.Writer) (*writer, error) {
+	switch format {
+	case outputText, outputJSON, outputDOT:
+		return &writer{format: format, out: out}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func (w *writer) write(res result) error {
+	switch w.format {
+	case outputJSON:
+		return w.writeJSON(res)
+	case outputDOT:
+		return w.writeDOT(res)
+	default:
+		return w.writeText(res)
+	}
+}
+
+func (w *writer) writeText(res result) error {
+	var prefix string
+	if res.Line > 0 {
+		prefix = fmt.Sprintf("line %d: ", res.Line)
+	}
+
+	if res.Err != nil {
+		_, err := fmt.Fprintf(w.out, "%serror: %v\n", prefix, res.Err)
+		return err
+	}
+
+	_, err := fmt.Fprintf(w.out, "%s%s\n", prefix, strings.Join(res.Response.Path, " -> "))
+	return err
+}
+
+func (w *writer) writeJSON(res result) error {
+	if res.Line == 0 {
+		return json.NewEncoder(w.out).Encode(res.Response)
+	}
+
+	line := struct {
+		Line int `json:"line"`
+		*models.PathResponse
+		Error string `json:"error,omitempty"`
+		Kind  string `json:"kind,omitempty"`
+	}{Line: res.Line}
+	if res.Err != nil {
+		line.Error = res.Err.Error()
+		line.Kind = errorKind(res.Err)
+	} else {
+		line.PathResponse = &res.Response
+	}
+
+	return json.NewEncoder(w.out).Encode(line)
+}
+
+func (w *writer) writeDOT(res result) error {
+	name := "path"
+	if res.Line > 0 {
+		name = fmt.Sprintf("line_%d", res.Line)
+	}
+
+	if res.Err != nil {
+		_, err := fmt.Fprintf(w.out, "// %s: error: %v\n", name, res.Err)
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", name)
+	fmt.Fprintf(&b, "  %q [shape=doublecircle];\n", res.Response.Start)
+	fmt.Fprintf(&b, "  %q [shape=doublecircle];\n", res.Response.End)
+	for i := 1; i < len(res.Response.Path); i++ {
+		fmt.Fprintf(&b, "  %q -> %q [label=\"%d\"];\n", res.Response.Path[i-1], res.Response.Path[i], i)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w.out, b.String())
+	return err
+}
+
+func errorKind(err error) string {
+	switch exitCode(err) {
+	case exitValidation:
+		return "validation"
+	case exitNoPath:
+		return "no_path"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,227 @@
+package completion
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/volume/service/user-flight-tracking/graph"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/network"
+)
+
+// DefaultMaxInferredLegs is the number of legs inferred to join two segments when the configuration sets none
+const DefaultMaxInferredLegs = 2
+
+// MaxMaxInferredLegs bounds the legs inferred to join two segments, and the states of the searches joining them
+const MaxMaxInferredLegs = 5
+
+// MaxSegments bounds the segments joined, as every order of the segments is weighed
+const MaxSegments = 10
+
+// ErrInvalidItinerary is returned for the flights no network could complete: they branch, form a circuit or
+// have too many segments
+var ErrInvalidItinerary = errors.New("invalid itinerary")
+
+// Segment is a chain of connected legs of an itinerary
+type Segment struct {
+	// Airports holds the airports of the chain in travel order
+	Airports []string
+}
+
+// Start returns the first airport of the segment
+func (s Segment) Start() string {
+	return s.Airports[0]
+}
+
+// End returns the last airport of the segment
+func (s Segment) End() string {
+	return s.Airports[len(s.Airports)-1]
+}
+
+// Segments returns the chains formed by the [origin, destination] pairs, in the order their first airport appears.
+// The pairs can't branch nor form a circuit, as they couldn't be joined into a single itinerary
+func Segments(pairs [][]string) ([]Segment, error) {
+	flights := graph.FromPairs(pairs)
+	for _, airport := range flights.Airports() {
+		if len(flights.Outgoing(airport)) > 1 || len(flights.Incoming(airport)) > 1 {
+			return nil, fmt.Errorf("the flights branch at %s", airport)
+		}
+	}
+
+	var (
+		segments  []Segment
+		traversal = graph.NewTraversal(flights)
+	)
+	for _, airport := range flights.Airports() {
+		if len(flights.Incoming(airport)) > 0 {
+			continue
+		}
+
+		var segment Segment
+		for next := []graph.Airport{airport}; len(next) > 0; next = flights.Outgoing(next[0]) {
+			traversal.Visit(next[0])
+			segment.Airports = append(segment.Airports, string(next[0]))
+		}
+		segments = append(segments, segment)
+	}
+	if circular := traversal.Unvisited(); len(circular) > 0 {
+		return nil, fmt.Errorf("a circular flight was found between flights: %s", circular[0])
+	}
+
+	return segments, nil
+}
+
+// Completer joins the segments of the itineraries with routes of a known network
+type Completer struct {
+	Network *network.Network
+	// MaxInferredLegs bounds the legs inferred between two segments
+	MaxInferredLegs int
+}
+
+// NewCompleter returns a completer of the itineraries over the network
+func NewCompleter(network *network.Network, maxInferredLegs int) (*Completer, error) {
+	switch {
+	case network == nil:
+		return nil, errors.New("network")
+	case maxInferredLegs <= 0 || maxInferredLegs > MaxMaxInferredLegs:
+		return nil, fmt.Errorf("maxInferredLegs must be between 1 and %d", MaxMaxInferredLegs)
+	}
+
+	return &Completer{
+		Network:         network,
+		MaxInferredLegs: maxInferredLegs,
+	}, nil
+}
+
+// Complete returns req with the fewest network legs joining its segments into a single itinerary, marked as inferred.
+// The completed request always holds detailed legs, the flights of req are converted.
+func (c *Completer) Complete(req models.PathRequest) (models.PathRequest, error) {
+	segments, err := Segments(req.Pairs())
+	if err != nil {
+		return models.PathRequest{}, fmt.Errorf("%w: %v", ErrInvalidItinerary, err)
+	}
+	if len(segments) == 1 {
+		return req, nil
+	}
+	if len(segments) > MaxSegments {
+		return models.PathRequest{}, fmt.Errorf("%w: the flights form %d segments, at most %d can be joined", ErrInvalidItinerary, len(segments), MaxSegments)
+	}
+
+	joins := c.joins(segments)
+	order, ok := cheapestOrder(joins)
+	if !ok {
+		return models.PathRequest{}, errors.New("no known route joins the segments of the flights")
+	}
+
+	completed := req
+	completed.Flights, completed.Legs = nil, req.Itinerary()
+	if len(req.Dates) > 0 {
+		completed.Dates = append([]string(nil), req.Dates...)
+	}
+	for i := 1; i < len(order); i++ {
+		join := joins[order[i-1]][order[i]]
+		for j := 1; j < len(join); j++ {
+			completed.Legs = append(completed.Legs, models.Leg{Origin: join[j-1], Destination: join[j], Inferred: true})
+			if len(completed.Dates) > 0 {
+				completed.Dates = append(completed.Dates, "")
+			}
+		}
+	}
+
+	return completed, nil
+}
+
+// joins returns the shortest route from the end of every segment to the start of every other one, nil when none
+// is known. The routes don't pass through the airports of the itinerary, it would visit them twice
+func (c *Completer) joins(segments []Segment) [][][]string {
+	var itinerary []string
+	for _, segment := range segments {
+		itinerary = append(itinerary, segment.Airports...)
+	}
+
+	joins := make([][][]string, len(segments))
+	for i, from := range segments {
+		joins[i] = make([][]string, len(segments))
+		for j, to := range segments {
+			if i == j {
+				continue
+			}
+
+			var exclude []string
+			for _, airport := range itinerary {
+				if airport != from.End() && airport != to.Start() {
+					exclude = append(exclude, airport)
+				}
+			}
+			routes := c.Network.Suggest(network.Query{
+				From:     from.End(),
+				To:       to.Start(),
+				K:        1,
+				By:       network.ByHops,
+				MaxStops: c.MaxInferredLegs - 1,
+				Exclude:  exclude,
+			})
+			if len(routes) > 0 {
+				joins[i][j] = routes[0].Airports
+			}
+		}
+	}
+	return joins
+}
+
+// cheapestOrder returns the order of the segments inferring the fewest legs, by dynamic programming over the
+// subsets of the segments. ok is false when no order joins all of them
+func cheapestOrder(joins [][][]string) (order []int, ok bool) {
+	n := len(joins)
+	full := 1<<n - 1
+
+	// cost[set][last] is the fewest legs inferred to chain the segments of set ending with last
+	cost := make([][]int, full+1)
+	previous := make([][]int, full+1)
+	for set := range cost {
+		cost[set] = make([]int, n)
+		previous[set] = make([]int, n)
+		for last := range cost[set] {
+			cost[set][last] = math.MaxInt
+		}
+	}
+	for i := 0; i < n; i++ {
+		cost[1<<i][i] = 0
+	}
+
+	for set := 1; set <= full; set++ {
+		for last := 0; last < n; last++ {
+			if cost[set][last] == math.MaxInt {
+				continue
+			}
+			for next := 0; next < n; next++ {
+				join := joins[last][next]
+				if set&(1<<next) != 0 || join == nil {
+					continue
+				}
+				extended := set | 1<<next
+				if c := cost[set][last] + len(join) - 1; c < cost[extended][next] {
+					cost[extended][next], previous[extended][next] = c, last
+				}
+			}
+		}
+	}
+
+	last := -1
+	for i := 0; i < n; i++ {
+		if cost[full][i] != math.MaxInt && (last < 0 || cost[full][i] < cost[full][last]) {
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil, false
+	}
+
+	order = make([]int, n)
+	for set, i := full, n-1; i >= 0; i-- {
+		order[i] = last
+		set, last = set&^(1<<last), previous[set][last]
+	}
+	return order, true
+}
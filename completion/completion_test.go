@@ -0,0 +1,113 @@
+package completion_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/completion"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/network"
+)
+
+func TestCompletion_Segments(t *testing.T) {
+	tests := []struct {
+		name      string
+		pairs     [][]string
+		want      []completion.Segment
+		wantError error
+	}{
+		{
+			name:  "should_return_the_segments",
+			pairs: [][]string{{"IND", "EWR"}, {"SFO", "ATL"}, {"GSO", "IND"}},
+			want:  []completion.Segment{{Airports: []string{"SFO", "ATL"}}, {Airports: []string{"GSO", "IND", "EWR"}}},
+		},
+		{
+			name:      "should_return_error_when_the_flights_branch",
+			pairs:     [][]string{{"SFO", "ATL"}, {"SFO", "EWR"}},
+			wantError: errors.New("the flights branch at SFO"),
+		},
+		{
+			name:      "should_return_error_when_the_flights_are_circular",
+			pairs:     [][]string{{"SFO", "ATL"}, {"JFK", "BOS"}, {"BOS", "JFK"}},
+			wantError: errors.New("a circular flight was found between flights: JFK"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments, err := completion.Segments(tt.pairs)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tt.want, segments)
+		})
+	}
+}
+
+func TestCompletion_NewCompleter(t *testing.T) {
+	_, err := completion.NewCompleter(nil, 2)
+	assert.Error(t, err, "network")
+
+	_, err = completion.NewCompleter(network.New(nil), 0)
+	assert.Error(t, err, "maxInferredLegs must be between 1 and 5")
+
+	_, err = completion.NewCompleter(network.New(nil), completion.MaxMaxInferredLegs+1)
+	assert.Error(t, err, "maxInferredLegs must be between 1 and 5")
+
+	_, err = completion.NewCompleter(network.New(nil), completion.MaxMaxInferredLegs)
+	assert.NilError(t, err)
+}
+
+func TestCompletion_Complete(t *testing.T) {
+	routes := network.New([][]string{
+		{"ATL", "GSO"}, {"ATL", "ORD"}, {"ORD", "GSO"}, {"EWR", "BOS"}, {"IND", "ORD"}, {"ORD", "SFO"}, {"EWR", "DEN"}, {"DEN", "SEA"}, {"SEA", "MIA"},
+	})
+	completer, err := completion.NewCompleter(routes, 2)
+	require.NoError(t, err)
+
+	t.Run("should_join_the_segments_with_the_fewest_legs", func(t *testing.T) {
+		req, err := completer.Complete(models.PathRequest{
+			Flights: [][]string{{"GSO", "IND"}, {"SFO", "ATL"}, {"IND", "EWR"}},
+			UserID:  "u-1",
+		})
+		require.NoError(t, err)
+
+		assert.DeepEqual(t, models.PathRequest{
+			Legs: []models.Leg{
+				{Origin: "GSO", Destination: "IND"},
+				{Origin: "SFO", Destination: "ATL"},
+				{Origin: "IND", Destination: "EWR"},
+				{Origin: "ATL", Destination: "GSO", Inferred: true},
+			},
+			UserID: "u-1",
+		}, req)
+	})
+
+	t.Run("should_return_the_connected_itinerary_unchanged", func(t *testing.T) {
+		req := models.PathRequest{Legs: []models.Leg{{Origin: "SFO", Destination: "ATL", Carrier: "UA"}, {Origin: "ATL", Destination: "GSO"}}}
+
+		completed, err := completer.Complete(req)
+		require.NoError(t, err)
+		assert.DeepEqual(t, req, completed)
+	})
+
+	t.Run("should_not_pass_through_the_airports_of_the_itinerary", func(t *testing.T) {
+		// IND-ORD-SFO would join the segments, visiting ORD twice
+		_, err := completer.Complete(models.PathRequest{Flights: [][]string{{"MIA", "IND"}, {"SFO", "ORD"}}})
+
+		assert.Error(t, err, "no known route joins the segments of the flights")
+	})
+
+	t.Run("failure_response_when_the_join_needs_too_many_legs", func(t *testing.T) {
+		// EWR-DEN-SEA-MIA would need 3 legs
+		_, err := completer.Complete(models.PathRequest{Flights: [][]string{{"SFO", "EWR"}, {"MIA", "CLT"}}})
+
+		assert.Error(t, err, "no known route joins the segments of the flights")
+	})
+}
@@ -0,0 +1,234 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Config holds the service configuration
+type Config struct {
+	Log         Log         `json:"log"`
+	Auth        Auth        `json:"auth"`
+	RateLimit   RateLimit   `json:"rateLimit"`
+	Tracing     Tracing     `json:"tracing"`
+	Jobs        Jobs        `json:"jobs"`
+	Webhooks    Webhooks    `json:"webhooks"`
+	Cache       Cache       `json:"cache"`
+	Idempotency Idempotency `json:"idempotency"`
+	Tenants     []Tenant    `json:"tenants"`
+	Audit       Audit       `json:"audit"`
+	Privacy     Privacy     `json:"privacy"`
+	Connections Connections `json:"connections"`
+	Anomalies   Anomalies   `json:"anomalies"`
+	Legs        Legs        `json:"legs"`
+	Network     Network     `json:"network"`
+	Airports    Airports    `json:"airports"`
+}
+
+// Log holds the logging configuration
+type Log struct {
+	// Level is the minimum logrus level written, defaults to "info"
+	Level string `json:"level"`
+}
+
+// Auth holds the authentication configuration
+type Auth struct {
+	// APIKeys are the static keys accepted by the service, stored as SHA-256 hashes
+	APIKeys []APIKey `json:"apiKeys"`
+	// JWKSFile is the path of a local JWKS document used to validate bearer tokens
+	JWKSFile string `json:"jwksFile"`
+	// Issuer is the expected "iss" claim of bearer tokens, ignored when empty
+	Issuer string `json:"issuer"`
+	// Audience is the expected "aud" claim of bearer tokens, ignored when empty
+	Audience string `json:"audience"`
+}
+
+// APIKey describes a static API key
+type APIKey struct {
+	// ID identifies the client owning the key
+	ID string `json:"id"`
+	// Hash is the hex encoded SHA-256 of the key
+	Hash  string   `json:"hash"`
+	Roles []string `json:"roles"`
+	// Tenant binds the key to a tenant, the caller then can't act on behalf of another one
+	Tenant string `json:"tenant"`
+}
+
+// Enabled reports whether any authentication method is configured
+func (a Auth) Enabled() bool {
+	return len(a.APIKeys) > 0 || a.JWKSFile != ""
+}
+
+// RateLimit holds the rate limiting and quota configuration
+type RateLimit struct {
+	// Default applies to the routes without a specific limit
+	Default Limit `json:"default"`
+	// Routes holds specific limits by route template, e.g. "/calculate"
+	Routes map[string]Limit `json:"routes"`
+	// DailyLegQuota is the number of flight legs a client may submit per UTC day, unlimited when zero
+	DailyLegQuota int `json:"dailyLegQuota"`
+}
+
+// Limit describes a token bucket
+type Limit struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Burst             int     `json:"burst"`
+}
+
+// Enabled reports whether the limit is configured
+func (l Limit) Enabled() bool {
+	return l.RequestsPerSecond > 0
+}
+
+// Enabled reports whether any rate limit or quota is configured
+func (r RateLimit) Enabled() bool {
+	return r.Default.Enabled() || len(r.Routes) > 0 || r.DailyLegQuota > 0
+}
+
+// Tracing holds the tracing configuration
+type Tracing struct {
+	// Exporter is either "stdout" or "otlp", tracing is disabled when empty
+	Exporter string `json:"exporter"`
+	// Endpoint is the base URL of the OTLP/HTTP collector, e.g. "http://localhost:4318"
+	Endpoint string `json:"endpoint"`
+	// ServiceName identifies the service in the traces, defaults to "user-flight-tracking"
+	ServiceName string `json:"serviceName"`
+}
+
+// Jobs holds the configuration of the asynchronous calculations
+type Jobs struct {
+	// Workers is the number of jobs processed concurrently, defaults to 4
+	Workers int `json:"workers"`
+	// QueueSize is the number of jobs waiting for a worker before new ones are rejected, defaults to 100
+	QueueSize int `json:"queueSize"`
+	// Retention is how long finished jobs and their results are kept, e.g. "30m", defaults to one hour
+	Retention string `json:"retention"`
+}
+
+// Webhooks holds the configuration of the webhook deliveries
+type Webhooks struct {
+	// MaxAttempts is the number of attempts of a delivery before it is dead-lettered, defaults to 5
+	MaxAttempts int `json:"maxAttempts"`
+	// MinBackoff and MaxBackoff bound the exponential wait between attempts, default to "1s" and "1m"
+	MinBackoff string `json:"minBackoff"`
+	MaxBackoff string `json:"maxBackoff"`
+	// Timeout limits every attempt, defaults to "10s"
+	Timeout string `json:"timeout"`
+}
+
+// Cache holds the configuration of the path cache
+type Cache struct {
+	// Capacity is the number of itineraries kept, defaults to 10000
+	Capacity int `json:"capacity"`
+	// TTL is how long an itinerary is kept, e.g. "10m", defaults to five minutes
+	TTL string `json:"ttl"`
+}
+
+// Idempotency holds the configuration of the Idempotency-Key support
+type Idempotency struct {
+	// Window is how long the response of a key is replayed, e.g. "12h", defaults to 24 hours
+	Window string `json:"window"`
+}
+
+// Tenant holds the configuration of a customer whose data is isolated from the other tenants
+type Tenant struct {
+	ID string `json:"id"`
+	// StrictAirportValidation only accepts IATA codes of three uppercase letters
+	StrictAirportValidation bool `json:"strictAirportValidation"`
+	// Features lists the optional features enabled for the tenant, e.g. "jobs", all of them when empty
+	Features []string `json:"features"`
+	// DuplicateLegs overrides the policy of the duplicate legs of Legs for the tenant
+	DuplicateLegs string `json:"duplicateLegs"`
+}
+
+// Audit holds the configuration of the audit log sinks, the entries are kept in memory when none is configured
+type Audit struct {
+	// File is the path of a JSON Lines file the entries are appended to
+	File     string        `json:"file"`
+	Database AuditDatabase `json:"database"`
+}
+
+// AuditDatabase describes the database table the entries are inserted in
+type AuditDatabase struct {
+	// Driver is the name of a database/sql driver linked into the service, e.g. "postgres"
+	Driver string `json:"driver"`
+	DSN    string `json:"dsn"`
+	// Table defaults to "audit_log"
+	Table string `json:"table"`
+}
+
+// Privacy holds the protection of the personal data of the travelers
+type Privacy struct {
+	// PathLogging is how the airports of the paths found are logged: "plain", "hashed" or "omitted", the default
+	PathLogging string `json:"pathLogging"`
+	// PathLoggingKey is the secret of the HMAC of the "hashed" paths, of at least 16 characters
+	PathLoggingKey string `json:"pathLoggingKey"`
+}
+
+// Connections holds the minimum connection times the connections of the timed itineraries are checked against
+type Connections struct {
+	// Domestic and International are the minimum connection times of the airports without their own, default to "45m" and "90m"
+	Domestic      string `json:"domestic"`
+	International string `json:"international"`
+	// Airports holds the minimum connection times of some airports, by IATA code
+	Airports map[string]ConnectionTimes `json:"airports"`
+	// TightMargin is how far above the minimum a connection is still reported as tight, defaults to "30m"
+	TightMargin string `json:"tightMargin"`
+}
+
+// ConnectionTimes are the minimum connection times of an airport, the ones omitted fall back to the defaults
+type ConnectionTimes struct {
+	Domestic      string `json:"domestic"`
+	International string `json:"international"`
+}
+
+// Anomalies holds the thresholds of the analysis of the itineraries
+type Anomalies struct {
+	// MaxGroundSpeedKmh is the fastest plausible flight, defaults to 1200
+	MaxGroundSpeedKmh float64 `json:"maxGroundSpeedKmh"`
+	// MaxSurfaceSpeedKmh is the fastest plausible transfer by land between two airports, defaults to 300
+	MaxSurfaceSpeedKmh float64 `json:"maxSurfaceSpeedKmh"`
+}
+
+// Legs holds the handling of the legs of the itineraries
+type Legs struct {
+	// Duplicates is the policy of the legs repeating the airports of another one: "dedupe" merges them,
+	// "keep" returns them as separate legs and "reject" refuses the itinerary. Defaults to "dedupe"
+	Duplicates string `json:"duplicates"`
+}
+
+// Network holds the known routes the suggestions are searched in, and the partial itineraries completed with
+type Network struct {
+	// RoutesFile is the path of a JSON file whose "routes" holds [origin, destination] pairs,
+	// no route is known when empty
+	RoutesFile string `json:"routesFile"`
+	// MaxInferredLegs bounds the legs added to join two segments of a partial itinerary, from 1 to 5, defaults to 2
+	MaxInferredLegs int `json:"maxInferredLegs"`
+}
+
+// Airports holds the reference data of the airports added to the built-in ones
+type Airports struct {
+	// File is the path of a JSON file whose "airports" holds the code, country, zone, latitude and longitude of
+	// airports, replacing the built-in data of those already known
+	File string `json:"file"`
+}
+
+// Load reads the configuration from a JSON file, an empty path returns the default configuration
+func Load(path string) (Config, error) {
+	var cfg Config
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("reading config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("decoding config: %w", err)
+	}
+
+	return cfg, nil
+}
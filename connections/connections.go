@@ -0,0 +1,126 @@
+package connections
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/volume/service/user-flight-tracking/airports"
+	"github.com/volume/service/user-flight-tracking/dto"
+)
+
+// Defaults applied when the configuration omits a value
+const (
+	DefaultDomestic      = 45 * time.Minute
+	DefaultInternational = 90 * time.Minute
+	DefaultTightMargin   = 30 * time.Minute
+)
+
+// Severities of the connection warnings
+const (
+	// SeverityTight is a connection meeting the minimum connection time by less than the tight margin
+	SeverityTight = "tight"
+	// SeverityImpossible is a connection shorter than the minimum connection time
+	SeverityImpossible = "impossible"
+)
+
+// Times are the minimum connection times at an airport
+type Times struct {
+	Domestic      time.Duration
+	International time.Duration
+}
+
+// Table holds the minimum connection times the connections of the itineraries are checked against
+type Table struct {
+	Default Times
+	// Airports overrides the default times, by IATA code
+	Airports map[string]Times
+	// TightMargin is how far above the minimum connection time a connection is still tight
+	TightMargin time.Duration
+}
+
+// NewTable returns the table of the minimum connection times, the airports without a time use the default one
+func NewTable(defaults Times, overrides map[string]Times, tightMargin time.Duration) (*Table, error) {
+	switch {
+	case defaults.Domestic <= 0:
+		return nil, errors.New("domestic")
+	case defaults.International <= 0:
+		return nil, errors.New("international")
+	case tightMargin < 0:
+		return nil, errors.New("tightMargin")
+	}
+
+	table := &Table{Default: defaults, Airports: make(map[string]Times, len(overrides)), TightMargin: tightMargin}
+	for airport, times := range overrides {
+		if times.Domestic < 0 || times.International < 0 {
+			return nil, fmt.Errorf("airport %s", airport)
+		}
+		if times.Domestic == 0 {
+			times.Domestic = defaults.Domestic
+		}
+		if times.International == 0 {
+			times.International = defaults.International
+		}
+		table.Airports[airport] = times
+	}
+
+	return table, nil
+}
+
+// Minimum returns the minimum connection time at the airport
+func (t *Table) Minimum(airport string, international bool) time.Duration {
+	times, ok := t.Airports[airport]
+	if !ok {
+		times = t.Default
+	}
+
+	if international {
+		return times.International
+	}
+	return times.Domestic
+}
+
+// Check returns the warnings of the tight and impossible connections of the timeline, in the order of the legs
+func (t *Table) Check(timeline dto.Timeline) []dto.ConnectionWarning {
+	var warnings []dto.ConnectionWarning
+	for i := 1; i < len(timeline.Legs); i++ {
+		inbound, outbound := timeline.Legs[i-1], timeline.Legs[i]
+		international := International(inbound.Origin, outbound.Origin, outbound.Destination)
+		minimum := t.Minimum(outbound.Origin, international)
+
+		warning := dto.ConnectionWarning{
+			Airport:       outbound.Origin,
+			International: international,
+			Layover:       outbound.Layover,
+			Minimum:       minimum,
+		}
+		switch {
+		case outbound.Layover < minimum:
+			warning.Severity = SeverityImpossible
+		case outbound.Layover < minimum+t.TightMargin:
+			warning.Severity = SeverityTight
+		default:
+			continue
+		}
+		warnings = append(warnings, warning)
+	}
+
+	return warnings
+}
+
+// International reports whether a connection at the airport, arriving from origin and departing to destination,
+// involves another country. The connections at unknown airports are international, as their times are longer
+func International(origin, airport, destination string) bool {
+	connection, ok := airports.Lookup(airport)
+	if !ok {
+		return true
+	}
+
+	for _, code := range []string{origin, destination} {
+		other, ok := airports.Lookup(code)
+		if !ok || other.Country != connection.Country {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package connections_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/connections"
+	"github.com/volume/service/user-flight-tracking/dto"
+)
+
+var defaults = connections.Times{Domestic: 45 * time.Minute, International: 90 * time.Minute}
+
+func TestConnections_NewTable(t *testing.T) {
+	tests := []struct {
+		name        string
+		defaults    connections.Times
+		overrides   map[string]connections.Times
+		tightMargin time.Duration
+		wantError   error
+	}{
+		{name: "should_return_success", defaults: defaults, overrides: map[string]connections.Times{"ATL": {Domestic: time.Hour}}, tightMargin: time.Minute},
+		{name: "should_return_error_without_domestic_time", defaults: connections.Times{International: time.Hour}, wantError: errors.New("domestic")},
+		{name: "should_return_error_without_international_time", defaults: connections.Times{Domestic: time.Hour}, wantError: errors.New("international")},
+		{name: "should_return_error_when_the_margin_is_negative", defaults: defaults, tightMargin: -time.Minute, wantError: errors.New("tightMargin")},
+		{name: "should_return_error_when_an_airport_time_is_negative", defaults: defaults, overrides: map[string]connections.Times{"ATL": {Domestic: -time.Hour}}, wantError: errors.New("airport ATL")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := connections.NewTable(tt.defaults, tt.overrides, tt.tightMargin)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestConnections_Minimum(t *testing.T) {
+	table, err := connections.NewTable(defaults, map[string]connections.Times{"ATL": {Domestic: time.Hour}}, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Hour, table.Minimum("ATL", false))
+	assert.Equal(t, 90*time.Minute, table.Minimum("ATL", true), "the omitted times should fall back to the defaults")
+	assert.Equal(t, 45*time.Minute, table.Minimum("EWR", false))
+}
+
+func TestConnections_Check(t *testing.T) {
+	table, err := connections.NewTable(defaults, nil, 30*time.Minute)
+	require.NoError(t, err)
+
+	schedule := dto.Timeline{
+		Legs: []dto.TimelineLeg{
+			{Origin: "SFO", Destination: "ATL"},
+			{Origin: "ATL", Destination: "EWR", Layover: 50 * time.Minute},
+			{Origin: "EWR", Destination: "LHR", Layover: 80 * time.Minute},
+			{Origin: "LHR", Destination: "CDG", Layover: 3 * time.Hour},
+		},
+	}
+
+	assert.DeepEqual(t, []dto.ConnectionWarning{
+		{Airport: "ATL", Severity: connections.SeverityTight, Layover: 50 * time.Minute, Minimum: 45 * time.Minute},
+		{Airport: "EWR", Severity: connections.SeverityImpossible, International: true, Layover: 80 * time.Minute, Minimum: 90 * time.Minute},
+	}, table.Check(schedule))
+}
+
+func TestConnections_International(t *testing.T) {
+	tests := []struct {
+		name                         string
+		origin, airport, destination string
+		want                         bool
+	}{
+		{name: "should_be_domestic_within_a_country", origin: "SFO", airport: "ATL", destination: "EWR"},
+		{name: "should_be_international_when_arriving_from_abroad", origin: "LHR", airport: "JFK", destination: "SFO", want: true},
+		{name: "should_be_international_when_departing_abroad", origin: "SFO", airport: "JFK", destination: "LHR", want: true},
+		{name: "should_be_international_with_unknown_airports", origin: "SFO", airport: "XXX", destination: "EWR", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, connections.International(tt.origin, tt.airport, tt.destination))
+		})
+	}
+}
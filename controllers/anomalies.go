@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/anomaly"
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/controllers/translators"
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// Anomalies defines the methods for the analysis of the itineraries
+type Anomalies interface {
+	Analyze(w http.ResponseWriter, r *http.Request)
+}
+
+// anomalies defines the components for the controller
+type anomalies struct {
+	Logger   *log.Entry
+	Detector *anomaly.Detector
+	Auditor  audit.Recorder
+}
+
+// NewAnomalies returns a new instance of Anomalies controller
+func NewAnomalies(log *log.Entry, detector *anomaly.Detector, auditor audit.Recorder) (Anomalies, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case detector == nil:
+		return nil, errors.New("detector")
+	case auditor == nil:
+		return nil, errors.New("auditor")
+	}
+
+	return &anomalies{
+		Logger:   log,
+		Detector: detector,
+		Auditor:  auditor,
+	}, nil
+}
+
+// Analyze returns the risk score of the itinerary of the request, with the legs that couldn't physically happen.
+// Unlike the path calculation, the legs don't need to be connected.
+func (c *anomalies) Analyze(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromContext(r.Context(), c.Logger)
+
+	var request models.PathRequest
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	w = sw
+	defer func() { c.Auditor.Record(r.Context(), pathRequestEntry(audit.ActionAnalyze, request, sw.status)) }()
+
+	request, ok := readPathRequest(w, r, logger)
+	if !ok {
+		return
+	}
+
+	report := c.Detector.Analyze(request.Itinerary())
+	logger.WithField(logging.FieldLegs, len(request.Pairs())).
+		WithField("score", report.Score).
+		WithField("anomalies", len(report.Reasons)).
+		Info("itinerary analyzed")
+
+	writeJSON(w, http.StatusOK, translators.AnomalyReportToModel(report))
+}
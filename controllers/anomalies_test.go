@@ -0,0 +1,109 @@
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/anomaly"
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/controllers"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+func TestController_NewAnomalies(t *testing.T) {
+	detector := newDetector(t)
+	auditor := newAuditor(t)
+
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		detector  *anomaly.Detector
+		auditor   audit.Recorder
+		wantError error
+	}{
+		{name: "should_return_success", logger: log.NewEntry(nil), detector: detector, auditor: auditor},
+		{name: "should_return_error_when_the_logger_is_nil", detector: detector, auditor: auditor, wantError: errors.New("logger")},
+		{name: "should_return_error_when_the_detector_is_nil", logger: log.NewEntry(nil), auditor: auditor, wantError: errors.New("detector")},
+		{name: "should_return_error_when_the_auditor_is_nil", logger: log.NewEntry(nil), detector: detector, wantError: errors.New("auditor")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := controllers.NewAnomalies(tt.logger, tt.detector, tt.auditor)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestController_AnomaliesAnalyze(t *testing.T) {
+	sink, err := audit.NewMemorySink(10)
+	require.NoError(t, err)
+	auditor, err := audit.NewAuditor(log.NewEntry(log.New()), sink)
+	require.NoError(t, err)
+
+	c, err := controllers.NewAnomalies(log.NewEntry(log.New()), newDetector(t), auditor)
+	require.NoError(t, err)
+
+	analyze := func(body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/analyze", bytes.NewReader([]byte(body)))
+		request = request.WithContext(tenancy.NewContext(request.Context(), tenancy.Tenant{ID: "acme"}))
+		recorder := httptest.NewRecorder()
+		c.Analyze(recorder, request)
+		return recorder
+	}
+
+	t.Run("should_return_the_anomalies_of_disconnected_legs", func(t *testing.T) {
+		recorder := analyze(`{"userId": "u-1", "legs": [
+			{"origin": "SFO", "destination": "NRT", "departure": "2023-06-13T11:00", "arrival": "2023-06-14T06:00"},
+			{"origin": "ATL", "destination": "EWR"}
+		]}`)
+
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		var report models.AnomalyReport
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+		assert.Equal(t, 40, report.Score)
+		require.Equal(t, 1, len(report.Reasons))
+		assert.Equal(t, anomaly.KindSupersonic, report.Reasons[0].Kind)
+		assert.DeepEqual(t, []int{0}, report.Reasons[0].Legs)
+
+		entries, err := sink.Query(context.Background(), audit.Filter{Action: audit.ActionAnalyze})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(entries))
+		assert.Equal(t, "u-1", entries[0].UserID)
+		assert.Equal(t, 64, len(entries[0].RequestHash))
+	})
+
+	t.Run("should_return_an_empty_report_without_anomalies", func(t *testing.T) {
+		recorder := analyze(`{"flights": [["SFO", "ATL"], ["ATL", "EWR"]]}`)
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "{\"score\":0,\"reasons\":[]}\n", recorder.Body.String())
+	})
+
+	t.Run("failure_response_when_bad_request", func(t *testing.T) {
+		recorder := analyze(`{"legs": [{"origin": "SFO", "destination": "ATL", "departure": "2023-06-13T08:00"}]}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func newDetector(t *testing.T) *anomaly.Detector {
+	detector, err := anomaly.NewDetector(anomaly.DefaultMaxGroundSpeed, anomaly.DefaultMaxSurfaceSpeed)
+	require.NoError(t, err)
+	return detector
+}
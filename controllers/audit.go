@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/controllers/translators"
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+const (
+	// defaultAuditLimit is the number of entries returned when the request sets no limit
+	defaultAuditLimit = 100
+	// maxAuditLimit bounds the number of entries returned by a request
+	maxAuditLimit = 1000
+)
+
+// Audit defines the methods for the audit log
+type Audit interface {
+	List(w http.ResponseWriter, r *http.Request)
+}
+
+// auditLog defines the components for the controller
+type auditLog struct {
+	Logger *log.Entry
+	Reader audit.Reader
+}
+
+// NewAudit returns a new instance of Audit controller
+func NewAudit(log *log.Entry, reader audit.Reader) (Audit, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case reader == nil:
+		return nil, errors.New("reader")
+	}
+
+	return &auditLog{
+		Logger: log,
+		Reader: reader,
+	}, nil
+}
+
+// List answers with the audit entries selected by the query parameters, oldest first.
+// It is restricted to admins, and admins bound to a tenant only read the entries of their tenant.
+func (c *auditLog) List(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromContext(r.Context(), c.Logger)
+
+	if identity, ok := auth.FromContext(r.Context()); !ok || !identity.HasRole(auth.RoleAdmin) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	filter, err := auditFilter(r)
+	if err != nil {
+		logger.WithError(err).Warn("invalid audit query")
+		http.Error(w, "Bad Request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if id := tenancy.ID(r.Context()); id != "" {
+		filter.Tenant = id
+	}
+
+	entries, err := c.Reader.Query(r.Context(), filter)
+	if err != nil {
+		logger.WithError(err).Error("error querying the audit log")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]models.AuditEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		response = append(response, translators.AuditEntryToModel(entry))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// auditFilter reads the filter from the query parameters
+func auditFilter(r *http.Request) (audit.Filter, error) {
+	query := r.URL.Query()
+	filter := audit.Filter{
+		Tenant: query.Get("tenant"),
+		Actor:  query.Get("actor"),
+		Action: query.Get("action"),
+		UserID: query.Get("userId"),
+		Limit:  defaultAuditLimit,
+	}
+
+	var err error
+	if value := query.Get("from"); value != "" {
+		if filter.From, err = time.Parse(time.RFC3339, value); err != nil {
+			return audit.Filter{}, errors.New("from must be an RFC 3339 time")
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if filter.To, err = time.Parse(time.RFC3339, value); err != nil {
+			return audit.Filter{}, errors.New("to must be an RFC 3339 time")
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > maxAuditLimit {
+			return audit.Filter{}, errors.New("limit must be between 1 and " + strconv.Itoa(maxAuditLimit))
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,122 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/controllers"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+func TestController_NewAudit(t *testing.T) {
+	sink, err := audit.NewMemorySink(1)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		reader    audit.Reader
+		wantError error
+	}{
+		{name: "should_return_success", logger: log.NewEntry(nil), reader: sink},
+		{name: "should_return_error_when_the_logger_is_nil", reader: sink, wantError: errors.New("logger")},
+		{name: "should_return_error_when_the_reader_is_nil", logger: log.NewEntry(nil), wantError: errors.New("reader")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := controllers.NewAudit(tt.logger, tt.reader)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestController_AuditList(t *testing.T) {
+	sink, err := audit.NewMemorySink(10)
+	require.NoError(t, err)
+
+	start := time.Date(2023, 6, 13, 10, 0, 0, 0, time.UTC)
+	for i, e := range []audit.Entry{
+		{ID: "1", Tenant: "acme", Actor: "api_key:agent", Action: audit.ActionCalculate, UserID: "u-1"},
+		{ID: "2", Tenant: "globex", Actor: "api_key:other", Action: audit.ActionCalculate, UserID: "u-1"},
+		{ID: "3", Tenant: "acme", Actor: "api_key:agent", Action: audit.ActionCalculate, UserID: "u-2"},
+	} {
+		e.Time = start.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, sink.Write(context.Background(), e))
+	}
+
+	c, err := controllers.NewAudit(log.NewEntry(log.New()), sink)
+	require.NoError(t, err)
+
+	var (
+		admin      = auth.Identity{Subject: "compliance", Method: auth.MethodJWT, Roles: []string{auth.RoleAdmin}}
+		agent      = auth.Identity{Subject: "agent", Method: auth.MethodAPIKey}
+		acmeTenant = tenancy.Tenant{ID: "acme"}
+	)
+
+	tests := []struct {
+		name       string
+		target     string
+		identity   *auth.Identity
+		tenant     *tenancy.Tenant
+		wantStatus int
+		wantIDs    []string
+	}{
+		{name: "should_return_every_entry_to_admins", target: "/audit", identity: &admin, wantStatus: http.StatusOK, wantIDs: []string{"1", "2", "3"}},
+		{name: "should_filter_by_user_and_tenant", target: "/audit?userId=u-1&tenant=globex", identity: &admin, wantStatus: http.StatusOK, wantIDs: []string{"2"}},
+		{name: "should_filter_by_time", target: "/audit?from=2023-06-13T10:01:00Z&to=2023-06-13T10:02:00Z", identity: &admin, wantStatus: http.StatusOK, wantIDs: []string{"2"}},
+		{name: "should_limit_the_entries", target: "/audit?limit=2", identity: &admin, wantStatus: http.StatusOK, wantIDs: []string{"1", "2"}},
+		{name: "should_return_the_entries_of_the_tenant_of_admins_bound_to_one", target: "/audit?tenant=globex", identity: &admin, tenant: &acmeTenant, wantStatus: http.StatusOK, wantIDs: []string{"1", "3"}},
+		{name: "should_return_an_empty_list", target: "/audit?userId=u-3", identity: &admin, wantStatus: http.StatusOK, wantIDs: []string{}},
+		{name: "failure_response_when_the_caller_is_not_admin", target: "/audit", identity: &agent, wantStatus: http.StatusForbidden},
+		{name: "failure_response_when_the_caller_is_anonymous", target: "/audit", wantStatus: http.StatusForbidden},
+		{name: "failure_response_when_the_time_is_invalid", target: "/audit?from=yesterday", identity: &admin, wantStatus: http.StatusBadRequest},
+		{name: "failure_response_when_the_limit_is_invalid", target: "/audit?limit=0", identity: &admin, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			ctx := request.Context()
+			if tt.identity != nil {
+				ctx = auth.NewContext(ctx, *tt.identity)
+			}
+			if tt.tenant != nil {
+				ctx = tenancy.NewContext(ctx, *tt.tenant)
+			}
+			recorder := httptest.NewRecorder()
+
+			c.List(recorder, request.WithContext(ctx))
+
+			require.Equal(t, tt.wantStatus, recorder.Code, recorder.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var entries []models.AuditEntryResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &entries))
+			ids := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				ids = append(ids, entry.ID)
+			}
+			assert.DeepEqual(t, tt.wantIDs, ids)
+		})
+	}
+}
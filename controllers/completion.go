@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/completion"
+	"github.com/volume/service/user-flight-tracking/controllers/translators"
+	"github.com/volume/service/user-flight-tracking/duplicates"
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/mediators"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// Completion defines the methods for the completion of the partial itineraries
+type Completion interface {
+	Complete(w http.ResponseWriter, r *http.Request)
+}
+
+// completionController defines the components for the controller
+type completionController struct {
+	Logger                *log.Entry
+	Completer             *completion.Completer
+	FlightTrackerMediator mediators.FlightTracker
+	Auditor               audit.Recorder
+}
+
+// NewCompletion returns a new instance of Completion controller
+func NewCompletion(
+	log *log.Entry,
+	completer *completion.Completer,
+	flightTrackerMediator mediators.FlightTracker,
+	auditor audit.Recorder,
+) (Completion, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case completer == nil:
+		return nil, errors.New("completer")
+	case flightTrackerMediator == nil:
+		return nil, errors.New("flightTrackerMediator")
+	case auditor == nil:
+		return nil, errors.New("auditor")
+	}
+
+	return &completionController{
+		Logger:                log,
+		Completer:             completer,
+		FlightTrackerMediator: flightTrackerMediator,
+		Auditor:               auditor,
+	}, nil
+}
+
+// Complete returns the path of the itinerary of the request, joining its disconnected segments with the fewest
+// legs of the known network. The legs added are marked as inferred.
+func (c *completionController) Complete(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromContext(r.Context(), c.Logger)
+	logger.WithField("url", r.URL.Path).Debug("request")
+
+	var request models.PathRequest
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	w = sw
+	defer func() { c.Auditor.Record(r.Context(), pathRequestEntry(audit.ActionComplete, request, sw.status)) }()
+
+	request, ok := readPathRequest(w, r, logger)
+	if !ok {
+		return
+	}
+	logger = logger.WithField(logging.FieldLegs, len(request.Pairs()))
+
+	completed, err := c.Completer.Complete(request)
+	if errors.Is(err, completion.ErrInvalidItinerary) {
+		logger.WithError(err).Error("error validating request")
+		http.Error(w, "Bad Request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Warn("itinerary could not be completed")
+		http.Error(w, "Not Found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	logger = logger.WithField("inferredLegs", len(completed.Pairs())-len(request.Pairs()))
+
+	// the legs of the request come first in the completed one, the positions of its duplicates are unchanged
+	path, err := c.FlightTrackerMediator.GetFlightsPath(r.Context(), completed)
+	var rejected *duplicates.Error
+	if errors.As(err, &rejected) {
+		logger.WithError(err).Error("error validating request")
+		writeDuplicateLegs(w, legsField(request), rejected)
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Error("internal server error")
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	logger.Info("itinerary completed")
+	if err := writeCacheableJSON(w, r, translators.PathDTOtoModel(path)); err != nil {
+		logger.WithError(err).Error("error encoding JSON")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,136 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/completion"
+	"github.com/volume/service/user-flight-tracking/controllers"
+	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/mediators"
+	mock_flightTracker_mediator "github.com/volume/service/user-flight-tracking/mocks/mockmediators"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/network"
+)
+
+func TestController_NewCompletion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		completer    = newCompleter(t)
+		mockMediator = mock_flightTracker_mediator.NewMockFlightTracker(ctrl)
+		auditor      = newAuditor(t)
+	)
+
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		completer *completion.Completer
+		mediator  mediators.FlightTracker
+		auditor   audit.Recorder
+		wantError error
+	}{
+		{name: "should_return_success", logger: log.NewEntry(nil), completer: completer, mediator: mockMediator, auditor: auditor},
+		{name: "should_return_error_when_the_logger_is_nil", completer: completer, mediator: mockMediator, auditor: auditor, wantError: errors.New("logger")},
+		{name: "should_return_error_when_the_completer_is_nil", logger: log.NewEntry(nil), mediator: mockMediator, auditor: auditor, wantError: errors.New("completer")},
+		{name: "should_return_error_when_the_mediator_is_nil", logger: log.NewEntry(nil), completer: completer, auditor: auditor, wantError: errors.New("flightTrackerMediator")},
+		{name: "should_return_error_when_the_auditor_is_nil", logger: log.NewEntry(nil), completer: completer, mediator: mockMediator, wantError: errors.New("auditor")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := controllers.NewCompletion(tt.logger, tt.completer, tt.mediator, tt.auditor)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestController_CompletionComplete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMediator := mock_flightTracker_mediator.NewMockFlightTracker(ctrl)
+	c, err := controllers.NewCompletion(log.NewEntry(log.New()), newCompleter(t), mockMediator, newAuditor(t))
+	require.NoError(t, err)
+
+	complete := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		c.Complete(recorder, httptest.NewRequest(http.MethodPost, "/complete", bytes.NewReader([]byte(body))))
+		return recorder
+	}
+
+	t.Run("should_return_the_path_with_the_inferred_legs", func(t *testing.T) {
+		mockMediator.EXPECT().GetFlightsPath(gomock.Any(), models.PathRequest{Legs: []models.Leg{
+			{Origin: "SFO", Destination: "ATL"},
+			{Origin: "GSO", Destination: "EWR"},
+			{Origin: "ATL", Destination: "GSO", Inferred: true},
+		}}).Return(dto.Path{
+			Airports: []string{"SFO", "ATL", "GSO", "EWR"},
+			Legs: []dto.Leg{
+				{Origin: "SFO", Destination: "ATL"},
+				{Origin: "ATL", Destination: "GSO", Inferred: true},
+				{Origin: "GSO", Destination: "EWR"},
+			},
+		}, nil)
+
+		recorder := complete(`{"flights": [["SFO", "ATL"], ["GSO", "EWR"]]}`)
+
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		var response models.PathResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.DeepEqual(t, []models.Leg{
+			{Origin: "SFO", Destination: "ATL"},
+			{Origin: "ATL", Destination: "GSO", Inferred: true},
+			{Origin: "GSO", Destination: "EWR"},
+		}, response.Legs)
+	})
+
+	t.Run("failure_response_when_no_route_joins_the_segments", func(t *testing.T) {
+		recorder := complete(`{"flights": [["SFO", "ATL"], ["MIA", "EWR"]]}`)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Equal(t, "Not Found: no known route joins the segments of the flights\n", recorder.Body.String())
+	})
+
+	t.Run("failure_response_when_the_flights_branch", func(t *testing.T) {
+		recorder := complete(`{"flights": [["SFO", "ATL"], ["SFO", "EWR"]]}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, "Bad Request: invalid itinerary: the flights branch at SFO\n", recorder.Body.String())
+	})
+
+	t.Run("failure_response_when_the_flights_are_circular", func(t *testing.T) {
+		recorder := complete(`{"flights": [["SFO", "ATL"], ["JFK", "BOS"], ["BOS", "JFK"]]}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, "Bad Request: invalid itinerary: a circular flight was found between flights: JFK\n", recorder.Body.String())
+	})
+
+	t.Run("failure_response_when_the_legs_are_marked_as_inferred", func(t *testing.T) {
+		recorder := complete(`{"legs": [{"origin": "SFO", "destination": "ATL", "inferred": true}]}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func newCompleter(t *testing.T) *completion.Completer {
+	completer, err := completion.NewCompleter(network.New([][]string{{"ATL", "GSO"}}), completion.DefaultMaxInferredLegs)
+	require.NoError(t, err)
+	return completer
+}
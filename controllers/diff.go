@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/controllers/translators"
+	"github.com/volume/service/user-flight-tracking/duplicates"
+	"github.com/volume/service/user-flight-tracking/idempotency"
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/mediators"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+// ItineraryDiff defines the methods for the comparison of the versions of an itinerary
+type ItineraryDiff interface {
+	Diff(w http.ResponseWriter, r *http.Request)
+}
+
+// itineraryDiffController defines the components for the controller
+type itineraryDiffController struct {
+	Logger                *log.Entry
+	FlightTrackerMediator mediators.FlightTracker
+	Auditor               audit.Recorder
+}
+
+// NewItineraryDiff returns a new instance of ItineraryDiff controller
+func NewItineraryDiff(log *log.Entry, flightTrackerMediator mediators.FlightTracker, auditor audit.Recorder) (ItineraryDiff, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case flightTrackerMediator == nil:
+		return nil, errors.New("flightTrackerMediator")
+	case auditor == nil:
+		return nil, errors.New("auditor")
+	}
+
+	return &itineraryDiffController{
+		Logger:                log,
+		FlightTrackerMediator: flightTrackerMediator,
+		Auditor:               auditor,
+	}, nil
+}
+
+// Diff reconstructs the paths of the old and new versions of an itinerary, and returns the legs added, removed,
+// reordered and changed, with the changes of its start and end.
+func (c *itineraryDiffController) Diff(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromContext(r.Context(), c.Logger)
+	logger.WithField("url", r.URL.Path).Debug("request")
+
+	// the audit log identifies the comparison by the new version of the itinerary, once validated
+	var audited models.PathRequest
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	w = sw
+	defer func() { c.Auditor.Record(r.Context(), pathRequestEntry(audit.ActionDiff, audited, sw.status)) }()
+
+	var request models.DiffRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.WithError(err).Error("error decoding request")
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	// Validate the request, with the airport rules of the tenant
+	validate := request.Validate
+	if tenant, _ := tenancy.FromContext(r.Context()); tenant.StrictAirports {
+		validate = request.ValidateStrict
+	}
+	if err := validate(); err != nil {
+		logger.WithError(err).Error("error validating request")
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	audited = request.New
+	idempotency.Track(r.Context(), request.Old.UserID)
+	idempotency.Track(r.Context(), request.New.UserID)
+	logger = logger.WithField(logging.FieldLegs, len(request.New.Pairs()))
+
+	diff, err := c.FlightTrackerMediator.DiffFlightsPaths(r.Context(), request.Old, request.New)
+	var (
+		rejected *duplicates.Error
+		version  *mediators.VersionError
+		noPath   *mediators.PathError
+	)
+	switch {
+	case errors.As(err, &rejected) && errors.As(err, &version):
+		logger.WithError(err).Error("error validating request")
+		rejectedRequest := request.Old
+		if version.Version == mediators.VersionNew {
+			rejectedRequest = request.New
+		}
+		writeDuplicateLegs(w, version.Version+"."+legsField(rejectedRequest), rejected)
+		return
+	case errors.As(err, &noPath) && errors.As(err, &version):
+		logger.WithError(err).Warn("itineraries could not be compared")
+		http.Error(w, "Not Found: "+version.Version+" itinerary: "+noPath.Reason, http.StatusNotFound)
+		return
+	case err != nil:
+		logger.WithError(err).Error("error comparing itineraries")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	logger.WithField("added", len(diff.Added)).
+		WithField("removed", len(diff.Removed)).
+		WithField("reordered", len(diff.Reordered)).
+		WithField("changed", len(diff.Changed)).
+		Info("itineraries compared")
+	writeJSON(w, http.StatusOK, translators.PathDiffToModel(diff))
+}
@@ -0,0 +1,167 @@
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/controllers"
+	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/duplicates"
+	"github.com/volume/service/user-flight-tracking/mediators"
+	mock_flightTracker_mediator "github.com/volume/service/user-flight-tracking/mocks/mockmediators"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+func TestController_NewItineraryDiff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		mockMediator = mock_flightTracker_mediator.NewMockFlightTracker(ctrl)
+		auditor      = newAuditor(t)
+	)
+
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		mediator  mediators.FlightTracker
+		auditor   audit.Recorder
+		wantError error
+	}{
+		{name: "should_return_success", logger: log.NewEntry(nil), mediator: mockMediator, auditor: auditor},
+		{name: "should_return_error_when_the_logger_is_nil", mediator: mockMediator, auditor: auditor, wantError: errors.New("logger")},
+		{name: "should_return_error_when_the_mediator_is_nil", logger: log.NewEntry(nil), auditor: auditor, wantError: errors.New("flightTrackerMediator")},
+		{name: "should_return_error_when_the_auditor_is_nil", logger: log.NewEntry(nil), mediator: mockMediator, wantError: errors.New("auditor")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := controllers.NewItineraryDiff(tt.logger, tt.mediator, tt.auditor)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestController_ItineraryDiffDiff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMediator := mock_flightTracker_mediator.NewMockFlightTracker(ctrl)
+	c, err := controllers.NewItineraryDiff(log.NewEntry(log.New()), mockMediator, newAuditor(t))
+	require.NoError(t, err)
+
+	diff := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		c.Diff(recorder, httptest.NewRequest(http.MethodPost, "/diff", bytes.NewReader([]byte(body))))
+		return recorder
+	}
+	body := `{"old": {"legs": [{"origin": "SFO", "destination": "ATL", "carrier": "UA", "flightNumber": "88"}]},
+		"new": {"legs": [{"origin": "SFO", "destination": "ATL", "carrier": "UA", "flightNumber": "89"}, {"origin": "ATL", "destination": "EWR"}]}}`
+
+	t.Run("should_return_the_differences", func(t *testing.T) {
+		old := dto.Path{Airports: []string{"SFO", "ATL"}, Legs: []dto.Leg{{Origin: "SFO", Destination: "ATL", Carrier: "UA", FlightNumber: "88"}}}
+		updated := dto.Path{
+			Airports: []string{"SFO", "ATL", "EWR"},
+			Legs:     []dto.Leg{{Origin: "SFO", Destination: "ATL", Carrier: "UA", FlightNumber: "89"}, {Origin: "ATL", Destination: "EWR"}},
+		}
+		mockMediator.EXPECT().DiffFlightsPaths(gomock.Any(), gomock.Any(), gomock.Any()).Return(dto.PathDiff{
+			Old:        old,
+			New:        updated,
+			EndChanged: true,
+			Added:      []dto.LegDiff{{New: updated.Legs[1], OldPosition: -1, NewPosition: 1}},
+			Changed:    []dto.LegDiff{{Old: old.Legs[0], New: updated.Legs[0], Changes: []string{"flightNumber"}}},
+		}, nil)
+
+		recorder := diff(body)
+
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		var response models.ItineraryDiff
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+
+		zero, one := 0, 1
+		assert.Assert(t, response.Start == nil)
+		assert.DeepEqual(t, &models.AirportChange{Old: "ATL", New: "EWR"}, response.End)
+		assert.DeepEqual(t, []models.LegDiff{{Origin: "ATL", Destination: "EWR", NewPosition: &one}}, response.Added)
+		assert.DeepEqual(t, []models.LegDiff{}, response.Removed)
+		assert.DeepEqual(t, []models.LegDiff{{
+			Origin:      "SFO",
+			Destination: "ATL",
+			OldPosition: &zero,
+			NewPosition: &zero,
+			Changes:     []models.DetailChange{{Field: "flightNumber", Old: "88", New: "89"}},
+		}}, response.Changed)
+	})
+
+	t.Run("failure_response_when_a_version_has_no_path", func(t *testing.T) {
+		mockMediator.EXPECT().DiffFlightsPaths(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(dto.PathDiff{}, &mediators.VersionError{Version: mediators.VersionNew, Err: &mediators.PathError{Reason: "no initial flight found"}})
+
+		recorder := diff(body)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Equal(t, "Not Found: new itinerary: no initial flight found\n", recorder.Body.String())
+	})
+
+	t.Run("failure_response_when_the_comparison_fails", func(t *testing.T) {
+		tests := []struct {
+			name string
+			err  error
+		}{
+			{name: "canceled", err: &mediators.VersionError{Version: mediators.VersionOld, Err: context.Canceled}},
+			{name: "unexpected", err: errors.New("dial tcp 10.0.0.7:6379: connection refused")},
+		}
+		for _, tt := range tests {
+			mockMediator.EXPECT().DiffFlightsPaths(gomock.Any(), gomock.Any(), gomock.Any()).Return(dto.PathDiff{}, tt.err)
+
+			recorder := diff(body)
+
+			assert.Equal(t, http.StatusInternalServerError, recorder.Code, tt.name)
+			assert.Equal(t, "Internal Server Error\n", recorder.Body.String(), tt.name)
+		}
+	})
+
+	t.Run("failure_response_when_the_duplicate_legs_are_rejected", func(t *testing.T) {
+		mockMediator.EXPECT().DiffFlightsPaths(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(dto.PathDiff{}, &mediators.VersionError{Version: mediators.VersionOld, Err: &duplicates.Error{
+				Duplicates: []dto.DuplicateLegs{{Origin: "SFO", Destination: "ATL", Legs: []int{0, 1}}},
+			}})
+
+		recorder := diff(body)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		var response models.ValidationErrorResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, "duplicate legs", response.Message)
+		assert.DeepEqual(t, []models.FieldError{
+			{Field: "old.legs[1]", Message: "repeats the leg SFO-ATL of old.legs[0]"},
+		}, response.Errors)
+	})
+
+	t.Run("failure_response_when_a_version_is_invalid", func(t *testing.T) {
+		recorder := diff(`{"old": {"flights": [["SFO", "ATL"]]}, "new": {"flights": [["SFO"]]}}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("failure_response_when_a_version_is_missing", func(t *testing.T) {
+		recorder := diff(`{"new": {"flights": [["SFO", "ATL"]]}}`)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
@@ -1,16 +1,24 @@
 package controllers
 
 import (
-	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"mime"
 	"net/http"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/volume/service/user-flight-tracking/audit"
 	"github.com/volume/service/user-flight-tracking/controllers/translators"
+	"github.com/volume/service/user-flight-tracking/duplicates"
+	"github.com/volume/service/user-flight-tracking/idempotency"
+	"github.com/volume/service/user-flight-tracking/logging"
 	"github.com/volume/service/user-flight-tracking/mediators"
 	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/tenancy"
 )
 
 // Service defines the methods for flight
@@ -22,54 +30,210 @@ type FlightTracker interface {
 type flightTracker struct {
 	Logger                *log.Entry
 	FlightTrackerMediator mediators.FlightTracker
+	Auditor               audit.Recorder
 }
 
 // NewFlightTracker returns a new instance of FlightTracker controller
-func NewFlightTracker(log *log.Entry, flightTrackerMediator mediators.FlightTracker) (FlightTracker, error) {
+func NewFlightTracker(log *log.Entry, flightTrackerMediator mediators.FlightTracker, auditor audit.Recorder) (FlightTracker, error) {
 	switch {
 	case log == nil:
 		return nil, errors.New("logger")
 	case flightTrackerMediator == nil:
 		return nil, errors.New("flightTrackerMediator")
+	case auditor == nil:
+		return nil, errors.New("auditor")
 	}
 
 	return &flightTracker{
 		Logger:                log,
 		FlightTrackerMediator: flightTrackerMediator,
+		Auditor:               auditor,
 	}, nil
 }
 
-// GetPath retrieves flight path from the backend
+// GetPath retrieves flight path from the backend, recording the call in the audit log
 func (c *flightTracker) GetPath(w http.ResponseWriter, r *http.Request) {
-	c.Logger.WithField("url", r.URL).Info("request")
+	logger := logging.FromContext(r.Context(), c.Logger)
+	logger.WithField("url", r.URL.Path).Debug("request")
 
-	// Decodes the JSON data from the request body into an instance of the `PathRequest` structure
 	var request models.PathRequest
-	err := json.NewDecoder(r.Body).Decode(&request)
-	if err != nil {
-		c.Logger.WithError(err).Error("error decoding JSON")
-		http.Error(w, "Bad Request", http.StatusBadRequest)
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	w = sw
+	defer func() { c.Auditor.Record(r.Context(), pathRequestEntry(audit.ActionCalculate, request, sw.status)) }()
+
+	request, ok := readPathRequest(w, r, logger)
+	if !ok {
 		return
 	}
+	logger = logger.WithField(logging.FieldLegs, len(request.Pairs()))
 
-	// Validate the request
-	if err := request.Validate(); err != nil {
-		c.Logger.WithError(err).Error("error validating request")
-		http.Error(w, "Bad Request", http.StatusBadRequest)
+	path, err := c.FlightTrackerMediator.GetFlightsPath(r.Context(), request)
+	var rejected *duplicates.Error
+	if errors.As(err, &rejected) {
+		logger.WithError(err).Error("error validating request")
+		writeDuplicateLegs(w, legsField(request), rejected)
 		return
 	}
-
-	path, err := c.FlightTrackerMediator.GetFlightsPath(context.Background(), request)
 	if err != nil {
-		c.Logger.WithError(err).Error("internal server error")
+		logger.WithError(err).Error("internal server error")
 		http.Error(w, "Not Found", http.StatusNotFound)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(translators.PathDTOtoModel(path)); err != nil {
-		c.Logger.WithError(err).Error("error encoding JSON")
+	if err := writeCacheableJSON(w, r, translators.PathDTOtoModel(path)); err != nil {
+		logger.WithError(err).Error("error encoding JSON")
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 }
+
+// pathRequestEntry returns the audit entry of an action on the itinerary of request, identified by its hash
+func pathRequestEntry(action string, request models.PathRequest, status int) audit.Entry {
+	entry := audit.Entry{
+		Action:  action,
+		UserID:  request.UserID,
+		Outcome: audit.Outcome(status),
+		Status:  status,
+	}
+	if len(request.Pairs()) > 0 {
+		encoded, _ := json.Marshal(request)
+		sum := sha256.Sum256(encoded)
+		entry.RequestHash = hex.EncodeToString(sum[:])
+	}
+
+	return entry
+}
+
+// readPathRequest decodes and validates the request body, answering 400 Bad Request when it is invalid
+func readPathRequest(w http.ResponseWriter, r *http.Request, logger *log.Entry) (models.PathRequest, bool) {
+	tenant, _ := tenancy.FromContext(r.Context())
+	if isCSV(r) && !tenant.Allows(tenancy.FeatureCSV) {
+		http.Error(w, "Forbidden: "+tenancy.FeatureCSV+" is not enabled for the tenant", http.StatusForbidden)
+		return models.PathRequest{}, false
+	}
+
+	// Decodes the request body into an instance of the `PathRequest` structure
+	request, err := decodePathRequest(r)
+	if err != nil {
+		logger.WithError(err).Error("error decoding request")
+
+		var rowErrs translators.CSVRowErrors
+		if errors.As(err, &rowErrs) {
+			writeJSON(w, http.StatusBadRequest, models.ValidationErrorResponse{
+				Message: "invalid csv rows",
+				Errors:  rowErrs,
+			})
+			return models.PathRequest{}, false
+		}
+
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return models.PathRequest{}, false
+	}
+
+	logging.AddFields(r.Context(), log.Fields{logging.FieldLegs: len(request.Pairs())})
+
+	// Validate the request, with the airport rules of the tenant
+	validate := request.Validate
+	if tenant.StrictAirports {
+		validate = request.ValidateStrict
+	}
+	if err := validate(); err != nil {
+		logger.WithField(logging.FieldLegs, len(request.Pairs())).WithError(err).Error("error validating request")
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return models.PathRequest{}, false
+	}
+	idempotency.Track(r.Context(), request.UserID)
+
+	return request, true
+}
+
+// writeDuplicateLegs answers 400 Bad Request with the duplicate legs of a request rejected by the policy of the tenant,
+// field names the legs in the errors, e.g. "flights" or "old.legs"
+func writeDuplicateLegs(w http.ResponseWriter, field string, rejected *duplicates.Error) {
+	writeJSON(w, http.StatusBadRequest, models.ValidationErrorResponse{
+		Message: "duplicate legs",
+		Errors:  translators.DuplicateLegsToFieldErrors(field, rejected.Duplicates),
+	})
+}
+
+// legsField returns the name of the legs in the request, "flights" or "legs"
+func legsField(request models.PathRequest) string {
+	if len(request.Legs) > 0 {
+		return "legs"
+	}
+	return "flights"
+}
+
+// decodePathRequest decodes a JSON body, or a CSV upload when the content type is text/csv
+func decodePathRequest(r *http.Request) (models.PathRequest, error) {
+	if isCSV(r) {
+		return translators.CSVToPathRequest(r.Body)
+	}
+
+	var request models.PathRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	return request, err
+}
+
+// isCSV reports whether the request body is a CSV upload
+func isCSV(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "text/csv"
+}
+
+// writeJSON encodes v as the JSON body of the response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeCacheableJSON encodes v as the JSON body of a 200 OK response tagged with the hash of the body. When the
+// request already holds the same body in If-None-Match, GET and HEAD are answered with 304 Not Modified and the
+// other methods with 412 Precondition Failed, as a 304 only applies to GET and HEAD (RFC 9110 §13.1.2)
+func writeCacheableJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	body = append(body, '\n')
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+		http.Error(w, "Precondition Failed", http.StatusPreconditionFailed)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(body)
+	return err
+}
+
+// etagMatches applies the weak comparison of If-None-Match to a list of entity tags
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// statusWriter records the status code of the response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
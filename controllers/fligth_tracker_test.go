@@ -2,6 +2,7 @@ package controllers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -14,11 +15,15 @@ import (
 	"github.com/stretchr/testify/require"
 	"gotest.tools/assert"
 
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/auth"
 	"github.com/volume/service/user-flight-tracking/controllers"
 	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/duplicates"
 	"github.com/volume/service/user-flight-tracking/mediators"
 	mock_flightTracker_mediator "github.com/volume/service/user-flight-tracking/mocks/mockmediators"
 	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/tenancy"
 )
 
 func TestController_NewFlightTracker(t *testing.T) {
@@ -28,11 +33,13 @@ func TestController_NewFlightTracker(t *testing.T) {
 	var (
 		logger       = log.NewEntry(nil)
 		mockMediator = mock_flightTracker_mediator.NewMockFlightTracker(ctrl)
+		auditor      = newAuditor(t)
 	)
 
 	type args struct {
 		logger       *log.Entry
 		mockMediator mediators.FlightTracker
+		auditor      audit.Recorder
 	}
 	tests := []struct {
 		name      string
@@ -44,6 +51,7 @@ func TestController_NewFlightTracker(t *testing.T) {
 			args: args{
 				logger:       logger,
 				mockMediator: mockMediator,
+				auditor:      auditor,
 			},
 			wantError: nil,
 		},
@@ -52,6 +60,7 @@ func TestController_NewFlightTracker(t *testing.T) {
 			args: args{
 				logger:       nil,
 				mockMediator: mockMediator,
+				auditor:      auditor,
 			},
 			wantError: errors.New("logger"),
 		},
@@ -60,14 +69,24 @@ func TestController_NewFlightTracker(t *testing.T) {
 			args: args{
 				logger:       logger,
 				mockMediator: nil,
+				auditor:      auditor,
 			},
 			wantError: errors.New("flightTrackerMediator"),
 		},
+		{
+			name: "should_return_error_when_the_auditor_is_nil",
+			args: args{
+				logger:       logger,
+				mockMediator: mockMediator,
+				auditor:      nil,
+			},
+			wantError: errors.New("auditor"),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := controllers.NewFlightTracker(tt.args.logger, tt.args.mockMediator)
+			_, err := controllers.NewFlightTracker(tt.args.logger, tt.args.mockMediator, tt.args.auditor)
 			if err != nil {
 				assert.Equal(t, tt.wantError.Error(), err.Error())
 			}
@@ -82,6 +101,7 @@ func TestController_GetPath(t *testing.T) {
 	var (
 		logger       = log.NewEntry(log.New())
 		mockMediator = mock_flightTracker_mediator.NewMockFlightTracker(ctrl)
+		auditor      = newAuditor(t)
 	)
 
 	t.Run("should_return_path", func(t *testing.T) {
@@ -92,18 +112,12 @@ func TestController_GetPath(t *testing.T) {
 		}
 
 		path := dto.Path{
-			Flights: []*dto.Flight{
-				{Name: "SFO"},
-				{Name: "ATL"},
-				{Name: "GSO"},
-				{Name: "IND"},
-				{Name: "EWR"},
-			},
+			Airports: []string{"SFO", "ATL", "GSO", "IND", "EWR"},
 		}
 
 		mockMediator.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(path, nil)
 
-		c, err := controllers.NewFlightTracker(logger, mockMediator)
+		c, err := controllers.NewFlightTracker(logger, mockMediator, auditor)
 		require.NoError(t, err)
 
 		jsonBody := `{
@@ -138,7 +152,7 @@ func TestController_GetPath(t *testing.T) {
 	})
 
 	t.Run("failure_response_when_bad_request", func(t *testing.T) {
-		c, err := controllers.NewFlightTracker(logger, mockMediator)
+		c, err := controllers.NewFlightTracker(logger, mockMediator, auditor)
 		require.NoError(t, err)
 
 		jsonBody := `{
@@ -164,7 +178,7 @@ func TestController_GetPath(t *testing.T) {
 	})
 
 	t.Run("failure_response_when_bad_request", func(t *testing.T) {
-		c, err := controllers.NewFlightTracker(logger, mockMediator)
+		c, err := controllers.NewFlightTracker(logger, mockMediator, auditor)
 		require.NoError(t, err)
 
 		jsonBody := `{
@@ -190,7 +204,7 @@ func TestController_GetPath(t *testing.T) {
 	})
 
 	t.Run("failure_response_when_mediator_retrun_error", func(t *testing.T) {
-		c, err := controllers.NewFlightTracker(logger, mockMediator)
+		c, err := controllers.NewFlightTracker(logger, mockMediator, auditor)
 		require.NoError(t, err)
 
 		jsonBody := `{
@@ -216,4 +230,232 @@ func TestController_GetPath(t *testing.T) {
 
 		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 	})
+
+	t.Run("failure_response_when_the_duplicate_legs_are_rejected", func(t *testing.T) {
+		c, err := controllers.NewFlightTracker(logger, mockMediator, auditor)
+		require.NoError(t, err)
+
+		jsonBody := `{"flights": [["SFO", "ATL"], ["ATL", "EWR"], ["SFO", "ATL"]]}`
+
+		mockMediator.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(dto.Path{}, &duplicates.Error{
+			Duplicates: []dto.DuplicateLegs{{Origin: "SFO", Destination: "ATL", Legs: []int{0, 2}}},
+		})
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewReader([]byte(jsonBody)))
+
+		c.GetPath(recorder, request)
+
+		resp := recorder.Result()
+		defer resp.Body.Close()
+
+		var body models.ValidationErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.DeepEqual(t, models.ValidationErrorResponse{
+			Message: "duplicate legs",
+			Errors:  []models.FieldError{{Field: "flights[2]", Message: "repeats the leg SFO-ATL of flights[0]"}},
+		}, body)
+	})
+
+	t.Run("should_return_path_from_csv", func(t *testing.T) {
+		path := dto.Path{
+			Airports: []string{"SFO", "ATL", "EWR"},
+		}
+
+		mockMediator.EXPECT().GetFlightsPath(gomock.Any(), models.PathRequest{
+			Flights: [][]string{{"SFO", "ATL"}, {"ATL", "EWR"}},
+			UserID:  "u-1",
+			Dates:   []string{"2023-06-13", "2023-06-14"},
+		}).Return(path, nil)
+
+		c, err := controllers.NewFlightTracker(logger, mockMediator, auditor)
+		require.NoError(t, err)
+
+		csvBody := "user_id,origin,destination,date\nu-1,SFO,ATL,2023-06-13\nu-1,ATL,EWR,2023-06-14\n"
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewReader([]byte(csvBody)))
+		request.Header.Set("Content-Type", "text/csv")
+
+		c.GetPath(recorder, request)
+
+		resp := recorder.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("failure_response_with_row_errors_when_csv_is_invalid", func(t *testing.T) {
+		c, err := controllers.NewFlightTracker(logger, mockMediator, auditor)
+		require.NoError(t, err)
+
+		csvBody := "origin,destination\nSFO,ATL\nATLX,EWR\n"
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewReader([]byte(csvBody)))
+		request.Header.Set("Content-Type", "text/csv; charset=utf-8")
+
+		c.GetPath(recorder, request)
+
+		resp := recorder.Result()
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err, "should return a readable response body")
+
+		responseBody := models.ValidationErrorResponse{}
+		require.NoError(t, json.Unmarshal(body, &responseBody))
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.DeepEqual(t, []models.FieldError{{Row: 3, Field: "origin", Message: "the airport must have exactly 3 characters"}}, responseBody.Errors)
+	})
+
+	t.Run("should_return_precondition_failed_when_the_etag_matches", func(t *testing.T) {
+		path := dto.Path{
+			Airports: []string{"SFO", "EWR"},
+		}
+
+		mockMediator.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(path, nil).Times(3)
+
+		c, err := controllers.NewFlightTracker(logger, mockMediator, auditor)
+		require.NoError(t, err)
+
+		serve := func(ifNoneMatch string) *httptest.ResponseRecorder {
+			request := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewReader([]byte(`{"flights": [["SFO", "EWR"]]}`)))
+			if ifNoneMatch != "" {
+				request.Header.Set("If-None-Match", ifNoneMatch)
+			}
+			recorder := httptest.NewRecorder()
+			c.GetPath(recorder, request)
+			return recorder
+		}
+
+		first := serve("")
+		etag := first.Header().Get("ETag")
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Assert(t, etag != "", "the response must carry an ETag")
+
+		// a 304 only answers GET and HEAD requests
+		matching := serve(`"stale", W/` + etag)
+		assert.Equal(t, http.StatusPreconditionFailed, matching.Code)
+		assert.Equal(t, etag, matching.Header().Get("ETag"))
+		assert.Equal(t, "Precondition Failed\n", matching.Body.String())
+
+		assert.Equal(t, http.StatusOK, serve(`"stale"`).Code)
+	})
+
+	t.Run("failure_response_when_the_airports_are_not_strict_iata_codes", func(t *testing.T) {
+		c, err := controllers.NewFlightTracker(logger, mockMediator, auditor)
+		require.NoError(t, err)
+
+		request := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewReader([]byte(`{"flights": [["sfo", "ATL"]]}`)))
+		request = request.WithContext(tenancy.NewContext(request.Context(), tenancy.Tenant{ID: "acme", StrictAirports: true}))
+		recorder := httptest.NewRecorder()
+
+		c.GetPath(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("failure_response_when_the_legs_are_invalid", func(t *testing.T) {
+		c, err := controllers.NewFlightTracker(logger, mockMediator, auditor)
+		require.NoError(t, err)
+
+		bodies := map[string]string{
+			"flight number without carrier": `{"legs": [{"origin": "SFO", "destination": "ATL", "flightNumber": "88"}]}`,
+			"unknown cabin":                 `{"legs": [{"origin": "SFO", "destination": "ATL", "cabin": "lounge"}]}`,
+			"legs along with flights":       `{"flights": [["SFO", "ATL"]], "legs": [{"origin": "SFO", "destination": "ATL"}]}`,
+			"departure without arrival":     `{"legs": [{"origin": "SFO", "destination": "ATL", "departure": "2023-06-13T08:00"}]}`,
+			"arrival before departure":      `{"legs": [{"origin": "SFO", "destination": "ATL", "departure": "2023-06-13T08:00", "arrival": "2023-06-13T10:00"}]}`,
+			"invalid time":                  `{"legs": [{"origin": "SFO", "destination": "ATL", "departure": "2023-06-13 08:00", "arrival": "2023-06-13T18:00"}]}`,
+		}
+		for name, body := range bodies {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewReader([]byte(body)))
+
+			c.GetPath(recorder, request)
+
+			assert.Equal(t, http.StatusBadRequest, recorder.Code, name)
+		}
+	})
+
+	t.Run("failure_response_when_csv_is_not_enabled_for_the_tenant", func(t *testing.T) {
+		c, err := controllers.NewFlightTracker(logger, mockMediator, auditor)
+		require.NoError(t, err)
+
+		request := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewReader([]byte("SFO,ATL\n")))
+		request.Header.Set("Content-Type", "text/csv")
+		request = request.WithContext(tenancy.NewContext(request.Context(), tenancy.Tenant{ID: "acme", Features: []string{tenancy.FeatureJobs}}))
+		recorder := httptest.NewRecorder()
+
+		c.GetPath(recorder, request)
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+}
+
+func newAuditor(t *testing.T) *audit.Auditor {
+	sink, err := audit.NewMemorySink(100)
+	require.NoError(t, err)
+	auditor, err := audit.NewAuditor(log.NewEntry(log.New()), sink)
+	require.NoError(t, err)
+	return auditor
+}
+
+func TestController_GetPath_Audit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		logger       = log.NewEntry(log.New())
+		mockMediator = mock_flightTracker_mediator.NewMockFlightTracker(ctrl)
+		identity     = auth.Identity{Subject: "agent", Method: auth.MethodAPIKey}
+	)
+
+	sink, err := audit.NewMemorySink(10)
+	require.NoError(t, err)
+	auditor, err := audit.NewAuditor(logger, sink)
+	require.NoError(t, err)
+
+	c, err := controllers.NewFlightTracker(logger, mockMediator, auditor)
+	require.NoError(t, err)
+
+	serve := func(body string) {
+		request := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewReader([]byte(body)))
+		ctx := auth.NewContext(request.Context(), identity)
+		request = request.WithContext(tenancy.NewContext(ctx, tenancy.Tenant{ID: "acme"}))
+		c.GetPath(httptest.NewRecorder(), request)
+	}
+
+	mockMediator.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(dto.Path{
+		Airports: []string{"SFO", "ATL"},
+	}, nil)
+	mockMediator.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(dto.Path{}, errors.New("no initial flight found"))
+
+	serve(`{"flights": [["SFO", "ATL"]], "userId": "u-1"}`)
+	serve(`{"flights": [["SFO", "SFO"]], "userId": "u-1"}`)
+	serve(`{"flights": `)
+
+	entries, err := sink.Query(context.Background(), audit.Filter{})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(entries))
+
+	for _, entry := range entries {
+		assert.Equal(t, "api_key:agent", entry.Actor)
+		assert.Equal(t, "acme", entry.Tenant)
+		assert.Equal(t, audit.ActionCalculate, entry.Action)
+	}
+
+	assert.Equal(t, "u-1", entries[0].UserID)
+	assert.Equal(t, audit.OutcomeSuccess, entries[0].Outcome)
+	assert.Equal(t, http.StatusOK, entries[0].Status)
+	assert.Equal(t, 64, len(entries[0].RequestHash))
+
+	assert.Equal(t, audit.OutcomeFailure, entries[1].Outcome)
+	assert.Equal(t, http.StatusNotFound, entries[1].Status)
+	assert.Assert(t, entries[0].RequestHash != entries[1].RequestHash)
+
+	assert.Equal(t, http.StatusBadRequest, entries[2].Status)
+	assert.Equal(t, "", entries[2].RequestHash)
 }
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/controllers/translators"
+	"github.com/volume/service/user-flight-tracking/jobqueue"
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/mediators"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/privacy"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+// Jobs defines the methods for asynchronous calculations
+type Jobs interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	Get(w http.ResponseWriter, r *http.Request)
+	GetResult(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+}
+
+// jobs defines the components for the controller
+type jobs struct {
+	Logger                *log.Entry
+	FlightTrackerMediator mediators.FlightTracker
+	Queue                 *jobqueue.Queue
+	Auditor               audit.Recorder
+}
+
+// jobResult is the result of a calculation job, with its request for the audit of the retrievals
+type jobResult struct {
+	Request  models.PathRequest
+	Response models.PathResponse
+}
+
+// NewJobs returns a new instance of Jobs controller
+func NewJobs(log *log.Entry, flightTrackerMediator mediators.FlightTracker, queue *jobqueue.Queue, auditor audit.Recorder) (Jobs, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case flightTrackerMediator == nil:
+		return nil, errors.New("flightTrackerMediator")
+	case queue == nil:
+		return nil, errors.New("queue")
+	case auditor == nil:
+		return nil, errors.New("auditor")
+	}
+
+	return &jobs{
+		Logger:                log,
+		FlightTrackerMediator: flightTrackerMediator,
+		Queue:                 queue,
+		Auditor:               auditor,
+	}, nil
+}
+
+// Create enqueues the calculation of a flight path and answers with the new job
+func (c *jobs) Create(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromContext(r.Context(), c.Logger)
+
+	var request models.PathRequest
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	w = sw
+	defer func() { c.Auditor.Record(r.Context(), pathRequestEntry(audit.ActionCalculate, request, sw.status)) }()
+
+	request, ok := readPathRequest(w, r, logger)
+	if !ok {
+		return
+	}
+
+	subject := privacy.Subject(r.Context(), request.UserID)
+	job, err := c.Queue.Submit(r.Context(), tenancy.Owner(r.Context()), subject, func(ctx context.Context) (interface{}, error) {
+		ctx = mediators.WithProgress(ctx, func(percent int) { jobqueue.ReportProgress(ctx, percent) })
+		path, err := c.FlightTrackerMediator.GetFlightsPath(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		return jobResult{Request: request, Response: translators.PathDTOtoModel(path)}, nil
+	})
+	if err != nil {
+		logger.WithError(err).Error("error enqueuing job")
+		if errors.Is(err, jobqueue.ErrQueueFull) || errors.Is(err, jobqueue.ErrClosed) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	logging.AddFields(r.Context(), log.Fields{logging.FieldJobID: job.ID})
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, translators.JobToModel(job))
+}
+
+// Get answers with the status and progress of a job
+func (c *jobs) Get(w http.ResponseWriter, r *http.Request) {
+	job, ok := c.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, translators.JobToModel(job))
+}
+
+// GetResult answers with the flight path of a succeeded job
+func (c *jobs) GetResult(w http.ResponseWriter, r *http.Request) {
+	// the request of the job is only kept with its result, the other retrievals are audited without it
+	var request models.PathRequest
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	w = sw
+	defer func() { c.Auditor.Record(r.Context(), pathRequestEntry(audit.ActionCalculate, request, sw.status)) }()
+
+	job, ok := c.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	switch job.Status {
+	case jobqueue.StatusSucceeded:
+		result := job.Result.(jobResult)
+		request = result.Request
+		if err := writeCacheableJSON(w, r, result.Response); err != nil {
+			logging.FromContext(r.Context(), c.Logger).WithError(err).Error("error encoding JSON")
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	case jobqueue.StatusFailed:
+		http.Error(w, "Not Found", http.StatusNotFound)
+	default:
+		http.Error(w, "Job Not Finished", http.StatusConflict)
+	}
+}
+
+// Delete cancels a pending job and discards it with its result
+func (c *jobs) Delete(w http.ResponseWriter, r *http.Request) {
+	job, ok := c.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.Queue.Delete(job.ID); err != nil && !errors.Is(err, jobqueue.ErrNotFound) {
+		logging.FromContext(r.Context(), c.Logger).WithError(err).Error("error deleting job")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// lookup returns the job of the request path, answering 404 Not Found when it doesn't exist or belongs to another caller
+func (c *jobs) lookup(w http.ResponseWriter, r *http.Request) (jobqueue.Job, bool) {
+	id := mux.Vars(r)["id"]
+	logging.AddFields(r.Context(), log.Fields{logging.FieldJobID: id})
+
+	job, err := c.Queue.Get(id)
+	if err == nil && job.Owner != tenancy.Owner(r.Context()) {
+		err = jobqueue.ErrNotFound
+	}
+	if err != nil {
+		logging.FromContext(r.Context(), c.Logger).WithError(err).Debug("job not available")
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return jobqueue.Job{}, false
+	}
+
+	return job, true
+}
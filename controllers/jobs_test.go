@@ -0,0 +1,257 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/controllers"
+	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/jobqueue"
+	"github.com/volume/service/user-flight-tracking/mediators"
+	mock_flightTracker_mediator "github.com/volume/service/user-flight-tracking/mocks/mockmediators"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+func TestController_NewJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		logger       = log.NewEntry(nil)
+		mockMediator = mock_flightTracker_mediator.NewMockFlightTracker(ctrl)
+		queue        = &jobqueue.Queue{}
+		auditor      = newAuditor(t)
+	)
+
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		mediator  mediators.FlightTracker
+		queue     *jobqueue.Queue
+		auditor   audit.Recorder
+		wantError error
+	}{
+		{name: "should_return_success", logger: logger, mediator: mockMediator, queue: queue, auditor: auditor},
+		{name: "should_return_error_when_the_logger_is_nil", mediator: mockMediator, queue: queue, auditor: auditor, wantError: errors.New("logger")},
+		{name: "should_return_error_when_the_mediator_is_nil", logger: logger, queue: queue, auditor: auditor, wantError: errors.New("flightTrackerMediator")},
+		{name: "should_return_error_when_the_queue_is_nil", logger: logger, mediator: mockMediator, auditor: auditor, wantError: errors.New("queue")},
+		{name: "should_return_error_when_the_auditor_is_nil", logger: logger, mediator: mockMediator, queue: queue, wantError: errors.New("auditor")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := controllers.NewJobs(tt.logger, tt.mediator, tt.queue, tt.auditor)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestController_Jobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		logger       = log.NewEntry(log.New())
+		mockMediator = mock_flightTracker_mediator.NewMockFlightTracker(ctrl)
+		owner        = auth.Identity{Subject: "batch-job", Method: auth.MethodAPIKey}
+	)
+
+	queue, err := jobqueue.NewQueue(logger, 1, 10, time.Minute)
+	require.NoError(t, err)
+	defer func() { _ = queue.Shutdown(context.Background()) }()
+
+	sink, err := audit.NewMemorySink(100)
+	require.NoError(t, err)
+	auditor, err := audit.NewAuditor(logger, sink)
+	require.NoError(t, err)
+
+	c, err := controllers.NewJobs(logger, mockMediator, queue, auditor)
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/jobs", c.Create).Methods(http.MethodPost)
+	router.HandleFunc("/jobs/{id}", c.Get).Methods(http.MethodGet)
+	router.HandleFunc("/jobs/{id}", c.Delete).Methods(http.MethodDelete)
+	router.HandleFunc("/jobs/{id}/result", c.GetResult).Methods(http.MethodGet)
+
+	serve := func(method, target, body string, identity *auth.Identity) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		if identity != nil {
+			request = request.WithContext(auth.NewContext(request.Context(), *identity))
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	submit := func(t *testing.T) models.JobResponse {
+		recorder := serve(http.MethodPost, "/jobs", `{"flights": [["ATL", "EWR"], ["SFO", "ATL"]]}`, &owner)
+		require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+
+		var job models.JobResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &job))
+		assert.Equal(t, "/jobs/"+job.ID, recorder.Header().Get("Location"))
+		return job
+	}
+
+	waitStatus := func(t *testing.T, id string) models.JobResponse {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			recorder := serve(http.MethodGet, "/jobs/"+id, "", &owner)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var job models.JobResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &job))
+			if job.Status != string(jobqueue.StatusQueued) && job.Status != string(jobqueue.StatusRunning) {
+				return job
+			}
+			require.True(t, time.Now().Before(deadline), "job %s did not finish", id)
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	t.Run("should_return_the_result_of_a_finished_job", func(t *testing.T) {
+		mockMediator.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(dto.Path{
+			Airports: []string{"SFO", "ATL", "EWR"},
+		}, nil)
+
+		job := submit(t)
+		finished := waitStatus(t, job.ID)
+		assert.Equal(t, string(jobqueue.StatusSucceeded), finished.Status)
+		assert.Equal(t, 100, finished.Progress)
+		assert.Assert(t, finished.ExpiresAt != nil)
+
+		recorder := serve(http.MethodGet, "/jobs/"+job.ID+"/result", "", &owner)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var path models.PathResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &path))
+		assert.DeepEqual(t, []string{"SFO", "ATL", "EWR"}, path.Path)
+
+		// the creation and the retrieval of the result are both audited with the request
+		entries, err := sink.Query(context.Background(), audit.Filter{Action: audit.ActionCalculate})
+		require.NoError(t, err)
+		require.Equal(t, 2, len(entries))
+		creation, retrieval := entries[0], entries[1]
+		assert.Equal(t, http.StatusOK, retrieval.Status)
+		assert.Equal(t, "api_key:batch-job", retrieval.Actor)
+		assert.Equal(t, http.StatusAccepted, creation.Status)
+		assert.Equal(t, 64, len(retrieval.RequestHash))
+		assert.Equal(t, creation.RequestHash, retrieval.RequestHash)
+	})
+
+	t.Run("should_return_not_modified_when_the_etag_of_the_result_matches", func(t *testing.T) {
+		mockMediator.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(dto.Path{
+			Airports: []string{"SFO", "ATL", "EWR"},
+		}, nil)
+
+		job := submit(t)
+		waitStatus(t, job.ID)
+		first := serve(http.MethodGet, "/jobs/"+job.ID+"/result", "", &owner)
+		etag := first.Header().Get("ETag")
+		assert.Assert(t, etag != "", "the result must carry an ETag")
+
+		request := httptest.NewRequest(http.MethodGet, "/jobs/"+job.ID+"/result", nil)
+		request = request.WithContext(auth.NewContext(request.Context(), owner))
+		request.Header.Set("If-None-Match", `"stale", W/`+etag)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusNotModified, recorder.Code)
+		assert.Equal(t, 0, recorder.Body.Len())
+	})
+
+	t.Run("failure_response_when_the_job_failed", func(t *testing.T) {
+		mockMediator.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(dto.Path{}, errors.New("no initial flight found"))
+
+		job := submit(t)
+		finished := waitStatus(t, job.ID)
+		assert.Equal(t, string(jobqueue.StatusFailed), finished.Status)
+		assert.Equal(t, "no initial flight found", finished.Error)
+
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/jobs/"+job.ID+"/result", "", &owner).Code)
+	})
+
+	t.Run("failure_response_when_the_job_has_not_finished", func(t *testing.T) {
+		release := make(chan struct{})
+		mockMediator.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, models.PathRequest) (dto.Path, error) {
+				<-release
+				return dto.Path{}, errors.New("canceled")
+			})
+
+		job := submit(t)
+		assert.Equal(t, http.StatusConflict, serve(http.MethodGet, "/jobs/"+job.ID+"/result", "", &owner).Code)
+
+		close(release)
+		waitStatus(t, job.ID)
+	})
+
+	t.Run("failure_response_when_the_job_belongs_to_another_caller", func(t *testing.T) {
+		mockMediator.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(dto.Path{
+			Airports: []string{"SFO", "ATL", "EWR"},
+		}, nil)
+
+		job := submit(t)
+		other := auth.Identity{Subject: "other", Method: auth.MethodAPIKey}
+
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/jobs/"+job.ID, "", &other).Code)
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/jobs/"+job.ID, "", nil).Code)
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/jobs/"+job.ID, "", &other).Code)
+		waitStatus(t, job.ID)
+	})
+
+	t.Run("failure_response_when_the_job_belongs_to_another_tenant", func(t *testing.T) {
+		mockMediator.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(dto.Path{
+			Airports: []string{"SFO", "ATL", "EWR"},
+		}, nil)
+
+		job := submit(t)
+
+		// the same credentials acting for a tenant don't reach the jobs created outside of it
+		request := httptest.NewRequest(http.MethodGet, "/jobs/"+job.ID, nil)
+		ctx := auth.NewContext(request.Context(), owner)
+		request = request.WithContext(tenancy.NewContext(ctx, tenancy.Tenant{ID: "acme"}))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		waitStatus(t, job.ID)
+	})
+
+	t.Run("should_discard_a_deleted_job", func(t *testing.T) {
+		mockMediator.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(dto.Path{
+			Airports: []string{"SFO", "ATL", "EWR"},
+		}, nil)
+
+		job := submit(t)
+		waitStatus(t, job.ID)
+
+		assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/jobs/"+job.ID, "", &owner).Code)
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/jobs/"+job.ID, "", &owner).Code)
+	})
+
+	t.Run("failure_response_when_bad_request", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/jobs", `{"flights": [["SFO"]]}`, &owner).Code)
+	})
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/controllers/translators"
+	"github.com/volume/service/user-flight-tracking/idempotency"
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/mediators"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/reconcile"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+// Reconciliation defines the methods for the reconciliation of the itineraries reported by several sources
+type Reconciliation interface {
+	Reconcile(w http.ResponseWriter, r *http.Request)
+}
+
+// reconciliationController defines the components for the controller
+type reconciliationController struct {
+	Logger                *log.Entry
+	FlightTrackerMediator mediators.FlightTracker
+	Auditor               audit.Recorder
+}
+
+// NewReconciliation returns a new instance of Reconciliation controller
+func NewReconciliation(log *log.Entry, flightTrackerMediator mediators.FlightTracker, auditor audit.Recorder) (Reconciliation, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case flightTrackerMediator == nil:
+		return nil, errors.New("flightTrackerMediator")
+	case auditor == nil:
+		return nil, errors.New("auditor")
+	}
+
+	return &reconciliationController{
+		Logger:                log,
+		FlightTrackerMediator: flightTrackerMediator,
+		Auditor:               auditor,
+	}, nil
+}
+
+// Reconcile merges the legs of a traveler reported by several sources, reconstructs the path of the consensus and
+// compares every source with it. The consensus legs not forming a single path don't fail the request, the
+// comparison is answered without the path.
+func (c *reconciliationController) Reconcile(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromContext(r.Context(), c.Logger)
+	logger.WithField("url", r.URL.Path).Debug("request")
+
+	// the audit log identifies the reconciliation by the request of its consensus path
+	var consensus models.PathRequest
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	w = sw
+	defer func() { c.Auditor.Record(r.Context(), pathRequestEntry(audit.ActionReconcile, consensus, sw.status)) }()
+
+	var request models.ReconcileRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.WithError(err).Error("error decoding request")
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	// Validate the request, with the airport rules of the tenant
+	validate := request.Validate
+	if tenant, _ := tenancy.FromContext(r.Context()); tenant.StrictAirports {
+		validate = request.ValidateStrict
+	}
+	if err := validate(); err != nil {
+		logger.WithField("sources", len(request.Sources)).WithError(err).Error("error validating request")
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	idempotency.Track(r.Context(), request.UserID)
+
+	result := reconcile.Reconcile(translators.SourcesToReconcile(request))
+	consensus = result.Request(request.UserID)
+	logger = logger.WithField("sources", len(request.Sources)).WithField(logging.FieldLegs, len(result.Legs))
+
+	reconciliation := translators.ReconciliationToModel(result)
+	path, err := c.FlightTrackerMediator.GetFlightsPath(r.Context(), consensus)
+	if err != nil {
+		logger.WithError(err).Warn("consensus path could not be reconstructed")
+		reconciliation.PathError = err.Error()
+	} else {
+		response := translators.PathDTOtoModel(path)
+		reconciliation.Path = &response
+	}
+
+	logger.Info("itineraries reconciled")
+	writeJSON(w, http.StatusOK, reconciliation)
+}
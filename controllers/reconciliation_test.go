@@ -0,0 +1,146 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/controllers"
+	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/mediators"
+	mock_flightTracker_mediator "github.com/volume/service/user-flight-tracking/mocks/mockmediators"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+func TestController_NewReconciliation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		mockMediator = mock_flightTracker_mediator.NewMockFlightTracker(ctrl)
+		auditor      = newAuditor(t)
+	)
+
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		mediator  mediators.FlightTracker
+		auditor   audit.Recorder
+		wantError error
+	}{
+		{name: "should_return_success", logger: log.NewEntry(nil), mediator: mockMediator, auditor: auditor},
+		{name: "should_return_error_when_the_logger_is_nil", mediator: mockMediator, auditor: auditor, wantError: errors.New("logger")},
+		{name: "should_return_error_when_the_mediator_is_nil", logger: log.NewEntry(nil), auditor: auditor, wantError: errors.New("flightTrackerMediator")},
+		{name: "should_return_error_when_the_auditor_is_nil", logger: log.NewEntry(nil), mediator: mockMediator, wantError: errors.New("auditor")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := controllers.NewReconciliation(tt.logger, tt.mediator, tt.auditor)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestController_ReconciliationReconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMediator := mock_flightTracker_mediator.NewMockFlightTracker(ctrl)
+	c, err := controllers.NewReconciliation(log.NewEntry(log.New()), mockMediator, newAuditor(t))
+	require.NoError(t, err)
+
+	reconcile := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		c.Reconcile(recorder, httptest.NewRequest(http.MethodPost, "/reconcile", bytes.NewReader([]byte(body))))
+		return recorder
+	}
+
+	t.Run("should_return_the_consensus_path_and_the_diffs", func(t *testing.T) {
+		mockMediator.EXPECT().GetFlightsPath(gomock.Any(), models.PathRequest{
+			Legs:   []models.Leg{{Origin: "SFO", Destination: "ATL", Carrier: "UA"}, {Origin: "ATL", Destination: "GSO"}},
+			UserID: "u-1",
+		}).Return(dto.Path{
+			Airports: []string{"SFO", "ATL", "GSO"},
+			Legs:     []dto.Leg{{Origin: "SFO", Destination: "ATL", Carrier: "UA"}, {Origin: "ATL", Destination: "GSO"}},
+		}, nil)
+
+		recorder := reconcile(`{"userId": "u-1", "sources": [
+			{"name": "booking", "legs": [{"origin": "SFO", "destination": "ATL", "carrier": "UA"}]},
+			{"name": "expenses", "legs": [{"origin": "ATL", "destination": "GSO"}, {"origin": "SFO", "destination": "ATL"}]}
+		]}`)
+
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		var response models.Reconciliation
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		require.NotNil(t, response.Path)
+		assert.DeepEqual(t, []string{"SFO", "ATL", "GSO"}, response.Path.Path)
+		assert.DeepEqual(t, []models.ReconciledLeg{
+			{Leg: models.Leg{Origin: "SFO", Destination: "ATL", Carrier: "UA"}, Status: "matching", Sources: []string{"booking", "expenses"}},
+			{Leg: models.Leg{Origin: "ATL", Destination: "GSO"}, Status: "source_only", Sources: []string{"expenses"}},
+		}, response.Legs)
+		assert.DeepEqual(t, []models.SourceDiff{
+			{Name: "booking", Matching: []int{0}, Conflicting: []models.LegConflict{}, Only: []int{}, Missing: []int{1}},
+			{Name: "expenses", Matching: []int{1}, Conflicting: []models.LegConflict{}, Only: []int{0}, Missing: []int{}},
+		}, response.Sources)
+	})
+
+	t.Run("should_return_the_diffs_when_the_consensus_has_no_path", func(t *testing.T) {
+		mockMediator.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).
+			Return(dto.Path{}, errors.New("disconnections detected between flights: [GSO IND]"))
+
+		recorder := reconcile(`{"sources": [
+			{"name": "booking", "legs": [{"origin": "SFO", "destination": "ATL"}]},
+			{"name": "airline", "legs": [{"origin": "GSO", "destination": "IND"}]}
+		]}`)
+
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		var response models.Reconciliation
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Assert(t, response.Path == nil)
+		assert.Equal(t, "disconnections detected between flights: [GSO IND]", response.PathError)
+		assert.Equal(t, 2, len(response.Sources))
+	})
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "failure_response_when_the_body_is_invalid", body: `{"sources": `},
+		{name: "failure_response_when_a_single_source_is_given", body: `{"sources": [{"name": "booking", "legs": [{"origin": "SFO", "destination": "ATL"}]}]}`},
+		{name: "failure_response_when_a_source_is_given_twice", body: `{"sources": [
+			{"name": "booking", "legs": [{"origin": "SFO", "destination": "ATL"}]},
+			{"name": "booking", "legs": [{"origin": "ATL", "destination": "GSO"}]}
+		]}`},
+		{name: "failure_response_when_a_source_has_no_legs", body: `{"sources": [
+			{"name": "booking", "legs": [{"origin": "SFO", "destination": "ATL"}]},
+			{"name": "airline", "legs": []}
+		]}`},
+		{name: "failure_response_when_a_leg_is_invalid", body: `{"sources": [
+			{"name": "booking", "legs": [{"origin": "SFO", "destination": "ATL"}]},
+			{"name": "airline", "legs": [{"origin": "SFO", "destination": "ATLANTA"}]}
+		]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := reconcile(tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/controllers/translators"
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/network"
+)
+
+const (
+	// defaultRoutes is the number of routes suggested when the request sets no k
+	defaultRoutes = 3
+	// maxRoutes bounds the number of routes suggested by a request
+	maxRoutes = 10
+	// defaultMaxStops is the number of connections of the routes when the request sets no maxStops
+	defaultMaxStops = 2
+	// maxMaxStops bounds the connections of the routes, and the states of the searches with them
+	maxMaxStops = 4
+)
+
+// Routes defines the methods for the suggestion of routes between airports
+type Routes interface {
+	Suggest(w http.ResponseWriter, r *http.Request)
+}
+
+// routes defines the components for the controller
+type routes struct {
+	Logger  *log.Entry
+	Network *network.Network
+}
+
+// NewRoutes returns a new instance of Routes controller
+func NewRoutes(log *log.Entry, network *network.Network) (Routes, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case network == nil:
+		return nil, errors.New("network")
+	}
+
+	return &routes{
+		Logger:  log,
+		Network: network,
+	}, nil
+}
+
+// Suggest answers with the shortest routes of the known network between the airports of the query parameters
+func (c *routes) Suggest(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromContext(r.Context(), c.Logger)
+
+	query, err := routesQuery(r)
+	if err != nil {
+		logger.WithError(err).Warn("invalid routes query")
+		http.Error(w, "Bad Request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, airport := range []string{query.From, query.To} {
+		if !c.Network.Contains(airport) {
+			http.Error(w, "Not Found: no known route from or to "+airport, http.StatusNotFound)
+			return
+		}
+	}
+
+	suggested := c.Network.Suggest(query)
+	logger.WithField("routes", len(suggested)).Debug("routes suggested")
+	writeJSON(w, http.StatusOK, translators.RoutesToModel(query, suggested))
+}
+
+// routesQuery reads the query of the routes from the query parameters
+func routesQuery(r *http.Request) (network.Query, error) {
+	values := r.URL.Query()
+	query := network.Query{
+		From:     values.Get("from"),
+		To:       values.Get("to"),
+		K:        defaultRoutes,
+		By:       values.Get("by"),
+		MaxStops: defaultMaxStops,
+	}
+
+	switch {
+	case query.From == "" || query.To == "":
+		return network.Query{}, errors.New("from and to are required")
+	case query.From == query.To:
+		return network.Query{}, errors.New("from and to must be different airports")
+	}
+	switch query.By {
+	case "":
+		query.By = network.ByHops
+	case network.ByHops, network.ByDistance:
+	default:
+		return network.Query{}, errors.New("by must be " + network.ByHops + " or " + network.ByDistance)
+	}
+	if value := values.Get("k"); value != "" {
+		k, err := strconv.Atoi(value)
+		if err != nil || k <= 0 || k > maxRoutes {
+			return network.Query{}, errors.New("k must be between 1 and " + strconv.Itoa(maxRoutes))
+		}
+		query.K = k
+	}
+	if value := values.Get("maxStops"); value != "" {
+		stops, err := strconv.Atoi(value)
+		if err != nil || stops < 0 || stops > maxMaxStops {
+			return network.Query{}, errors.New("maxStops must be between 0 and " + strconv.Itoa(maxMaxStops))
+		}
+		query.MaxStops = stops
+	}
+	if value := values.Get("exclude"); value != "" {
+		query.Exclude = strings.Split(value, ",")
+	}
+
+	return query, nil
+}
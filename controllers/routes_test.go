@@ -0,0 +1,85 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/controllers"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/network"
+)
+
+func TestController_NewRoutes(t *testing.T) {
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		network   *network.Network
+		wantError error
+	}{
+		{name: "should_return_success", logger: log.NewEntry(nil), network: network.New(nil)},
+		{name: "should_return_error_when_the_logger_is_nil", network: network.New(nil), wantError: errors.New("logger")},
+		{name: "should_return_error_when_the_network_is_nil", logger: log.NewEntry(nil), wantError: errors.New("network")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := controllers.NewRoutes(tt.logger, tt.network)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestController_RoutesSuggest(t *testing.T) {
+	c, err := controllers.NewRoutes(log.NewEntry(log.New()), network.New([][]string{
+		{"SFO", "ATL"}, {"ATL", "EWR"}, {"SFO", "XXX"}, {"XXX", "EWR"}, {"SFO", "DEN"}, {"DEN", "ORD"}, {"ORD", "EWR"},
+	}))
+	require.NoError(t, err)
+
+	suggest := func(query string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		c.Suggest(recorder, httptest.NewRequest(http.MethodGet, "/routes?"+query, nil))
+		return recorder
+	}
+
+	t.Run("should_return_the_routes", func(t *testing.T) {
+		recorder := suggest("from=SFO&to=EWR&k=2&exclude=ATL")
+
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		var suggestions models.RouteSuggestions
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &suggestions))
+		assert.Equal(t, "hops", suggestions.By)
+		require.Equal(t, 2, len(suggestions.Routes))
+		assert.DeepEqual(t, models.SuggestedRoute{Path: []string{"SFO", "XXX", "EWR"}, Stops: 1}, suggestions.Routes[0])
+		assert.DeepEqual(t, []string{"SFO", "DEN", "ORD", "EWR"}, suggestions.Routes[1].Path)
+		require.NotNil(t, suggestions.Routes[1].DistanceKm)
+	})
+
+	t.Run("should_return_no_route_beyond_the_maximum_stops", func(t *testing.T) {
+		recorder := suggest("from=SFO&to=EWR&by=distance&maxStops=0")
+
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		assert.Equal(t, `{"from":"SFO","to":"EWR","by":"distance","routes":[]}`+"\n", recorder.Body.String())
+	})
+
+	t.Run("failure_response_when_the_query_is_invalid", func(t *testing.T) {
+		for _, query := range []string{"from=SFO", "from=SFO&to=SFO", "from=SFO&to=EWR&by=time", "from=SFO&to=EWR&k=11", "from=SFO&to=EWR&maxStops=5"} {
+			assert.Equal(t, http.StatusBadRequest, suggest(query).Code, query)
+		}
+	})
+
+	t.Run("failure_response_when_the_airport_has_no_route", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, suggest("from=SFO&to=JFK").Code)
+	})
+}
@@ -0,0 +1,23 @@
+package translators
+
+import (
+	"github.com/volume/service/user-flight-tracking/anomaly"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// AnomalyReportToModel converts the analysis of an itinerary into a model object, and returns it.
+func AnomalyReportToModel(report anomaly.Report) models.AnomalyReport {
+	reasons := make([]models.AnomalyReason, len(report.Reasons))
+	for i, reason := range report.Reasons {
+		reasons[i] = models.AnomalyReason{
+			Kind:    reason.Kind,
+			Legs:    reason.Legs,
+			Message: reason.Message,
+		}
+	}
+
+	return models.AnomalyReport{
+		Score:   report.Score,
+		Reasons: reasons,
+	}
+}
@@ -0,0 +1,22 @@
+package translators
+
+import (
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// AuditEntryToModel converts an audit entry into a model object, and returns it.
+func AuditEntryToModel(entry audit.Entry) models.AuditEntryResponse {
+	return models.AuditEntryResponse{
+		ID:          entry.ID,
+		Time:        entry.Time,
+		RequestID:   entry.RequestID,
+		Actor:       entry.Actor,
+		Tenant:      entry.Tenant,
+		Action:      entry.Action,
+		UserID:      entry.UserID,
+		RequestHash: entry.RequestHash,
+		Outcome:     entry.Outcome,
+		Status:      entry.Status,
+	}
+}
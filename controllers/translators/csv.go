@@ -0,0 +1,129 @@
+package translators
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// maxCSVErrors limits the row errors reported for a single upload
+const maxCSVErrors = 100
+
+// CSVRowErrors is returned when some rows of a CSV upload are invalid
+type CSVRowErrors []models.FieldError
+
+func (e CSVRowErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "invalid csv"
+	case 1:
+		return fmt.Sprintf("invalid csv: row %d: %s: %s", e[0].Row, e[0].Field, e[0].Message)
+	default:
+		return fmt.Sprintf("invalid csv: row %d: %s: %s (and %d more)", e[0].Row, e[0].Field, e[0].Message, len(e)-1)
+	}
+}
+
+// CSVToPathRequest converts a CSV upload with one flight per row into a request.
+// The columns are detected from an optional header, without it the first two columns are the origin and the destination.
+func CSVToPathRequest(r io.Reader) (models.PathRequest, error) {
+	records, err := models.NewCSVReader(r).ReadAll()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return models.PathRequest{}, CSVRowErrors{{Row: parseErr.Line, Field: "row", Message: parseErr.Err.Error()}}
+		}
+		return models.PathRequest{}, err
+	}
+	if len(records) == 0 {
+		return models.PathRequest{}, CSVRowErrors{{Row: 1, Field: "row", Message: "the file has no flights"}}
+	}
+
+	columns, hasHeader := models.DetectCSVColumns(records[0])
+	first := 1
+	if hasHeader {
+		records = records[1:]
+		first = 2
+	}
+
+	var (
+		req      models.PathRequest
+		rowErrs  CSVRowErrors
+		userRow  int
+		hasDates bool
+	)
+	addError := func(row int, field, message string) {
+		if len(rowErrs) < maxCSVErrors {
+			rowErrs = append(rowErrs, models.FieldError{Row: row, Field: field, Message: message})
+		}
+	}
+
+	for i, record := range records {
+		row := first + i
+
+		origin, destination := column(record, columns.Origin), column(record, columns.Destination)
+		if msg := airportError(origin); msg != "" {
+			addError(row, "origin", msg)
+		}
+		if msg := airportError(destination); msg != "" {
+			addError(row, "destination", msg)
+		}
+		req.Flights = append(req.Flights, []string{origin, destination})
+
+		if user := column(record, columns.User); user != "" {
+			switch {
+			case req.UserID == "":
+				req.UserID, userRow = user, row
+			case req.UserID != user:
+				addError(row, "user", fmt.Sprintf("user %q differs from user %q of row %d", user, req.UserID, userRow))
+			}
+		}
+
+		date := column(record, columns.Date)
+		if date != "" {
+			hasDates = true
+			if _, err := time.Parse(models.DateLayout, date); err != nil {
+				addError(row, "date", "the date must have the format YYYY-MM-DD")
+			}
+		}
+		req.Dates = append(req.Dates, date)
+	}
+
+	if len(req.Flights) == 0 {
+		addError(first, "row", "the file has no flights")
+	}
+	if !hasDates {
+		req.Dates = nil
+	}
+	if len(rowErrs) > 0 {
+		return models.PathRequest{}, rowErrs
+	}
+
+	return req, nil
+}
+
+// airportError applies the rules of models.PathRequest to a single airport
+func airportError(airport string) string {
+	switch {
+	case airport == "":
+		return "the airport is required"
+	case strings.IndexFunc(airport, unicode.IsSpace) >= 0:
+		return "the airport must not contain spaces"
+	case utf8.RuneCountInString(airport) != 3:
+		return "the airport must have exactly 3 characters"
+	}
+	return ""
+}
+
+func column(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
@@ -0,0 +1,91 @@
+package translators_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/controllers/translators"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+func TestTranslator_CSVToPathRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		csv         string
+		wantRequest models.PathRequest
+		wantErrors  translators.CSVRowErrors
+	}{
+		{
+			name: "should_detect_the_columns_from_the_header",
+			csv:  "Date,Traveler,To,From\n2023-06-13,u-1,ATL,SFO\n2023-06-14,u-1,EWR,ATL\n",
+			wantRequest: models.PathRequest{
+				Flights: [][]string{{"SFO", "ATL"}, {"ATL", "EWR"}},
+				UserID:  "u-1",
+				Dates:   []string{"2023-06-13", "2023-06-14"},
+			},
+		},
+		{
+			name: "should_detect_the_departure_and_arrival_columns",
+			csv:  "departure,arrival\nSFO,ATL\n",
+			wantRequest: models.PathRequest{
+				Flights: [][]string{{"SFO", "ATL"}},
+			},
+		},
+		{
+			name: "should_use_the_first_two_columns_without_header",
+			csv:  "SFO, ATL\nATL, EWR\n",
+			wantRequest: models.PathRequest{
+				Flights: [][]string{{"SFO", "ATL"}, {"ATL", "EWR"}},
+			},
+		},
+		{
+			name: "should_report_every_invalid_row_with_its_line",
+			csv:  "origin,destination,date\nSFO,ATL,2023-06-13\nS O,EWR,2023-06-14\nEWR,,13/06/2023\n",
+			wantErrors: translators.CSVRowErrors{
+				{Row: 3, Field: "origin", Message: "the airport must not contain spaces"},
+				{Row: 4, Field: "destination", Message: "the airport is required"},
+				{Row: 4, Field: "date", Message: "the date must have the format YYYY-MM-DD"},
+			},
+		},
+		{
+			name: "should_report_rows_of_a_different_user",
+			csv:  "user,origin,destination\nu-1,SFO,ATL\nu-2,ATL,EWR\n",
+			wantErrors: translators.CSVRowErrors{
+				{Row: 3, Field: "user", Message: `user "u-2" differs from user "u-1" of row 2`},
+			},
+		},
+		{
+			name: "should_report_a_file_without_flights",
+			csv:  "origin,destination\n",
+			wantErrors: translators.CSVRowErrors{
+				{Row: 2, Field: "row", Message: "the file has no flights"},
+			},
+		},
+		{
+			name: "should_report_malformed_csv",
+			csv:  "origin,destination\nSFO,\"ATL\n",
+			wantErrors: translators.CSVRowErrors{
+				{Row: 2, Field: "row", Message: "extraneous or missing \" in quoted-field"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := translators.CSVToPathRequest(strings.NewReader(tt.csv))
+			if tt.wantErrors != nil {
+				var rowErrs translators.CSVRowErrors
+				require.True(t, errors.As(err, &rowErrs), "should return row errors, got %v", err)
+				assert.DeepEqual(t, tt.wantErrors, rowErrs)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.DeepEqual(t, tt.wantRequest, request)
+		})
+	}
+}
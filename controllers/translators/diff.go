@@ -0,0 +1,65 @@
+package translators
+
+import (
+	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// PathDiffToModel converts the differences between two paths into a model object, and returns it.
+func PathDiffToModel(diff dto.PathDiff) models.ItineraryDiff {
+	response := models.ItineraryDiff{
+		Old:       PathDTOtoModel(diff.Old),
+		New:       PathDTOtoModel(diff.New),
+		Added:     legDiffsToModel(diff.Added),
+		Removed:   legDiffsToModel(diff.Removed),
+		Reordered: legDiffsToModel(diff.Reordered),
+		Changed:   legDiffsToModel(diff.Changed),
+	}
+	if diff.StartChanged {
+		response.Start = &models.AirportChange{Old: response.Old.Start, New: response.New.Start}
+	}
+	if diff.EndChanged {
+		response.End = &models.AirportChange{Old: response.Old.End, New: response.New.End}
+	}
+
+	return response
+}
+
+// legDiffsToModel converts the legs of a diff, empty rather than nil to be encoded as an empty array
+func legDiffsToModel(legDiffs []dto.LegDiff) []models.LegDiff {
+	result := make([]models.LegDiff, len(legDiffs))
+	for i, legDiff := range legDiffs {
+		leg := legDiff.New
+		if legDiff.NewPosition < 0 {
+			leg = legDiff.Old
+		}
+		result[i] = models.LegDiff{
+			Origin:      leg.Origin,
+			Destination: leg.Destination,
+			OldPosition: position(legDiff.OldPosition),
+			NewPosition: position(legDiff.NewPosition),
+		}
+
+		old, updated := legDTOtoModel(legDiff.Old), legDTOtoModel(legDiff.New)
+		for _, field := range legDiff.Changes {
+			for j, name := range models.LegDetails {
+				if name == field {
+					result[i].Changes = append(result[i].Changes, models.DetailChange{
+						Field: field,
+						Old:   *old.Details()[j],
+						New:   *updated.Details()[j],
+					})
+				}
+			}
+		}
+	}
+	return result
+}
+
+// position returns the position of a leg in a path, nil when the leg is missing from it
+func position(p int) *int {
+	if p < 0 {
+		return nil
+	}
+	return &p
+}
@@ -0,0 +1,16 @@
+package translators
+
+import (
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/privacy"
+)
+
+// ErasureToModel converts the erasure of a traveler into a model object, and returns it.
+func ErasureToModel(erasure privacy.Erasure) models.ErasureResponse {
+	return models.ErasureResponse{
+		UserID:   erasure.UserID,
+		Tenant:   erasure.Tenant,
+		ErasedAt: erasure.Time,
+		Erased:   erasure.Erased,
+	}
+}
@@ -0,0 +1,33 @@
+package translators
+
+import (
+	"time"
+
+	"github.com/volume/service/user-flight-tracking/jobqueue"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// JobToModel converts a job snapshot into a model object, and returns it.
+func JobToModel(job jobqueue.Job) models.JobResponse {
+	response := models.JobResponse{
+		ID:         job.ID,
+		Status:     string(job.Status),
+		Progress:   job.Progress,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  optionalTime(job.StartedAt),
+		FinishedAt: optionalTime(job.FinishedAt),
+		ExpiresAt:  optionalTime(job.ExpiresAt),
+	}
+	if job.Err != nil {
+		response.Error = job.Err.Error()
+	}
+
+	return response
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
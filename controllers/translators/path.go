@@ -1,20 +1,79 @@
 package translators
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/volume/service/user-flight-tracking/dto"
 	"github.com/volume/service/user-flight-tracking/models"
 )
 
 // PathDTOtoModel converts a DTO object into a model object, and returns it.
 func PathDTOtoModel(path dto.Path) models.PathResponse {
-	var fullPath []string
-	for _, flight := range path.Flights {
-		fullPath = append(fullPath, flight.Name)
+	legs := make([]models.Leg, 0, len(path.Legs))
+	for _, leg := range path.Legs {
+		legs = append(legs, legDTOtoModel(leg))
+	}
+
+	response := models.PathResponse{
+		Start:    path.Airports[0],
+		End:      path.Airports[len(path.Airports)-1],
+		Path:     path.Airports,
+		Legs:     legs,
+		Warnings: ConnectionWarningsDTOtoModel(path.Warnings),
+	}
+	for _, d := range path.Duplicates {
+		response.Duplicates = append(response.Duplicates, models.DuplicateLegs{
+			Origin:      d.Origin,
+			Destination: d.Destination,
+			Legs:        d.Legs,
+			Merged:      d.Merged,
+			Conflicting: d.Conflicting,
+		})
 	}
+	if path.Timeline != nil {
+		timeline := TimelineDTOtoModel(*path.Timeline)
+		response.Timeline = &timeline
+	}
+
+	return response
+}
+
+// legDTOtoModel converts a leg of a path into a model object, and returns it.
+func legDTOtoModel(leg dto.Leg) models.Leg {
+	return models.Leg{
+		Origin:           leg.Origin,
+		Destination:      leg.Destination,
+		Carrier:          leg.Carrier,
+		FlightNumber:     leg.FlightNumber,
+		OperatingCarrier: leg.OperatingCarrier,
+		Cabin:            leg.Cabin,
+		BookingReference: leg.BookingReference,
+		Departure:        localTime(leg.Departure),
+		Arrival:          localTime(leg.Arrival),
+		Inferred:         leg.Inferred,
+	}
+}
+
+// DuplicateLegsToFieldErrors converts the duplicate legs of a rejected request into the errors of its repeated legs,
+// field is the name of the legs in the request, "flights" or "legs"
+func DuplicateLegsToFieldErrors(field string, duplicates []dto.DuplicateLegs) []models.FieldError {
+	var errs []models.FieldError
+	for _, d := range duplicates {
+		for _, position := range d.Legs[1:] {
+			errs = append(errs, models.FieldError{
+				Field:   fmt.Sprintf("%s[%d]", field, position),
+				Message: fmt.Sprintf("repeats the leg %s-%s of %s[%d]", d.Origin, d.Destination, field, d.Legs[0]),
+			})
+		}
+	}
+	return errs
+}
 
-	return models.PathResponse{
-		Start: path.Flights[0].Name,
-		End:   path.Flights[len(path.Flights)-1].Name,
-		Path:  fullPath,
+// localTime formats a time of a leg as given in the request, empty when the leg is not timed
+func localTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
 	}
+	return t.Format(models.LocalTimeLayout)
 }
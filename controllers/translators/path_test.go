@@ -2,6 +2,7 @@ package translators_test
 
 import (
 	"testing"
+	"time"
 
 	"gotest.tools/assert"
 
@@ -19,13 +20,7 @@ func TestTranslator_PathDTOtoModel(t *testing.T) {
 		{
 			name: "Successful translation",
 			pathDTO: dto.Path{
-				Flights: []*dto.Flight{
-					{Name: "SFO"},
-					{Name: "ATL"},
-					{Name: "GSO"},
-					{Name: "IND"},
-					{Name: "EWR"},
-				},
+				Airports: []string{"SFO", "ATL", "GSO", "IND", "EWR"},
 			},
 			pathModel: models.PathResponse{
 				Start: "SFO",
@@ -33,6 +28,19 @@ func TestTranslator_PathDTOtoModel(t *testing.T) {
 				Path:  []string{"SFO", "ATL", "GSO", "IND", "EWR"},
 			},
 		},
+		{
+			name: "Successful translation of the duplicate legs",
+			pathDTO: dto.Path{
+				Airports:   []string{"SFO", "ATL", "GSO", "IND", "EWR"},
+				Duplicates: []dto.DuplicateLegs{{Origin: "SFO", Destination: "ATL", Legs: []int{1, 4}, Merged: true, Conflicting: true}},
+			},
+			pathModel: models.PathResponse{
+				Start:      "SFO",
+				End:        "EWR",
+				Path:       []string{"SFO", "ATL", "GSO", "IND", "EWR"},
+				Duplicates: []models.DuplicateLegs{{Origin: "SFO", Destination: "ATL", Legs: []int{1, 4}, Merged: true, Conflicting: true}},
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -42,5 +50,39 @@ func TestTranslator_PathDTOtoModel(t *testing.T) {
 		assert.Equal(t, c.pathModel.Path[0], response.Path[0])
 		assert.Equal(t, c.pathModel.Path[4], response.Path[4])
 		assert.Equal(t, len(c.pathModel.Path), len(response.Path))
+		assert.DeepEqual(t, c.pathModel.Duplicates, response.Duplicates)
 	}
 }
+
+func TestTranslator_TimelineDTOtoModel(t *testing.T) {
+	pacific := time.FixedZone("PDT", -7*60*60)
+	eastern := time.FixedZone("EDT", -4*60*60)
+
+	timeline := translators.TimelineDTOtoModel(dto.Timeline{
+		Legs: []dto.TimelineLeg{
+			{
+				Origin:      "SFO",
+				Destination: "ATL",
+				Departure:   time.Date(2023, 6, 13, 7, 0, 0, 0, pacific),
+				Arrival:     time.Date(2023, 6, 13, 14, 30, 0, 0, eastern),
+				Block:       4*time.Hour + 30*time.Minute,
+			},
+			{
+				Origin:      "ATL",
+				Destination: "EWR",
+				Departure:   time.Date(2023, 6, 13, 17, 10, 0, 0, eastern),
+				Arrival:     time.Date(2023, 6, 13, 19, 25, 0, 0, eastern),
+				Block:       2*time.Hour + 15*time.Minute,
+				Layover:     2*time.Hour + 40*time.Minute,
+			},
+		},
+		Elapsed: 9*time.Hour + 25*time.Minute,
+	})
+
+	assert.Equal(t, 565, timeline.ElapsedMinutes)
+	assert.Equal(t, "2023-06-13T07:00:00-07:00", timeline.Legs[0].DepartureLocal)
+	assert.Equal(t, "2023-06-13T14:00:00Z", timeline.Legs[0].DepartureUTC)
+	assert.Equal(t, 270, timeline.Legs[0].BlockMinutes)
+	assert.Assert(t, timeline.Legs[0].LayoverMinutes == nil)
+	assert.Equal(t, 160, *timeline.Legs[1].LayoverMinutes)
+}
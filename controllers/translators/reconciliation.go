@@ -0,0 +1,47 @@
+package translators
+
+import (
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/reconcile"
+)
+
+// SourcesToReconcile converts the sources of a request into sources to reconcile, and returns them.
+func SourcesToReconcile(request models.ReconcileRequest) []reconcile.Source {
+	sources := make([]reconcile.Source, len(request.Sources))
+	for i, source := range request.Sources {
+		sources[i] = reconcile.Source{Name: source.Name, Legs: source.Legs}
+	}
+	return sources
+}
+
+// ReconciliationToModel converts the reconciliation of the sources into a model object, and returns it.
+func ReconciliationToModel(result reconcile.Result) models.Reconciliation {
+	reconciliation := models.Reconciliation{
+		Legs:    make([]models.ReconciledLeg, len(result.Legs)),
+		Sources: make([]models.SourceDiff, len(result.Diffs)),
+	}
+	for i, leg := range result.Legs {
+		reconciliation.Legs[i] = models.ReconciledLeg{
+			Leg:       leg.Leg,
+			Status:    leg.Status,
+			Sources:   leg.Sources,
+			Conflicts: leg.Conflicts,
+		}
+	}
+	for i, diff := range result.Diffs {
+		// the positions are encoded as empty arrays rather than null
+		source := models.SourceDiff{
+			Name:        diff.Source,
+			Matching:    append([]int{}, diff.Matching...),
+			Conflicting: make([]models.LegConflict, len(diff.Conflicting)),
+			Only:        append([]int{}, diff.Only...),
+			Missing:     append([]int{}, diff.Missing...),
+		}
+		for j, conflict := range diff.Conflicting {
+			source.Conflicting[j] = models.LegConflict{Leg: conflict.Leg, Fields: conflict.Fields}
+		}
+		reconciliation.Sources[i] = source
+	}
+
+	return reconciliation
+}
@@ -0,0 +1,28 @@
+package translators
+
+import (
+	"math"
+
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/network"
+)
+
+// RoutesToModel converts the routes suggested for a query into a model object, and returns it.
+func RoutesToModel(query network.Query, routes []network.Route) models.RouteSuggestions {
+	suggestions := models.RouteSuggestions{
+		From:   query.From,
+		To:     query.To,
+		By:     query.By,
+		Routes: make([]models.SuggestedRoute, len(routes)),
+	}
+	for i, route := range routes {
+		suggested := models.SuggestedRoute{Path: route.Airports, Stops: route.Stops()}
+		if route.DistanceKnown {
+			km := int(math.Round(route.Distance))
+			suggested.DistanceKm = &km
+		}
+		suggestions.Routes[i] = suggested
+	}
+
+	return suggestions
+}
@@ -0,0 +1,58 @@
+package translators
+
+import (
+	"time"
+
+	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// TimelineDTOtoModel converts the timeline of a path into its model
+func TimelineDTOtoModel(timeline dto.Timeline) models.Timeline {
+	legs := make([]models.TimelineLeg, len(timeline.Legs))
+	for i, leg := range timeline.Legs {
+		legs[i] = models.TimelineLeg{
+			Origin:          leg.Origin,
+			Destination:     leg.Destination,
+			DepartureLocal:  leg.Departure.Format(time.RFC3339),
+			DepartureUTC:    leg.Departure.UTC().Format(time.RFC3339),
+			ArrivalLocal:    leg.Arrival.Format(time.RFC3339),
+			ArrivalUTC:      leg.Arrival.UTC().Format(time.RFC3339),
+			BlockMinutes:    int(leg.Block.Minutes()),
+			Overnight:       leg.Overnight,
+			CrossesDateLine: leg.CrossesDateLine,
+		}
+		if i > 0 {
+			layover := int(leg.Layover.Minutes())
+			legs[i].LayoverMinutes = &layover
+		}
+	}
+
+	return models.Timeline{
+		Legs:           legs,
+		ElapsedMinutes: int(timeline.Elapsed.Minutes()),
+	}
+}
+
+// ConnectionWarningsDTOtoModel converts the connection warnings of a path into their models
+func ConnectionWarningsDTOtoModel(warnings []dto.ConnectionWarning) []models.ConnectionWarning {
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	result := make([]models.ConnectionWarning, len(warnings))
+	for i, warning := range warnings {
+		connection := models.ConnectionDomestic
+		if warning.International {
+			connection = models.ConnectionInternational
+		}
+		result[i] = models.ConnectionWarning{
+			Airport:        warning.Airport,
+			Severity:       warning.Severity,
+			Connection:     connection,
+			LayoverMinutes: int(warning.Layover.Minutes()),
+			MinimumMinutes: int(warning.Minimum.Minutes()),
+		}
+	}
+	return result
+}
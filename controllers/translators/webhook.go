@@ -0,0 +1,29 @@
+package translators
+
+import (
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/webhooks"
+)
+
+// SubscriptionToModel converts a webhook subscription into a model object without its secret, and returns it.
+func SubscriptionToModel(subscription webhooks.Subscription) models.WebhookSubscriptionResponse {
+	return models.WebhookSubscriptionResponse{
+		ID:        subscription.ID,
+		URL:       subscription.URL,
+		Events:    subscription.Events,
+		CreatedAt: subscription.CreatedAt,
+	}
+}
+
+// DeadLetterToModel converts an abandoned delivery into a model object, and returns it.
+func DeadLetterToModel(deadLetter webhooks.DeadLetter) models.DeadLetterResponse {
+	return models.DeadLetterResponse{
+		DeliveryID:     deadLetter.DeliveryID,
+		SubscriptionID: deadLetter.Subscription.ID,
+		URL:            deadLetter.Subscription.URL,
+		Event:          deadLetter.Event,
+		Attempts:       deadLetter.Attempts,
+		LastError:      deadLetter.LastError,
+		FailedAt:       deadLetter.FailedAt,
+	}
+}
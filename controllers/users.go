@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/controllers/translators"
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/privacy"
+)
+
+// Users defines the methods for the personal data of the travelers
+type Users interface {
+	Erase(w http.ResponseWriter, r *http.Request)
+}
+
+// users defines the components for the controller
+type users struct {
+	Logger  *log.Entry
+	Eraser  *privacy.Eraser
+	Auditor audit.Recorder
+}
+
+// NewUsers returns a new instance of Users controller
+func NewUsers(log *log.Entry, eraser *privacy.Eraser, auditor audit.Recorder) (Users, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case eraser == nil:
+		return nil, errors.New("eraser")
+	case auditor == nil:
+		return nil, errors.New("auditor")
+	}
+
+	return &users{
+		Logger:  log,
+		Eraser:  eraser,
+		Auditor: auditor,
+	}, nil
+}
+
+// Erase removes the data of a traveler in the tenant of the caller and leaves a tombstone in the audit log.
+// It is restricted to admins.
+func (c *users) Erase(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromContext(r.Context(), c.Logger)
+
+	if identity, ok := auth.FromContext(r.Context()); !ok || !identity.HasRole(auth.RoleAdmin) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	userID := mux.Vars(r)["userID"]
+	erasure, err := c.Eraser.Erase(r.Context(), userID)
+
+	status := http.StatusOK
+	if err != nil {
+		status = http.StatusInternalServerError
+	}
+	c.Auditor.Record(r.Context(), audit.Entry{
+		Action:  audit.ActionErase,
+		UserID:  userID,
+		Outcome: audit.Outcome(status),
+		Status:  status,
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("error erasing user")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	logger.WithField("erased", erasure.Erased).Info("user erased")
+	writeJSON(w, http.StatusOK, translators.ErasureToModel(erasure))
+}
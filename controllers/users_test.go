@@ -0,0 +1,156 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/audit"
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/controllers"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/privacy"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+func TestController_NewUsers(t *testing.T) {
+	auditor := newAuditor(t)
+	eraser := newEraser(t, map[string]privacy.Target{"audit": auditor})
+
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		eraser    *privacy.Eraser
+		auditor   audit.Recorder
+		wantError error
+	}{
+		{name: "should_return_success", logger: log.NewEntry(nil), eraser: eraser, auditor: auditor},
+		{name: "should_return_error_when_the_logger_is_nil", eraser: eraser, auditor: auditor, wantError: errors.New("logger")},
+		{name: "should_return_error_when_the_eraser_is_nil", logger: log.NewEntry(nil), auditor: auditor, wantError: errors.New("eraser")},
+		{name: "should_return_error_when_the_auditor_is_nil", logger: log.NewEntry(nil), eraser: eraser, wantError: errors.New("auditor")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := controllers.NewUsers(tt.logger, tt.eraser, tt.auditor)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestController_UsersErase(t *testing.T) {
+	var (
+		admin = auth.Identity{Subject: "compliance", Method: auth.MethodJWT, Roles: []string{auth.RoleAdmin}}
+		agent = auth.Identity{Subject: "agent", Method: auth.MethodAPIKey}
+		acme  = tenancy.Tenant{ID: "acme"}
+	)
+
+	erase := func(c controllers.Users, identity *auth.Identity, userID string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodDelete, "/users/"+userID, nil)
+		ctx := tenancy.NewContext(request.Context(), acme)
+		if identity != nil {
+			ctx = auth.NewContext(ctx, *identity)
+		}
+		request = mux.SetURLVars(request.WithContext(ctx), map[string]string{"userID": userID})
+
+		recorder := httptest.NewRecorder()
+		c.Erase(recorder, request)
+		return recorder
+	}
+
+	t.Run("should_erase_the_user_and_leave_a_tombstone", func(t *testing.T) {
+		sink, err := audit.NewMemorySink(10)
+		require.NoError(t, err)
+		auditor, err := audit.NewAuditor(log.NewEntry(log.New()), sink)
+		require.NoError(t, err)
+		require.NoError(t, sink.Write(context.Background(), audit.Entry{
+			ID: "1", Tenant: "acme", Action: audit.ActionCalculate, UserID: "u-1", RequestHash: "abc",
+		}))
+
+		var erasedJobs []string
+		eraser := newEraser(t, map[string]privacy.Target{
+			"audit": auditor,
+			"jobs": privacy.TargetFunc(func(ctx context.Context, userID string) (int, error) {
+				erasedJobs = append(erasedJobs, privacy.Subject(ctx, userID))
+				return 2, nil
+			}),
+		})
+		c, err := controllers.NewUsers(log.NewEntry(log.New()), eraser, auditor)
+		require.NoError(t, err)
+
+		recorder := erase(c, &admin, "u-1")
+
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		var response models.ErasureResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, "u-1", response.UserID)
+		assert.Equal(t, "acme", response.Tenant)
+		assert.DeepEqual(t, map[string]int{"audit": 1, "jobs": 2}, response.Erased)
+		assert.DeepEqual(t, []string{"acme/u-1"}, erasedJobs)
+
+		entries, err := sink.Query(context.Background(), audit.Filter{UserID: "u-1"})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(entries), "only the tombstone should reference the user")
+		assert.Equal(t, audit.ActionErase, entries[0].Action)
+		assert.Equal(t, "jwt:compliance", entries[0].Actor)
+		assert.Equal(t, http.StatusOK, entries[0].Status)
+	})
+
+	t.Run("failure_response_when_a_target_fails", func(t *testing.T) {
+		sink, err := audit.NewMemorySink(10)
+		require.NoError(t, err)
+		auditor, err := audit.NewAuditor(log.NewEntry(log.New()), sink)
+		require.NoError(t, err)
+
+		eraser := newEraser(t, map[string]privacy.Target{
+			"jobs": privacy.TargetFunc(func(context.Context, string) (int, error) {
+				return 0, errors.New("unavailable")
+			}),
+		})
+		c, err := controllers.NewUsers(log.NewEntry(log.New()), eraser, auditor)
+		require.NoError(t, err)
+
+		recorder := erase(c, &admin, "u-1")
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		entries, err := sink.Query(context.Background(), audit.Filter{Action: audit.ActionErase})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(entries))
+		assert.Equal(t, audit.OutcomeFailure, entries[0].Outcome)
+	})
+
+	t.Run("failure_response_when_the_caller_is_not_admin", func(t *testing.T) {
+		erased := false
+		eraser := newEraser(t, map[string]privacy.Target{
+			"jobs": privacy.TargetFunc(func(context.Context, string) (int, error) {
+				erased = true
+				return 0, nil
+			}),
+		})
+		c, err := controllers.NewUsers(log.NewEntry(log.New()), eraser, newAuditor(t))
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusForbidden, erase(c, &agent, "u-1").Code)
+		assert.Equal(t, http.StatusForbidden, erase(c, nil, "u-1").Code)
+		assert.Assert(t, !erased)
+	})
+}
+
+func newEraser(t *testing.T, targets map[string]privacy.Target) *privacy.Eraser {
+	eraser, err := privacy.NewEraser(log.NewEntry(log.New()), targets)
+	require.NoError(t, err)
+	return eraser
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/controllers/translators"
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+	"github.com/volume/service/user-flight-tracking/webhooks"
+)
+
+// Webhooks defines the methods for webhook subscriptions
+type Webhooks interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	List(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	ListDeadLetters(w http.ResponseWriter, r *http.Request)
+}
+
+// subscriptions defines the components for the controller
+type subscriptions struct {
+	Logger     *log.Entry
+	Store      webhooks.SubscriptionStore
+	Dispatcher *webhooks.Dispatcher
+}
+
+// NewWebhooks returns a new instance of Webhooks controller
+func NewWebhooks(log *log.Entry, store webhooks.SubscriptionStore, dispatcher *webhooks.Dispatcher) (Webhooks, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case store == nil:
+		return nil, errors.New("store")
+	case dispatcher == nil:
+		return nil, errors.New("dispatcher")
+	}
+
+	return &subscriptions{
+		Logger:     log,
+		Store:      store,
+		Dispatcher: dispatcher,
+	}, nil
+}
+
+// Create registers a subscription for the events of the caller
+func (c *subscriptions) Create(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromContext(r.Context(), c.Logger)
+
+	var request models.WebhookSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.WithError(err).Error("error decoding request")
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	if err := request.Validate(); err != nil {
+		logger.WithError(err).Error("error validating request")
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	subscription := webhooks.Subscription{
+		ID:        newSubscriptionID(),
+		Owner:     tenancy.Owner(r.Context()),
+		URL:       request.URL,
+		Events:    request.Events,
+		Secret:    request.Secret,
+		CreatedAt: c.Dispatcher.Now().UTC(),
+	}
+	if err := c.Store.Add(r.Context(), subscription); err != nil {
+		logger.WithError(err).Error("error saving subscription")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", "/webhooks/"+subscription.ID)
+	writeJSON(w, http.StatusCreated, translators.SubscriptionToModel(subscription))
+}
+
+// List answers with the subscriptions of the caller
+func (c *subscriptions) List(w http.ResponseWriter, r *http.Request) {
+	owned, err := c.Store.List(r.Context(), tenancy.Owner(r.Context()))
+	if err != nil {
+		logging.FromContext(r.Context(), c.Logger).WithError(err).Error("error listing subscriptions")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]models.WebhookSubscriptionResponse, 0, len(owned))
+	for _, subscription := range owned {
+		response = append(response, translators.SubscriptionToModel(subscription))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Delete removes a subscription of the caller
+func (c *subscriptions) Delete(w http.ResponseWriter, r *http.Request) {
+	err := c.Store.Delete(r.Context(), tenancy.Owner(r.Context()), mux.Vars(r)["id"])
+	switch {
+	case errors.Is(err, webhooks.ErrNotFound):
+		http.Error(w, "Not Found", http.StatusNotFound)
+	case err != nil:
+		logging.FromContext(r.Context(), c.Logger).WithError(err).Error("error deleting subscription")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ListDeadLetters answers with the deliveries to the subscriptions of the caller that were abandoned
+func (c *subscriptions) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	deadLetters := c.Dispatcher.DeadLetters(tenancy.Owner(r.Context()))
+
+	response := make([]models.DeadLetterResponse, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		response = append(response, translators.DeadLetterToModel(deadLetter))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func newSubscriptionID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
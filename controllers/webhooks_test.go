@@ -0,0 +1,146 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/controllers"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/webhooks"
+)
+
+func TestController_NewWebhooks(t *testing.T) {
+	var (
+		logger     = log.NewEntry(nil)
+		store      = webhooks.NewMemorySubscriptionStore()
+		dispatcher = &webhooks.Dispatcher{}
+	)
+
+	tests := []struct {
+		name       string
+		logger     *log.Entry
+		store      webhooks.SubscriptionStore
+		dispatcher *webhooks.Dispatcher
+		wantError  error
+	}{
+		{name: "should_return_success", logger: logger, store: store, dispatcher: dispatcher},
+		{name: "should_return_error_when_the_logger_is_nil", store: store, dispatcher: dispatcher, wantError: errors.New("logger")},
+		{name: "should_return_error_when_the_store_is_nil", logger: logger, dispatcher: dispatcher, wantError: errors.New("store")},
+		{name: "should_return_error_when_the_dispatcher_is_nil", logger: logger, store: store, wantError: errors.New("dispatcher")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := controllers.NewWebhooks(tt.logger, tt.store, tt.dispatcher)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestController_Webhooks(t *testing.T) {
+	var (
+		logger = log.NewEntry(log.New())
+		store  = webhooks.NewMemorySubscriptionStore()
+		owner  = auth.Identity{Subject: "expenses", Method: auth.MethodAPIKey}
+		other  = auth.Identity{Subject: "other", Method: auth.MethodAPIKey}
+	)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer receiver.Close()
+
+	dispatcher, err := webhooks.NewDispatcher(logger, store, receiver.Client(), 1, time.Millisecond, time.Millisecond)
+	require.NoError(t, err)
+	defer func() { _ = dispatcher.Shutdown(context.Background()) }()
+
+	c, err := controllers.NewWebhooks(logger, store, dispatcher)
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/webhooks", c.Create).Methods(http.MethodPost)
+	router.HandleFunc("/webhooks", c.List).Methods(http.MethodGet)
+	router.HandleFunc("/webhooks/dead-letters", c.ListDeadLetters).Methods(http.MethodGet)
+	router.HandleFunc("/webhooks/{id}", c.Delete).Methods(http.MethodDelete)
+
+	serve := func(method, target, body string, identity auth.Identity) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		request = request.WithContext(auth.NewContext(request.Context(), identity))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	var subscription models.WebhookSubscriptionResponse
+
+	t.Run("should_register_a_subscription", func(t *testing.T) {
+		body := `{"url": "` + receiver.URL + `", "events": ["path.computed"], "secret": "0123456789abcdef"}`
+		recorder := serve(http.MethodPost, "/webhooks", body, owner)
+		require.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &subscription))
+		assert.Equal(t, "/webhooks/"+subscription.ID, recorder.Header().Get("Location"))
+		assert.Equal(t, receiver.URL, subscription.URL)
+		assert.Assert(t, !strings.Contains(recorder.Body.String(), "0123456789abcdef"), "the secret must not be returned")
+	})
+
+	t.Run("failure_response_when_bad_request", func(t *testing.T) {
+		bodies := []string{
+			`{"url": "ftp://example.com", "events": ["path.computed"], "secret": "0123456789abcdef"}`,
+			`{"url": "https://example.com", "events": ["path.deleted"], "secret": "0123456789abcdef"}`,
+			`{"url": "https://example.com", "events": ["path.computed"], "secret": "short"}`,
+			`{"url": "https://example.com", "events": []`,
+		}
+		for _, body := range bodies {
+			assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/webhooks", body, owner).Code, body)
+		}
+	})
+
+	t.Run("should_list_the_subscriptions_of_the_caller", func(t *testing.T) {
+		var listed []models.WebhookSubscriptionResponse
+		require.NoError(t, json.Unmarshal(serve(http.MethodGet, "/webhooks", "", owner).Body.Bytes(), &listed))
+		assert.DeepEqual(t, []string{subscription.ID}, []string{listed[0].ID})
+		assert.Equal(t, 1, len(listed))
+
+		assert.Equal(t, "[]\n", serve(http.MethodGet, "/webhooks", "", other).Body.String())
+	})
+
+	t.Run("should_list_the_dead_letters_of_the_caller", func(t *testing.T) {
+		ctx := auth.NewContext(context.Background(), owner)
+		dispatcher.Publish(ctx, auth.Owner(ctx), models.WebhookEvent{Type: models.WebhookEventPathComputed})
+
+		var deadLetters []models.DeadLetterResponse
+		deadline := time.Now().Add(5 * time.Second)
+		for len(deadLetters) == 0 {
+			require.True(t, time.Now().Before(deadline), "the delivery was not dead-lettered")
+			require.NoError(t, json.Unmarshal(serve(http.MethodGet, "/webhooks/dead-letters", "", owner).Body.Bytes(), &deadLetters))
+		}
+
+		assert.Equal(t, subscription.ID, deadLetters[0].SubscriptionID)
+		assert.Equal(t, "unexpected status 410", deadLetters[0].LastError)
+		assert.Equal(t, "[]\n", serve(http.MethodGet, "/webhooks/dead-letters", "", other).Body.String())
+	})
+
+	t.Run("should_delete_only_the_subscriptions_of_the_caller", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/webhooks/"+subscription.ID, "", other).Code)
+		assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/webhooks/"+subscription.ID, "", owner).Code)
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/webhooks/"+subscription.ID, "", owner).Code)
+	})
+}
@@ -0,0 +1,28 @@
+package dto
+
+// PathDiff is the difference between the paths of two versions of an itinerary, e.g. before and after a rebooking
+type PathDiff struct {
+	Old Path
+	New Path
+	// StartChanged and EndChanged report whether the itinerary starts or ends at another airport
+	StartChanged bool
+	EndChanged   bool
+	// Added holds the legs of the new path only, Removed those of the old path only
+	Added   []LegDiff
+	Removed []LegDiff
+	// Reordered holds the legs of both paths flown in another order, Changed those with other details
+	Reordered []LegDiff
+	Changed   []LegDiff
+}
+
+// LegDiff is a leg added, removed, reordered or changed between two versions of an itinerary
+type LegDiff struct {
+	// Old and New are the leg in the old and new paths, zero when it is missing from one of them
+	Old Leg
+	New Leg
+	// OldPosition and NewPosition are the positions of the leg in the legs of the paths, -1 when it is missing
+	OldPosition int
+	NewPosition int
+	// Changes holds the details changed, named as in models.LegDetails
+	Changes []string
+}
@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+// Leg is the flight taken from an airport of a path to the next one
+type Leg struct {
+	Origin           string
+	Destination      string
+	Carrier          string
+	FlightNumber     string
+	OperatingCarrier string
+	Cabin            string
+	BookingReference string
+	// Departure and Arrival are in the time zones of the airports, zero when the leg is not timed
+	Departure time.Time
+	Arrival   time.Time
+	// Inferred reports whether the leg was added to join the legs of the request
+	Inferred bool
+}
+
+// DuplicateLegs are the legs of an itinerary sharing their origin and destination
+type DuplicateLegs struct {
+	Origin      string
+	Destination string
+	// Legs holds the positions of the legs in the request
+	Legs []int
+	// Merged reports whether the legs were merged into the first one
+	Merged bool
+	// Conflicting reports whether the legs disagree on a detail, e.g. the flight number
+	Conflicting bool
+}
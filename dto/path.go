@@ -1,5 +1,14 @@
 package dto
 
 type Path struct {
-	Flights []*Flight
+	// Airports holds the codes of the airports of the path, in order
+	Airports []string
+	// Legs holds the flight between each airport of Airports and the next one
+	Legs []Leg
+	// Timeline holds the schedule of Legs, nil unless every leg is timed
+	Timeline *Timeline
+	// Warnings holds the risky connections of Timeline
+	Warnings []ConnectionWarning
+	// Duplicates holds the legs of the request sharing their origin and destination
+	Duplicates []DuplicateLegs
 }
@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+// Timeline is the schedule of the legs of a path
+type Timeline struct {
+	Legs []TimelineLeg
+	// Elapsed is the time from the first departure to the last arrival
+	Elapsed time.Duration
+}
+
+// TimelineLeg is the schedule of a leg, its times are in the time zones of the airports
+type TimelineLeg struct {
+	Origin      string
+	Destination string
+	Departure   time.Time
+	Arrival     time.Time
+	// Block is the time from the departure to the arrival
+	Block time.Duration
+	// Layover is the time since the arrival of the previous leg, zero on the first one
+	Layover time.Duration
+	// Overnight reports whether the leg arrives on a later local date than it departs
+	Overnight bool
+	// CrossesDateLine reports whether the leg flies over the international date line
+	CrossesDateLine bool
+}
+
+// ConnectionWarning is a connection of a path shorter than, or close to, the minimum connection time of its airport
+type ConnectionWarning struct {
+	Airport       string
+	Severity      string
+	International bool
+	Layover       time.Duration
+	Minimum       time.Duration
+}
@@ -0,0 +1,189 @@
+package duplicates
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// Policy is how the legs repeating the origin and destination of an earlier leg are handled
+type Policy string
+
+// Policies of the duplicate legs
+const (
+	// PolicyDedupe merges the repeated legs into the first one, filling its missing details from the others
+	PolicyDedupe Policy = "dedupe"
+	// PolicyKeep keeps the repeated legs as separate legs of the path
+	PolicyKeep Policy = "keep"
+	// PolicyReject refuses the itineraries with repeated legs
+	PolicyReject Policy = "reject"
+)
+
+// DefaultPolicy applies when the configuration sets none
+const DefaultPolicy = PolicyDedupe
+
+// ParsePolicy returns the policy named value, DefaultPolicy when it is empty
+func ParsePolicy(value string) (Policy, error) {
+	if value == "" {
+		return DefaultPolicy, nil
+	}
+	if p := Policy(value); p.Valid() {
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown duplicate legs policy %q", value)
+}
+
+// Valid reports whether p is a known policy
+func (p Policy) Valid() bool {
+	switch p {
+	case PolicyDedupe, PolicyKeep, PolicyReject:
+		return true
+	}
+	return false
+}
+
+// Error is returned for the itineraries with duplicate legs under PolicyReject
+type Error struct {
+	Duplicates []dto.DuplicateLegs
+}
+
+func (e *Error) Error() string {
+	groups := make([]string, len(e.Duplicates))
+	for i, d := range e.Duplicates {
+		groups[i] = fmt.Sprintf("%s-%s at %s", d.Origin, d.Destination, positions(d.Legs))
+	}
+	return "duplicate legs: " + strings.Join(groups, "; ")
+}
+
+// Apply handles the duplicate legs of req under the policy. It returns the request to reconstruct the path of,
+// and the duplicates found
+func (p Policy) Apply(req models.PathRequest) (models.PathRequest, []dto.DuplicateLegs, error) {
+	found := Find(req.Itinerary())
+	if len(found) == 0 {
+		return req, nil, nil
+	}
+
+	switch p {
+	case PolicyKeep:
+		return req, found, nil
+	case PolicyReject:
+		return models.PathRequest{}, nil, &Error{Duplicates: found}
+	}
+	return Merge(req, found), merged(found), nil
+}
+
+// Find returns the legs sharing their origin and destination, in the order of their first occurrence
+func Find(legs []models.Leg) []dto.DuplicateLegs {
+	var (
+		pairs     [][2]string
+		positions = make(map[[2]string][]int)
+	)
+	for i, leg := range legs {
+		pair := [2]string{leg.Origin, leg.Destination}
+		if _, ok := positions[pair]; !ok {
+			pairs = append(pairs, pair)
+		}
+		positions[pair] = append(positions[pair], i)
+	}
+
+	var found []dto.DuplicateLegs
+	for _, pair := range pairs {
+		indexes := positions[pair]
+		if len(indexes) < 2 {
+			continue
+		}
+
+		group := make([]models.Leg, len(indexes))
+		for i, index := range indexes {
+			group[i] = legs[index]
+		}
+		_, conflicting := mergeLegs(group)
+		found = append(found, dto.DuplicateLegs{
+			Origin:      pair[0],
+			Destination: pair[1],
+			Legs:        indexes,
+			Conflicting: conflicting,
+		})
+	}
+	return found
+}
+
+// Merge returns req with the legs of each group of duplicates merged into their first one. The details missing
+// from the first leg are taken from the next ones; the details of the first leg win on conflicts.
+func Merge(req models.PathRequest, found []dto.DuplicateLegs) models.PathRequest {
+	removed := make(map[int]bool)
+	legs := append([]models.Leg(nil), req.Legs...)
+	dates := append([]string(nil), req.Dates...)
+	for _, d := range found {
+		first := d.Legs[0]
+		if len(legs) > 0 {
+			group := make([]models.Leg, len(d.Legs))
+			for i, index := range d.Legs {
+				group[i] = legs[index]
+			}
+			legs[first], _ = mergeLegs(group)
+		}
+		for _, index := range d.Legs[1:] {
+			if len(dates) > 0 && dates[first] == "" {
+				dates[first] = dates[index]
+			}
+			removed[index] = true
+		}
+	}
+
+	merged := req
+	merged.Flights, merged.Legs, merged.Dates = nil, nil, nil
+	for i := range req.Itinerary() {
+		if removed[i] {
+			continue
+		}
+		if len(legs) > 0 {
+			merged.Legs = append(merged.Legs, legs[i])
+		} else {
+			merged.Flights = append(merged.Flights, req.Flights[i])
+		}
+		if len(dates) > 0 {
+			merged.Dates = append(merged.Dates, dates[i])
+		}
+	}
+	return merged
+}
+
+// mergeLegs returns the first leg with its missing details taken from the next ones, and whether the legs
+// disagree on any detail given by several of them
+func mergeLegs(legs []models.Leg) (models.Leg, bool) {
+	result, conflicting := legs[0], false
+	for _, leg := range legs[1:] {
+		values := leg.Details()
+		for i, field := range result.Details() {
+			switch {
+			case *values[i] == "":
+			case *field == "":
+				*field = *values[i]
+			case *field != *values[i]:
+				conflicting = true
+			}
+		}
+	}
+	return result, conflicting
+}
+
+// merged returns a copy of the duplicates, marked as merged
+func merged(found []dto.DuplicateLegs) []dto.DuplicateLegs {
+	result := append([]dto.DuplicateLegs(nil), found...)
+	for i := range result {
+		result[i].Merged = true
+	}
+	return result
+}
+
+// positions joins the positions of the legs, e.g. "0, 2"
+func positions(legs []int) string {
+	values := make([]string, len(legs))
+	for i, leg := range legs {
+		values[i] = fmt.Sprint(leg)
+	}
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,104 @@
+package duplicates_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/duplicates"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+func TestDuplicates_ParsePolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		want      duplicates.Policy
+		wantError error
+	}{
+		{name: "should_return_the_default_policy", value: "", want: duplicates.PolicyDedupe},
+		{name: "should_return_the_policy", value: "reject", want: duplicates.PolicyReject},
+		{name: "should_return_error_when_the_policy_is_unknown", value: "ignore", wantError: errors.New(`unknown duplicate legs policy "ignore"`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := duplicates.ParsePolicy(tt.value)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, policy)
+		})
+	}
+}
+
+func TestDuplicates_Apply(t *testing.T) {
+	legs := []models.Leg{
+		{Origin: "SFO", Destination: "ATL", Carrier: "UA"},
+		{Origin: "ATL", Destination: "EWR"},
+		{Origin: "SFO", Destination: "ATL", FlightNumber: "88", Cabin: "economy"},
+		{Origin: "SFO", Destination: "ATL", Carrier: "DL"},
+	}
+
+	t.Run("should_merge_the_legs_into_the_first_one", func(t *testing.T) {
+		req, found, err := duplicates.PolicyDedupe.Apply(models.PathRequest{Legs: legs, UserID: "u-1"})
+		require.NoError(t, err)
+
+		assert.DeepEqual(t, models.PathRequest{
+			Legs: []models.Leg{
+				{Origin: "SFO", Destination: "ATL", Carrier: "UA", FlightNumber: "88", Cabin: "economy"},
+				{Origin: "ATL", Destination: "EWR"},
+			},
+			UserID: "u-1",
+		}, req)
+		assert.DeepEqual(t, []dto.DuplicateLegs{
+			{Origin: "SFO", Destination: "ATL", Legs: []int{0, 2, 3}, Merged: true, Conflicting: true},
+		}, found)
+	})
+
+	t.Run("should_merge_the_flights_and_their_dates", func(t *testing.T) {
+		req, found, err := duplicates.PolicyDedupe.Apply(models.PathRequest{
+			Flights: [][]string{{"SFO", "ATL"}, {"ATL", "EWR"}, {"SFO", "ATL"}},
+			Dates:   []string{"", "2024-03-01", "2024-02-29"},
+		})
+		require.NoError(t, err)
+
+		assert.DeepEqual(t, models.PathRequest{
+			Flights: [][]string{{"SFO", "ATL"}, {"ATL", "EWR"}},
+			Dates:   []string{"2024-02-29", "2024-03-01"},
+		}, req)
+		assert.DeepEqual(t, []dto.DuplicateLegs{{Origin: "SFO", Destination: "ATL", Legs: []int{0, 2}, Merged: true}}, found)
+	})
+
+	t.Run("should_keep_the_legs", func(t *testing.T) {
+		req, found, err := duplicates.PolicyKeep.Apply(models.PathRequest{Legs: legs})
+		require.NoError(t, err)
+
+		assert.DeepEqual(t, legs, req.Legs)
+		assert.DeepEqual(t, []dto.DuplicateLegs{{Origin: "SFO", Destination: "ATL", Legs: []int{0, 2, 3}, Conflicting: true}}, found)
+	})
+
+	t.Run("failure_response_when_the_legs_are_rejected", func(t *testing.T) {
+		_, _, err := duplicates.PolicyReject.Apply(models.PathRequest{Legs: legs})
+
+		var rejected *duplicates.Error
+		require.True(t, errors.As(err, &rejected))
+		assert.Equal(t, "duplicate legs: SFO-ATL at 0, 2, 3", err.Error())
+	})
+
+	t.Run("should_return_the_request_without_duplicates", func(t *testing.T) {
+		req := models.PathRequest{Flights: [][]string{{"SFO", "ATL"}, {"ATL", "SFO"}}}
+		for _, policy := range []duplicates.Policy{duplicates.PolicyDedupe, duplicates.PolicyKeep, duplicates.PolicyReject} {
+			got, found, err := policy.Apply(req)
+			require.NoError(t, err)
+			assert.DeepEqual(t, req, got)
+			assert.Equal(t, 0, len(found))
+		}
+	})
+}
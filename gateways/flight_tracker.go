@@ -8,8 +8,14 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/volume/service/user-flight-tracking/connections"
 	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/graph"
+	"github.com/volume/service/user-flight-tracking/logging"
 	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/privacy"
+	"github.com/volume/service/user-flight-tracking/timeline"
+	"github.com/volume/service/user-flight-tracking/tracing"
 )
 
 // FlightTracker specifies the methods to get flights information
@@ -20,17 +26,27 @@ type FlightTracker interface {
 // flightTracker is the concrete implementation of the FlightTracker interface
 type flightTracker struct {
 	Logger *log.Entry
+	// Redactor writes the airports of the paths found to the logs
+	Redactor privacy.Redactor
+	// Connections holds the minimum connection times the timed paths are checked against
+	Connections *connections.Table
 }
 
 // NewFlightTracker returns a new instance of FlightTracker gateway
-func NewFlightTracker(log *log.Entry) (FlightTracker, error) {
+func NewFlightTracker(log *log.Entry, redactor privacy.Redactor, connections *connections.Table) (FlightTracker, error) {
 	switch {
 	case log == nil:
 		return nil, errors.New("logger")
+	case !redactor.Valid():
+		return nil, errors.New("redactor")
+	case connections == nil:
+		return nil, errors.New("connections")
 	}
 
 	return &flightTracker{
-		Logger: log,
+		Logger:      log,
+		Redactor:    redactor,
+		Connections: connections,
 	}, nil
 }
 
@@ -38,115 +54,203 @@ func NewFlightTracker(log *log.Entry) (FlightTracker, error) {
 func (m *flightTracker) GetFlightsPath(ctx context.Context, req models.PathRequest) (dto.Path, error) {
 	var path dto.Path
 
-	graph := buildGraph(req.Flights)
-	startFlight, _, err := findStartAndEndFlights(graph)
+	_, span := tracing.StartSpan(ctx, "gateway.buildGraph")
+	flights := graph.FromPairs(req.Pairs())
+	span.SetAttribute("airports", flights.Len())
+	span.End()
+	if err := progress(ctx, 25); err != nil {
+		return dto.Path{}, err
+	}
+
+	_, span = tracing.StartSpan(ctx, "gateway.findStartAndEndFlights")
+	start, end, err := findStartAndEndFlights(flights)
+	span.RecordError(err)
+	span.End()
+	if err != nil {
+		return dto.Path{}, err
+	}
+	if err := progress(ctx, 50); err != nil {
+		return dto.Path{}, err
+	}
+
+	_, span = tracing.StartSpan(ctx, "gateway.checkConnectivity")
+	err = checkConnectivity(flights, start, end)
+	span.RecordError(err)
+	span.End()
 	if err != nil {
 		return dto.Path{}, err
 	}
-	path.Flights = findPath(startFlight, path.Flights)
-	log.Info(buildStringPath(path))
+	if err := progress(ctx, 75); err != nil {
+		return dto.Path{}, err
+	}
+
+	_, span = tracing.StartSpan(ctx, "gateway.findPath")
+	path.Airports = findPath(flights, graph.NewTraversal(flights), start, nil)
+	path.Legs = buildLegs(path.Airports, req.Itinerary())
+	if schedule, ok := timeline.Build(firstLegs(path.Legs)); ok {
+		path.Timeline = &schedule
+		path.Warnings = m.Connections.Check(schedule)
+	}
+	span.SetAttribute("airports", len(path.Airports))
+	span.End()
+
+	logger := logging.FromContext(ctx, m.Logger).WithField("airports", len(path.Airports))
+	if value, ok := m.Redactor.Path(buildStringPath(path)); ok {
+		logger = logger.WithField("path", value)
+	}
+	if len(path.Warnings) > 0 {
+		logger = logger.WithField("connectionWarnings", len(path.Warnings))
+	}
+	logger.Info("flights path found")
 
 	return path, nil
 }
 
-func buildGraph(pairs [][]string) map[string]*dto.Flight {
-	graph := make(map[string]*dto.Flight)
+// PathError is returned when the legs of a request don't form a single path, e.g. when they are disconnected
+type PathError struct {
+	Reason string
+}
 
-	for _, pair := range pairs {
-		source := pair[0]
-		destination := pair[1]
+func (e *PathError) Error() string {
+	return e.Reason
+}
 
-		// Create new flight if it does not exist in the graph
-		if _, ok := graph[source]; !ok {
-			graph[source] = &dto.Flight{Name: source}
-		}
-		if _, ok := graph[destination]; !ok {
-			graph[destination] = &dto.Flight{Name: destination}
-		}
+// ProgressFunc receives the completed percentage of a calculation
+type ProgressFunc func(percent int)
 
-		// Create the connections between the flights
-		sourceFlight := graph[source]
-		destinationFlight := graph[destination]
-		sourceFlight.Outgoing = append(sourceFlight.Outgoing, destinationFlight)
-		destinationFlight.Incoming = append(destinationFlight.Incoming, sourceFlight)
-	}
+type progressKey struct{}
+
+// WithProgress returns a copy of ctx whose calculations report their completed percentage to report
+func WithProgress(ctx context.Context, report ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
 
-	return graph
+// progress reports the completed percentage to the callback of ctx, if any, and stops the calculation once ctx is
+// canceled
+func progress(ctx context.Context, percent int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if report, ok := ctx.Value(progressKey{}).(ProgressFunc); ok {
+		report(percent)
+	}
+	return nil
 }
 
-func findStartAndEndFlights(graph map[string]*dto.Flight) (*dto.Flight, *dto.Flight, error) {
-	var startFlight, endFlight *dto.Flight
+// findStartAndEndFlights returns the airports without incoming and without outgoing flights
+func findStartAndEndFlights(flights *graph.Graph) (graph.Airport, graph.Airport, error) {
+	var start, end graph.Airport
 
-	for _, node := range graph {
-		if len(node.Incoming) == 0 {
-			node.IsStart = true
-			startFlight = node
+	for _, airport := range flights.Airports() {
+		if len(flights.Incoming(airport)) == 0 {
+			start = airport
 		}
-		if len(node.Outgoing) == 0 {
-			node.IsEnd = true
-			endFlight = node
+		if len(flights.Outgoing(airport)) == 0 {
+			end = airport
 		}
 	}
 
-	if startFlight == nil {
-		return nil, nil, fmt.Errorf("no initial flight found")
+	if start == "" {
+		return "", "", &PathError{Reason: "no initial flight found"}
 	}
 
-	if endFlight == nil {
-		return nil, nil, fmt.Errorf("no final flight found")
+	if end == "" {
+		return "", "", &PathError{Reason: "no final flight found"}
 	}
 
-	checkInFlights(startFlight)
+	return start, end, nil
+}
 
+func checkConnectivity(flights *graph.Graph, start, end graph.Airport) error {
 	// Check circular flights
-	if startFlight == endFlight {
-		return nil, nil, fmt.Errorf("a circular flight was found between flights: %s", startFlight.Name)
+	if start == end {
+		return &PathError{Reason: fmt.Sprintf("a circular flight was found between flights: %s", start)}
 	}
 
 	// Check disconnections
-	disconnectedFlights := make([]string, 0)
-	for _, node := range graph {
-		if !node.Visited {
-			disconnectedFlights = append(disconnectedFlights, node.Name)
-		}
+	traversal := graph.NewTraversal(flights)
+	traversal.Reach(start)
+	if disconnected := traversal.Unvisited(); len(disconnected) > 0 {
+		return &PathError{Reason: fmt.Sprintf("disconnections detected between flights: %v", disconnected)}
+	}
+
+	return nil
+}
+
+// buildLegs returns the legs connecting the airports of the path. Every leg of the itinerary between two
+// airports is returned, the duplicates kept by the policy of the request follow each other
+func buildLegs(airports []string, itinerary []models.Leg) []dto.Leg {
+	available := make(map[[2]string][]models.Leg)
+	for _, leg := range itinerary {
+		pair := [2]string{leg.Origin, leg.Destination}
+		available[pair] = append(available[pair], leg)
 	}
 
-	if len(disconnectedFlights) > 0 {
-		return nil, nil, fmt.Errorf("disconnections detected between flights: %v", disconnectedFlights)
+	legs := make([]dto.Leg, 0, len(airports))
+	for i := 1; i < len(airports); i++ {
+		pair := [2]string{airports[i-1], airports[i]}
+		candidates := available[pair]
+		if len(candidates) == 0 {
+			candidates = []models.Leg{{Origin: pair[0], Destination: pair[1]}}
+		}
+
+		for _, leg := range candidates {
+			departure, arrival, _ := leg.Times()
+			legs = append(legs, dto.Leg{
+				Origin:           leg.Origin,
+				Destination:      leg.Destination,
+				Carrier:          leg.Carrier,
+				FlightNumber:     leg.FlightNumber,
+				OperatingCarrier: leg.OperatingCarrier,
+				Cabin:            leg.Cabin,
+				BookingReference: leg.BookingReference,
+				Departure:        departure,
+				Arrival:          arrival,
+				Inferred:         leg.Inferred,
+			})
+		}
 	}
 
-	return startFlight, endFlight, nil
+	return legs
 }
 
-func checkInFlights(node *dto.Flight) {
-	node.Visited = true
-
-	for _, neighbor := range node.Outgoing {
-		if !neighbor.Visited {
-			checkInFlights(neighbor)
+// firstLegs returns the legs without the duplicates kept after the first leg of their group, the timeline follows
+// a single flight between two airports
+func firstLegs(legs []dto.Leg) []dto.Leg {
+	first := make([]dto.Leg, 0, len(legs))
+	for i, leg := range legs {
+		if i > 0 && leg.Origin == legs[i-1].Origin && leg.Destination == legs[i-1].Destination {
+			continue
 		}
+		first = append(first, leg)
 	}
+	return first
 }
 
 func buildStringPath(path dto.Path) string {
-	var p []string
-	for _, flight := range path.Flights {
-		p = append(p, flight.Name)
-	}
+	p := path.Airports
 
 	// Join the elements of pathStrings using commas
 	return fmt.Sprintf("[%s]=>[%s,%s]", strings.Join(p, ","), p[0], p[len(p)-1])
 }
 
-func findPath(node *dto.Flight, path []*dto.Flight) []*dto.Flight {
-	path = append(path, node)
+// findPath walks depth first from the airport to the first one without outgoing flights,
+// skipping the airports already on the path
+func findPath(flights *graph.Graph, traversal *graph.Traversal, airport graph.Airport, path []string) []string {
+	if !traversal.Visit(airport) {
+		return nil
+	}
+	defer traversal.Leave(airport)
+
+	path = append(path, string(airport))
 
-	if node.IsEnd {
+	outgoing := flights.Outgoing(airport)
+	if len(outgoing) == 0 {
 		return path
 	}
 
-	for _, nextFlight := range node.Outgoing {
-		nextPath := findPath(nextFlight, append([]*dto.Flight(nil), path...))
+	for _, next := range outgoing {
+		nextPath := findPath(flights, traversal, next, append([]string(nil), path...))
 		if nextPath != nil {
 			return nextPath
 		}
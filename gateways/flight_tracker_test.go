@@ -1,18 +1,25 @@
 package gateways_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 	"gotest.tools/assert"
 
+	"github.com/volume/service/user-flight-tracking/connections"
 	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/duplicates"
 	"github.com/volume/service/user-flight-tracking/gateways"
 	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/privacy"
 )
 
 func TestGateways_NewFlightTracker(t *testing.T) {
@@ -24,7 +31,9 @@ func TestGateways_NewFlightTracker(t *testing.T) {
 	)
 
 	type args struct {
-		logger *log.Entry
+		logger      *log.Entry
+		redactor    privacy.Redactor
+		connections *connections.Table
 	}
 	tests := []struct {
 		name      string
@@ -34,22 +43,52 @@ func TestGateways_NewFlightTracker(t *testing.T) {
 		{
 			name: "should_return_success",
 			args: args{
-				logger: logger,
+				logger:      logger,
+				redactor:    privacy.Redactor{Redaction: privacy.RedactionOmitted},
+				connections: newConnections(t),
 			},
 			wantError: nil,
 		},
 		{
 			name: "should_return_error_when_the_logger_is_nil",
 			args: args{
-				logger: nil,
+				logger:      nil,
+				redactor:    privacy.Redactor{Redaction: privacy.RedactionOmitted},
+				connections: newConnections(t),
 			},
 			wantError: errors.New("logger"),
 		},
+		{
+			name: "should_return_error_when_the_redaction_is_unknown",
+			args: args{
+				logger:      logger,
+				redactor:    privacy.Redactor{Redaction: "masked"},
+				connections: newConnections(t),
+			},
+			wantError: errors.New("redactor"),
+		},
+		{
+			name: "should_return_error_when_the_hashed_redaction_has_no_key",
+			args: args{
+				logger:      logger,
+				redactor:    privacy.Redactor{Redaction: privacy.RedactionHashed},
+				connections: newConnections(t),
+			},
+			wantError: errors.New("redactor"),
+		},
+		{
+			name: "should_return_error_when_the_connections_are_nil",
+			args: args{
+				logger:   logger,
+				redactor: privacy.Redactor{Redaction: privacy.RedactionOmitted},
+			},
+			wantError: errors.New("connections"),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := gateways.NewFlightTracker(tt.args.logger)
+			_, err := gateways.NewFlightTracker(tt.args.logger, tt.args.redactor, tt.args.connections)
 			if err != nil {
 				assert.Equal(t, tt.wantError.Error(), err.Error())
 			}
@@ -67,13 +106,7 @@ func TestGateways_GetFlightsPath(t *testing.T) {
 
 	t.Run("should_return_path", func(t *testing.T) {
 		wantedPath := dto.Path{
-			Flights: []*dto.Flight{
-				{Name: "SFO"},
-				{Name: "ATL"},
-				{Name: "GSO"},
-				{Name: "IND"},
-				{Name: "EWR"},
-			},
+			Airports: []string{"SFO", "ATL", "GSO", "IND", "EWR"},
 		}
 
 		req := models.PathRequest{
@@ -85,14 +118,14 @@ func TestGateways_GetFlightsPath(t *testing.T) {
 			},
 		}
 
-		g, err := gateways.NewFlightTracker(logger)
+		g, err := gateways.NewFlightTracker(logger, privacy.Redactor{Redaction: privacy.RedactionPlain}, newConnections(t))
 		require.NoError(t, err)
 
 		resp, err := g.GetFlightsPath(context.Background(), req)
 
-		assert.Equal(t, len(wantedPath.Flights), len(resp.Flights))
-		assert.Equal(t, wantedPath.Flights[0].Name, resp.Flights[0].Name)
-		assert.Equal(t, wantedPath.Flights[4].Name, resp.Flights[4].Name)
+		assert.Equal(t, len(wantedPath.Airports), len(resp.Airports))
+		assert.Equal(t, wantedPath.Airports[0], resp.Airports[0])
+		assert.Equal(t, wantedPath.Airports[4], resp.Airports[4])
 		assert.NilError(t, err)
 	})
 
@@ -108,12 +141,12 @@ func TestGateways_GetFlightsPath(t *testing.T) {
 			},
 		}
 
-		g, err := gateways.NewFlightTracker(logger)
+		g, err := gateways.NewFlightTracker(logger, privacy.Redactor{Redaction: privacy.RedactionPlain}, newConnections(t))
 		require.NoError(t, err)
 
 		resp, err := g.GetFlightsPath(context.Background(), req)
 
-		assert.Equal(t, len(wantedPath.Flights), len(resp.Flights))
+		assert.Equal(t, len(wantedPath.Airports), len(resp.Airports))
 		assert.Error(t, err, "no initial flight found")
 	})
 
@@ -129,12 +162,258 @@ func TestGateways_GetFlightsPath(t *testing.T) {
 			},
 		}
 
-		g, err := gateways.NewFlightTracker(logger)
+		g, err := gateways.NewFlightTracker(logger, privacy.Redactor{Redaction: privacy.RedactionPlain}, newConnections(t))
 		require.NoError(t, err)
 
 		resp, err := g.GetFlightsPath(context.Background(), req)
 
-		assert.Equal(t, len(wantedPath.Flights), len(resp.Flights))
+		assert.Equal(t, len(wantedPath.Airports), len(resp.Airports))
 		assert.Error(t, err, "disconnections detected between flights: [XXX EWR]")
 	})
 }
+
+func TestGateways_GetFlightsPath_Concurrent(t *testing.T) {
+	g, err := gateways.NewFlightTracker(log.NewEntry(log.New()), privacy.Redactor{Redaction: privacy.RedactionOmitted}, newConnections(t))
+	require.NoError(t, err)
+
+	req := models.PathRequest{
+		Flights: [][]string{
+			{"IND", "EWR"},
+			{"SFO", "ATL"},
+			{"GSO", "IND"},
+			{"ATL", "GSO"},
+		},
+	}
+
+	var wg sync.WaitGroup
+	paths := make([][]string, 8)
+	for i := range paths {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path, err := g.GetFlightsPath(context.Background(), req)
+			assert.NilError(t, err)
+			paths[i] = path.Airports
+		}(i)
+	}
+	wg.Wait()
+
+	for _, path := range paths {
+		assert.DeepEqual(t, []string{"SFO", "ATL", "GSO", "IND", "EWR"}, path)
+	}
+}
+
+func TestGateways_GetFlightsPath_Legs(t *testing.T) {
+	req := models.PathRequest{
+		Legs: []models.Leg{
+			{Origin: "ATL", Destination: "EWR", Carrier: "DL", FlightNumber: "1204", Cabin: models.CabinBusiness},
+			{Origin: "SFO", Destination: "ATL", Carrier: "UA", FlightNumber: "88", OperatingCarrier: "OO", BookingReference: "K7PQ2M"},
+			{Origin: "EWR", Destination: "BOS", Inferred: true},
+		},
+	}
+
+	g, err := gateways.NewFlightTracker(log.NewEntry(log.New()), privacy.Redactor{Redaction: privacy.RedactionPlain}, newConnections(t))
+	require.NoError(t, err)
+
+	resp, err := g.GetFlightsPath(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.DeepEqual(t, []dto.Leg{
+		{Origin: "SFO", Destination: "ATL", Carrier: "UA", FlightNumber: "88", OperatingCarrier: "OO", BookingReference: "K7PQ2M"},
+		{Origin: "ATL", Destination: "EWR", Carrier: "DL", FlightNumber: "1204", Cabin: models.CabinBusiness},
+		{Origin: "EWR", Destination: "BOS", Inferred: true},
+	}, resp.Legs)
+	assert.Assert(t, resp.Timeline == nil, "the legs are not timed")
+}
+
+func TestGateways_GetFlightsPath_DuplicateLegs(t *testing.T) {
+	req := models.PathRequest{
+		Legs: []models.Leg{
+			{Origin: "SFO", Destination: "ATL", Carrier: "UA", FlightNumber: "88"},
+			{Origin: "ATL", Destination: "EWR", Carrier: "DL", FlightNumber: "1204"},
+			{Origin: "SFO", Destination: "ATL", Carrier: "DL", FlightNumber: "2113"},
+		},
+	}
+
+	g, err := gateways.NewFlightTracker(log.NewEntry(log.New()), privacy.Redactor{Redaction: privacy.RedactionPlain}, newConnections(t))
+	require.NoError(t, err)
+
+	resp, err := g.GetFlightsPath(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.DeepEqual(t, []string{"SFO", "ATL", "EWR"}, resp.Airports)
+	assert.DeepEqual(t, []dto.Leg{
+		{Origin: "SFO", Destination: "ATL", Carrier: "UA", FlightNumber: "88"},
+		{Origin: "SFO", Destination: "ATL", Carrier: "DL", FlightNumber: "2113"},
+		{Origin: "ATL", Destination: "EWR", Carrier: "DL", FlightNumber: "1204"},
+	}, resp.Legs)
+}
+
+func TestGateways_GetFlightsPath_TimelineOfDuplicateLegs(t *testing.T) {
+	req := models.PathRequest{
+		Legs: []models.Leg{
+			{Origin: "SFO", Destination: "ATL", Carrier: "UA", Departure: "2023-06-13T07:00", Arrival: "2023-06-13T14:30"},
+			{Origin: "ATL", Destination: "EWR", Departure: "2023-06-13T17:10", Arrival: "2023-06-13T19:25"},
+			{Origin: "SFO", Destination: "ATL", Carrier: "DL", Departure: "2023-06-13T09:00", Arrival: "2023-06-13T16:20"},
+		},
+	}
+	kept, found, err := duplicates.PolicyKeep.Apply(req)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(found))
+
+	g, err := gateways.NewFlightTracker(log.NewEntry(log.New()), privacy.Redactor{Redaction: privacy.RedactionPlain}, newConnections(t))
+	require.NoError(t, err)
+
+	resp, err := g.GetFlightsPath(context.Background(), kept)
+	require.NoError(t, err)
+
+	require.Equal(t, 3, len(resp.Legs))
+	require.NotNil(t, resp.Timeline)
+	require.Equal(t, 2, len(resp.Timeline.Legs))
+	assert.Equal(t, "SFO", resp.Timeline.Legs[0].Origin)
+	assert.Equal(t, 4*time.Hour+30*time.Minute, resp.Timeline.Legs[0].Block)
+	assert.Equal(t, "ATL", resp.Timeline.Legs[1].Origin)
+	assert.Equal(t, 2*time.Hour+40*time.Minute, resp.Timeline.Legs[1].Layover)
+	assert.Equal(t, 0, len(resp.Warnings), "the kept duplicate must not shorten the layover at ATL")
+}
+
+func TestGateways_GetFlightsPath_Timeline(t *testing.T) {
+	req := models.PathRequest{
+		Legs: []models.Leg{
+			{Origin: "ATL", Destination: "EWR", Departure: "2023-06-13T17:10", Arrival: "2023-06-13T19:25"},
+			{Origin: "SFO", Destination: "ATL", Departure: "2023-06-13T07:00", Arrival: "2023-06-13T14:30"},
+		},
+	}
+
+	g, err := gateways.NewFlightTracker(log.NewEntry(log.New()), privacy.Redactor{Redaction: privacy.RedactionPlain}, newConnections(t))
+	require.NoError(t, err)
+
+	resp, err := g.GetFlightsPath(context.Background(), req)
+	require.NoError(t, err)
+
+	require.NotNil(t, resp.Timeline)
+	require.Equal(t, 2, len(resp.Timeline.Legs))
+	assert.Equal(t, "SFO", resp.Timeline.Legs[0].Origin)
+	assert.Equal(t, 4*time.Hour+30*time.Minute, resp.Timeline.Legs[0].Block)
+	assert.Equal(t, 2*time.Hour+40*time.Minute, resp.Timeline.Legs[1].Layover)
+	assert.Equal(t, 9*time.Hour+25*time.Minute, resp.Timeline.Elapsed)
+	assert.Equal(t, 0, len(resp.Warnings))
+}
+
+func TestGateways_GetFlightsPath_TimelineOfUnknownAirport(t *testing.T) {
+	req := models.PathRequest{
+		Legs: []models.Leg{
+			{Origin: "SFO", Destination: "ATL", Departure: "2023-06-13T07:00", Arrival: "2023-06-13T14:30"},
+			{Origin: "ATL", Destination: "XXX", Departure: "2023-06-13T17:10", Arrival: "2023-06-13T19:25"},
+		},
+	}
+	require.NoError(t, req.Validate(), "the airports of unknown time zone should be accepted")
+
+	g, err := gateways.NewFlightTracker(log.NewEntry(log.New()), privacy.Redactor{Redaction: privacy.RedactionPlain}, newConnections(t))
+	require.NoError(t, err)
+
+	resp, err := g.GetFlightsPath(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.DeepEqual(t, []string{"SFO", "ATL", "XXX"}, resp.Airports)
+	assert.Assert(t, resp.Timeline == nil, "the time zone of XXX is unknown")
+}
+
+func TestGateways_GetFlightsPath_Progress(t *testing.T) {
+	g, err := gateways.NewFlightTracker(log.NewEntry(log.New()), privacy.Redactor{Redaction: privacy.RedactionPlain}, newConnections(t))
+	require.NoError(t, err)
+	req := models.PathRequest{Flights: [][]string{{"SFO", "ATL"}, {"ATL", "EWR"}}}
+
+	t.Run("should_report_the_progress_to_the_callback", func(t *testing.T) {
+		var reported []int
+		ctx := gateways.WithProgress(context.Background(), func(percent int) { reported = append(reported, percent) })
+
+		_, err := g.GetFlightsPath(ctx, req)
+		require.NoError(t, err)
+		assert.DeepEqual(t, []int{25, 50, 75}, reported)
+	})
+
+	t.Run("should_stop_once_the_context_is_canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := g.GetFlightsPath(ctx, req)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestGateways_GetFlightsPath_ConnectionWarnings(t *testing.T) {
+	req := models.PathRequest{
+		Legs: []models.Leg{
+			{Origin: "SFO", Destination: "ATL", Departure: "2023-06-13T07:00", Arrival: "2023-06-13T14:30"},
+			{Origin: "ATL", Destination: "EWR", Departure: "2023-06-13T15:00", Arrival: "2023-06-13T17:15"},
+		},
+	}
+
+	g, err := gateways.NewFlightTracker(log.NewEntry(log.New()), privacy.Redactor{Redaction: privacy.RedactionPlain}, newConnections(t))
+	require.NoError(t, err)
+
+	resp, err := g.GetFlightsPath(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.DeepEqual(t, []dto.ConnectionWarning{
+		{Airport: "ATL", Severity: connections.SeverityImpossible, Layover: 30 * time.Minute, Minimum: connections.DefaultDomestic},
+	}, resp.Warnings)
+}
+
+func TestGateways_GetFlightsPath_Redaction(t *testing.T) {
+	req := models.PathRequest{
+		Flights: [][]string{
+			{"SFO", "ATL"},
+			{"ATL", "EWR"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		redactor privacy.Redactor
+		wantPath interface{}
+	}{
+		{
+			name:     "should_log_the_airports",
+			redactor: privacy.Redactor{Redaction: privacy.RedactionPlain},
+			wantPath: "[SFO,ATL,EWR]=>[SFO,EWR]",
+		},
+		{
+			name:     "should_log_the_hmac_of_the_airports",
+			redactor: privacy.Redactor{Redaction: privacy.RedactionHashed, Key: []byte("0123456789abcdef")},
+			wantPath: "hmac-sha256:e59806ef266c14356b9dbc0ce92046d68697781db7d93fe69beeb941ba897d84",
+		},
+		{
+			name:     "should_not_log_the_airports",
+			redactor: privacy.Redactor{Redaction: privacy.RedactionOmitted},
+			wantPath: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			logger := log.New()
+			logger.SetOutput(&buffer)
+			logger.SetFormatter(&log.JSONFormatter{})
+
+			g, err := gateways.NewFlightTracker(log.NewEntry(logger), tt.redactor, newConnections(t))
+			require.NoError(t, err)
+
+			_, err = g.GetFlightsPath(context.Background(), req)
+			require.NoError(t, err)
+
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal(buffer.Bytes(), &entry))
+			assert.Equal(t, float64(3), entry["airports"])
+			assert.Equal(t, tt.wantPath, entry["path"])
+		})
+	}
+}
+
+func newConnections(t *testing.T) *connections.Table {
+	table, err := connections.NewTable(connections.Times{Domestic: connections.DefaultDomestic, International: connections.DefaultInternational}, nil, connections.DefaultTightMargin)
+	require.NoError(t, err)
+	return table
+}
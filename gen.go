@@ -3,3 +3,5 @@ package gen
 //go:generate mockgen -package mock_flightTracker_controller -destination mocks/mockcontrollers/flightTracker_mock.go github.com/volume/service/user-flight-tracking/controllers FlightTracker
 //go:generate mockgen -package mock_flightTracker_mediator -destination mocks/mockmediators/flightTracker_mock.go github.com/volume/service/user-flight-tracking/mediators FlightTracker
 //go:generate mockgen -package mock_flightTracker_gateway -destination mocks/mockgateways/flightTracker_mock.go github.com/volume/service/user-flight-tracking/gateways FlightTracker
+//go:generate mockgen -package mock_flightTracker_controller -destination mocks/mockcontrollers/jobs_mock.go github.com/volume/service/user-flight-tracking/controllers Jobs
+//go:generate mockgen -package mock_webhooks -destination mocks/mockwebhooks/publisher_mock.go github.com/volume/service/user-flight-tracking/webhooks Publisher
@@ -0,0 +1,104 @@
+package graph
+
+// Airport is a node of the graph, identified by its code
+type Airport string
+
+// Leg is a directed edge of the graph, a flight from an airport to another one
+type Leg struct {
+	From Airport
+	To   Airport
+}
+
+// Graph is the adjacency structure of the airports connected by legs. It is not modified
+// once built, so several traversals can share it concurrently
+type Graph struct {
+	airports []Airport
+	index    map[Airport]int
+	outgoing [][]Airport
+	incoming [][]Airport
+	legs     []Leg
+}
+
+// New returns the graph of the legs, keeping the airports and their connections in the order they first appear.
+// The repeated legs connect their airports once, Legs still returns all of them
+func New(legs []Leg) *Graph {
+	g := &Graph{
+		index: make(map[Airport]int),
+		legs:  append([]Leg(nil), legs...),
+	}
+
+	connected := make(map[Leg]bool, len(legs))
+	for _, leg := range legs {
+		from, to := g.add(leg.From), g.add(leg.To)
+		if connected[leg] {
+			continue
+		}
+		connected[leg] = true
+		g.outgoing[from] = append(g.outgoing[from], leg.To)
+		g.incoming[to] = append(g.incoming[to], leg.From)
+	}
+
+	return g
+}
+
+// FromPairs returns the graph of the [origin, destination] pairs of a request
+func FromPairs(pairs [][]string) *Graph {
+	legs := make([]Leg, len(pairs))
+	for i, pair := range pairs {
+		legs[i] = Leg{From: Airport(pair[0]), To: Airport(pair[1])}
+	}
+
+	return New(legs)
+}
+
+// add returns the index of the airport, adding it to the graph when missing
+func (g *Graph) add(airport Airport) int {
+	if i, ok := g.index[airport]; ok {
+		return i
+	}
+
+	g.index[airport] = len(g.airports)
+	g.airports = append(g.airports, airport)
+	g.outgoing = append(g.outgoing, nil)
+	g.incoming = append(g.incoming, nil)
+	return len(g.airports) - 1
+}
+
+// Len returns the number of airports
+func (g *Graph) Len() int {
+	return len(g.airports)
+}
+
+// Contains reports whether the airport is part of the graph
+func (g *Graph) Contains(airport Airport) bool {
+	_, ok := g.index[airport]
+	return ok
+}
+
+// Airports returns the airports in the order they first appear in the legs
+func (g *Graph) Airports() []Airport {
+	return append([]Airport(nil), g.airports...)
+}
+
+// Legs returns the legs the graph was built from
+func (g *Graph) Legs() []Leg {
+	return append([]Leg(nil), g.legs...)
+}
+
+// Outgoing returns the destinations of the legs departing from the airport
+func (g *Graph) Outgoing(airport Airport) []Airport {
+	i, ok := g.index[airport]
+	if !ok {
+		return nil
+	}
+	return append([]Airport(nil), g.outgoing[i]...)
+}
+
+// Incoming returns the origins of the legs arriving to the airport
+func (g *Graph) Incoming(airport Airport) []Airport {
+	i, ok := g.index[airport]
+	if !ok {
+		return nil
+	}
+	return append([]Airport(nil), g.incoming[i]...)
+}
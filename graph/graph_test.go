@@ -0,0 +1,131 @@
+package graph_test
+
+import (
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/graph"
+)
+
+func TestGraph_FromPairs(t *testing.T) {
+	g := graph.FromPairs([][]string{{"IND", "EWR"}, {"SFO", "ATL"}, {"ATL", "IND"}, {"ATL", "GSO"}})
+
+	assert.Equal(t, 5, g.Len())
+	assert.DeepEqual(t, []graph.Airport{"IND", "EWR", "SFO", "ATL", "GSO"}, g.Airports())
+	assert.DeepEqual(t, []graph.Airport{"IND", "GSO"}, g.Outgoing("ATL"))
+	assert.DeepEqual(t, []graph.Airport{"ATL"}, g.Incoming("IND"))
+	assert.DeepEqual(t, []graph.Leg{{From: "IND", To: "EWR"}, {From: "SFO", To: "ATL"}, {From: "ATL", To: "IND"}, {From: "ATL", To: "GSO"}}, g.Legs())
+	assert.Assert(t, g.Contains("SFO"))
+	assert.Assert(t, !g.Contains("JFK"))
+	assert.Equal(t, 0, len(g.Outgoing("JFK")))
+}
+
+func TestGraph_RepeatedLegs(t *testing.T) {
+	g := graph.FromPairs([][]string{{"SFO", "ATL"}, {"ATL", "GSO"}, {"SFO", "ATL"}})
+
+	assert.DeepEqual(t, []graph.Airport{"ATL"}, g.Outgoing("SFO"))
+	assert.DeepEqual(t, []graph.Airport{"SFO"}, g.Incoming("ATL"))
+	assert.Equal(t, 3, len(g.Legs()))
+}
+
+func TestGraph_Immutable(t *testing.T) {
+	g := graph.FromPairs([][]string{{"SFO", "ATL"}, {"SFO", "EWR"}})
+
+	g.Airports()[0] = "JFK"
+	g.Outgoing("SFO")[0] = "JFK"
+	g.Legs()[0].To = "JFK"
+
+	assert.DeepEqual(t, []graph.Airport{"SFO", "ATL", "EWR"}, g.Airports())
+	assert.DeepEqual(t, []graph.Airport{"ATL", "EWR"}, g.Outgoing("SFO"))
+	assert.DeepEqual(t, graph.Leg{From: "SFO", To: "ATL"}, g.Legs()[0])
+}
+
+func TestGraph_Traversal(t *testing.T) {
+	g := graph.FromPairs([][]string{{"SFO", "ATL"}, {"ATL", "SFO"}, {"XXX", "EWR"}, {"ATL", "GSO"}})
+
+	t.Run("should_reach_the_connected_airports", func(t *testing.T) {
+		traversal := graph.NewTraversal(g)
+		traversal.Reach("SFO")
+
+		assert.Assert(t, traversal.Visited("GSO"))
+		assert.DeepEqual(t, []graph.Airport{"XXX", "EWR"}, traversal.Unvisited())
+	})
+
+	t.Run("should_keep_the_state_of_each_traversal", func(t *testing.T) {
+		first, second := graph.NewTraversal(g), graph.NewTraversal(g)
+
+		assert.Assert(t, first.Visit("SFO"))
+		assert.Assert(t, !first.Visit("SFO"))
+		assert.Assert(t, !first.Visit("JFK"))
+		assert.Assert(t, !second.Visited("SFO"))
+
+		first.Leave("SFO")
+		assert.Assert(t, !first.Visited("SFO"))
+	})
+}
+
+func TestGraph_ShortestPaths(t *testing.T) {
+	g := graph.FromPairs([][]string{
+		{"SFO", "DEN"}, {"DEN", "ORD"}, {"ORD", "EWR"}, {"SFO", "ATL"}, {"ATL", "EWR"}, {"DEN", "EWR"}, {"EWR", "SFO"},
+	})
+
+	t.Run("should_return_the_paths_with_the_fewest_legs_first", func(t *testing.T) {
+		paths := g.ShortestPaths("SFO", "EWR", 5, graph.PathOptions{})
+
+		assert.DeepEqual(t, []graph.WeightedPath{
+			{Airports: []graph.Airport{"SFO", "DEN", "EWR"}, Cost: 2},
+			{Airports: []graph.Airport{"SFO", "ATL", "EWR"}, Cost: 2},
+			{Airports: []graph.Airport{"SFO", "DEN", "ORD", "EWR"}, Cost: 3},
+		}, paths)
+	})
+
+	t.Run("should_return_at_most_k_paths", func(t *testing.T) {
+		assert.Equal(t, 1, len(g.ShortestPaths("SFO", "EWR", 1, graph.PathOptions{})))
+	})
+
+	t.Run("should_apply_the_constraints", func(t *testing.T) {
+		paths := g.ShortestPaths("SFO", "EWR", 5, graph.PathOptions{MaxLegs: 2, Excluded: []graph.Airport{"ATL"}})
+
+		assert.DeepEqual(t, []graph.WeightedPath{{Airports: []graph.Airport{"SFO", "DEN", "EWR"}, Cost: 2}}, paths)
+	})
+
+	t.Run("should_rank_the_paths_by_weight", func(t *testing.T) {
+		weights := map[graph.Leg]float64{{From: "SFO", To: "DEN"}: 1, {From: "DEN", To: "ORD"}: 1, {From: "ORD", To: "EWR"}: 1, {From: "DEN", To: "EWR"}: 5}
+		weight := func(leg graph.Leg) (float64, bool) {
+			w, ok := weights[leg]
+			return w, ok
+		}
+
+		paths := g.ShortestPaths("SFO", "EWR", 5, graph.PathOptions{Weight: weight})
+
+		assert.DeepEqual(t, []graph.WeightedPath{
+			{Airports: []graph.Airport{"SFO", "DEN", "ORD", "EWR"}, Cost: 3},
+			{Airports: []graph.Airport{"SFO", "DEN", "EWR"}, Cost: 6},
+		}, paths)
+	})
+
+	t.Run("should_return_no_path_between_unknown_airports", func(t *testing.T) {
+		assert.Equal(t, 0, len(g.ShortestPaths("SFO", "JFK", 5, graph.PathOptions{})))
+		assert.Equal(t, 0, len(g.ShortestPaths("SFO", "SFO", 5, graph.PathOptions{})))
+	})
+
+	t.Run("should_search_large_networks", func(t *testing.T) {
+		// 3,000 airports with 40 routes each, and an airport with no route to it
+		var pairs [][]string
+		for i := 0; i < 3000; i++ {
+			for j := 0; j < 40; j++ {
+				pairs = append(pairs, []string{fmt.Sprintf("A%04d", i), fmt.Sprintf("A%04d", (i+j*73+1)%3000)})
+			}
+		}
+		large := graph.FromPairs(append(pairs, []string{"ZZZ", "A0000"}))
+
+		paths := large.ShortestPaths("A0000", "A0367", 5, graph.PathOptions{MaxLegs: 5})
+		assert.Equal(t, 5, len(paths))
+		for i := 1; i < len(paths); i++ {
+			assert.Assert(t, paths[i-1].Cost <= paths[i].Cost)
+		}
+		assert.Equal(t, 0, len(large.ShortestPaths("A0000", "ZZZ", 5, graph.PathOptions{MaxLegs: 5})))
+	})
+}
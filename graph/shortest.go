@@ -0,0 +1,230 @@
+package graph
+
+import "container/heap"
+
+// Weight returns the cost of taking a leg, ok is false when the leg can't be taken
+type Weight func(leg Leg) (cost float64, ok bool)
+
+// Hops weighs every leg the same, to rank the paths by their number of legs
+func Hops(Leg) (float64, bool) {
+	return 1, true
+}
+
+// PathOptions constrain the paths returned by ShortestPaths
+type PathOptions struct {
+	// MaxLegs bounds the number of legs of each path, unbounded when 0
+	MaxLegs int
+	// Excluded holds the airports the paths can't pass through
+	Excluded []Airport
+	// Weight is the cost of the legs, Hops when nil. The costs must not be negative
+	Weight Weight
+}
+
+// WeightedPath is a path of the graph with its total cost
+type WeightedPath struct {
+	Airports []Airport
+	Cost     float64
+}
+
+// ShortestPaths returns up to k paths from an airport to another one, cheapest first. The paths never pass
+// through the same airport twice; those with the same cost keep the order of the legs of the graph.
+// The paths are found by Yen's algorithm: every path after the first one leaves a previous path at one of its
+// airports, by the cheapest way from there that no previous path with the same beginning takes
+func (g *Graph) ShortestPaths(from, to Airport, k int, opts PathOptions) []WeightedPath {
+	if k <= 0 || !g.Contains(from) || !g.Contains(to) || from == to {
+		return nil
+	}
+	search := pathSearch{graph: g, to: to, weight: opts.Weight, excluded: make(map[Airport]bool, len(opts.Excluded))}
+	if search.weight == nil {
+		search.weight = Hops
+	}
+	for _, airport := range opts.Excluded {
+		search.excluded[airport] = true
+	}
+	if search.excluded[from] || search.excluded[to] {
+		return nil
+	}
+
+	first, ok := search.cheapest(from, opts.MaxLegs, nil, nil)
+	if !ok {
+		return nil
+	}
+	var (
+		paths      = []WeightedPath{first}
+		candidates = &pathQueue{}
+		found      = map[string]bool{pathKey(first.Airports): true}
+	)
+	for len(paths) < k {
+		previous := paths[len(paths)-1].Airports
+		for i := 0; i < len(previous)-1; i++ {
+			root, maxLegs := previous[:i+1], 0
+			if opts.MaxLegs > 0 {
+				if maxLegs = opts.MaxLegs - i; maxLegs <= 0 {
+					break
+				}
+			}
+
+			// the root can't be visited again, nor left the way of the paths already found
+			removedAirports := make(map[Airport]bool, i)
+			for _, airport := range root[:i] {
+				removedAirports[airport] = true
+			}
+			removedLegs := make(map[Leg]bool)
+			for _, path := range paths {
+				if len(path.Airports) > i+1 && samePrefix(path.Airports, root) {
+					removedLegs[Leg{From: path.Airports[i], To: path.Airports[i+1]}] = true
+				}
+			}
+
+			spur, ok := search.cheapest(root[i], maxLegs, removedAirports, removedLegs)
+			if !ok {
+				continue
+			}
+			airports := append(append([]Airport(nil), root[:i]...), spur.Airports...)
+			if key := pathKey(airports); !found[key] {
+				found[key] = true
+				heap.Push(candidates, &partialPath{airports: airports, legs: len(airports) - 1, cost: search.cost(root) + spur.Cost})
+			}
+		}
+		if candidates.Len() == 0 {
+			break
+		}
+		best := heap.Pop(candidates).(*partialPath)
+		paths = append(paths, WeightedPath{Airports: best.airports, Cost: best.cost})
+	}
+
+	return paths
+}
+
+// pathSearch holds the constraints shared by the searches of a call to ShortestPaths
+type pathSearch struct {
+	graph    *Graph
+	to       Airport
+	weight   Weight
+	excluded map[Airport]bool
+}
+
+// cheapest returns the cheapest path from an airport to the destination of the search with at most maxLegs legs,
+// unbounded when 0, avoiding the removed airports and legs. Dijkstra's algorithm runs over the airports reached
+// with a number of legs: an airport already reached as cheaply with as few legs isn't extended again
+func (s *pathSearch) cheapest(from Airport, maxLegs int, removedAirports map[Airport]bool, removedLegs map[Leg]bool) (WeightedPath, bool) {
+	// settled holds the fewest legs of the paths taken out of the queue to each airport, cheapest first
+	settled := make(map[Airport]int)
+	dominated := func(airport Airport, legs int) bool {
+		fewest, ok := settled[airport]
+		return ok && (maxLegs <= 0 || fewest <= legs)
+	}
+
+	queue := &pathQueue{}
+	heap.Push(queue, &partialPath{airport: from})
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(*partialPath)
+		if dominated(current.airport, current.legs) {
+			continue
+		}
+		settled[current.airport] = current.legs
+		if current.airport == s.to {
+			return WeightedPath{Airports: current.path(), Cost: current.cost}, true
+		}
+		if maxLegs > 0 && current.legs >= maxLegs {
+			continue
+		}
+
+		for _, next := range s.graph.Outgoing(current.airport) {
+			leg := Leg{From: current.airport, To: next}
+			if s.excluded[next] || removedAirports[next] || removedLegs[leg] || dominated(next, current.legs+1) {
+				continue
+			}
+			cost, ok := s.weight(leg)
+			if !ok {
+				continue
+			}
+			heap.Push(queue, &partialPath{airport: next, previous: current, legs: current.legs + 1, cost: current.cost + cost})
+		}
+	}
+
+	return WeightedPath{}, false
+}
+
+// cost returns the cost of the legs between the airports
+func (s *pathSearch) cost(airports []Airport) float64 {
+	var total float64
+	for i := 1; i < len(airports); i++ {
+		cost, _ := s.weight(Leg{From: airports[i-1], To: airports[i]})
+		total += cost
+	}
+	return total
+}
+
+// samePrefix reports whether the path begins with the airports of prefix
+func samePrefix(path, prefix []Airport) bool {
+	for i, airport := range prefix {
+		if path[i] != airport {
+			return false
+		}
+	}
+	return true
+}
+
+// pathKey identifies the path by its airports
+func pathKey(airports []Airport) string {
+	key := make([]byte, 0, 4*len(airports))
+	for _, airport := range airports {
+		key = append(append(key, airport...), '-')
+	}
+	return string(key)
+}
+
+// partialPath is a path queued by a search: the candidates of ShortestPaths hold their airports, the paths of
+// cheapest are linked to the path they extend
+type partialPath struct {
+	airports []Airport
+	airport  Airport
+	previous *partialPath
+	legs     int
+	cost     float64
+	// sequence is the order the path was queued in, to break the ties
+	sequence int
+}
+
+// path returns the airports of a path of cheapest
+func (p *partialPath) path() []Airport {
+	airports := make([]Airport, p.legs+1)
+	for current := p; current != nil; current = current.previous {
+		airports[current.legs] = current.airport
+	}
+	return airports
+}
+
+// pathQueue is the priority queue of the partial paths, cheapest first, then with the fewest legs
+type pathQueue struct {
+	paths  []*partialPath
+	queued int
+}
+
+func (q *pathQueue) Len() int { return len(q.paths) }
+
+func (q *pathQueue) Less(i, j int) bool {
+	switch {
+	case q.paths[i].cost != q.paths[j].cost:
+		return q.paths[i].cost < q.paths[j].cost
+	case q.paths[i].legs != q.paths[j].legs:
+		return q.paths[i].legs < q.paths[j].legs
+	}
+	return q.paths[i].sequence < q.paths[j].sequence
+}
+
+func (q *pathQueue) Swap(i, j int) { q.paths[i], q.paths[j] = q.paths[j], q.paths[i] }
+
+func (q *pathQueue) Push(x interface{}) {
+	path := x.(*partialPath)
+	path.sequence = q.queued
+	q.queued++
+	q.paths = append(q.paths, path)
+}
+
+func (q *pathQueue) Pop() interface{} {
+	last := q.paths[len(q.paths)-1]
+	q.paths = q.paths[:len(q.paths)-1]
+	return last
+}
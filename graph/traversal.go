@@ -0,0 +1,62 @@
+package graph
+
+// Traversal holds the state of a walk over a graph, so that the graph itself stays untouched.
+// A traversal must not be shared between goroutines
+type Traversal struct {
+	graph   *Graph
+	visited []bool
+}
+
+// NewTraversal returns a traversal of the graph without visited airports
+func NewTraversal(g *Graph) *Traversal {
+	return &Traversal{
+		graph:   g,
+		visited: make([]bool, g.Len()),
+	}
+}
+
+// Visit marks the airport as visited, returning false when it was already visited or is not part of the graph
+func (t *Traversal) Visit(airport Airport) bool {
+	i, ok := t.graph.index[airport]
+	if !ok || t.visited[i] {
+		return false
+	}
+
+	t.visited[i] = true
+	return true
+}
+
+// Leave marks the airport as not visited, to backtrack
+func (t *Traversal) Leave(airport Airport) {
+	if i, ok := t.graph.index[airport]; ok {
+		t.visited[i] = false
+	}
+}
+
+// Visited reports whether the airport was visited
+func (t *Traversal) Visited(airport Airport) bool {
+	i, ok := t.graph.index[airport]
+	return ok && t.visited[i]
+}
+
+// Unvisited returns the airports not visited yet, in the order of the graph
+func (t *Traversal) Unvisited() []Airport {
+	var airports []Airport
+	for i, airport := range t.graph.airports {
+		if !t.visited[i] {
+			airports = append(airports, airport)
+		}
+	}
+	return airports
+}
+
+// Reach visits every airport reachable from start, depth first
+func (t *Traversal) Reach(start Airport) {
+	if !t.Visit(start) {
+		return
+	}
+
+	for _, next := range t.graph.outgoing[t.graph.index[start]] {
+		t.Reach(next)
+	}
+}
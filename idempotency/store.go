@@ -0,0 +1,113 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// KeyHeader is the header carrying the idempotency key chosen by the client
+	KeyHeader = "Idempotency-Key"
+	// ReplayedHeader marks the responses replayed from a previous request
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+// sweepInterval is the minimum time between two purges of the expired records of the memory store
+const sweepInterval = time.Minute
+
+// ErrNotFound is returned when a key has no record
+var ErrNotFound = errors.New("idempotency key not found")
+
+// Response is a response stored to be replayed
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Record is the request claiming a key and, once it has completed, its response
+type Record struct {
+	// Fingerprint identifies the request that claimed the key
+	Fingerprint string
+	// Response is nil while the request is in flight
+	Response  *Response
+	ExpiresAt time.Time
+}
+
+// Store keeps the records of the idempotency keys, implementations must be safe for concurrent use
+type Store interface {
+	// Reserve claims key for the request identified by fingerprint until expires, reporting true when it did.
+	// When the key is already claimed its record is returned instead.
+	Reserve(ctx context.Context, key, fingerprint string, expires time.Time) (Record, bool, error)
+	// Complete stores the response of the request that claimed key
+	Complete(ctx context.Context, key string, response Response) error
+	// Release drops key so that the request can be retried
+	Release(ctx context.Context, key string) error
+}
+
+// memoryStore is an in-memory Store
+type memoryStore struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	records   map[string]*Record
+	lastSweep time.Time
+}
+
+// NewMemoryStore returns a Store keeping the records in memory until they expire
+func NewMemoryStore() Store {
+	return &memoryStore{now: time.Now, records: make(map[string]*Record)}
+}
+
+func (s *memoryStore) Reserve(_ context.Context, key, fingerprint string, expires time.Time) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if record, ok := s.records[key]; ok && now.Before(record.ExpiresAt) {
+		return *record, false, nil
+	}
+
+	record := &Record{Fingerprint: fingerprint, ExpiresAt: expires}
+	s.records[key] = record
+	return *record, true, nil
+}
+
+func (s *memoryStore) Complete(_ context.Context, key string, response Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		return ErrNotFound
+	}
+	record.Response = &response
+	return nil
+}
+
+func (s *memoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// sweep drops the expired records, at most once per sweepInterval
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package idempotency_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/idempotency"
+)
+
+func TestIdempotency_MemoryStore(t *testing.T) {
+	ctx := context.Background()
+	expires := time.Now().Add(time.Hour)
+
+	t.Run("should_reserve_a_key_once", func(t *testing.T) {
+		store := idempotency.NewMemoryStore()
+
+		_, reserved, err := store.Reserve(ctx, "k", "a", expires)
+		require.NoError(t, err)
+		assert.Assert(t, reserved)
+
+		record, reserved, err := store.Reserve(ctx, "k", "b", expires)
+		require.NoError(t, err)
+		assert.Assert(t, !reserved)
+		assert.Equal(t, "a", record.Fingerprint)
+		assert.Assert(t, record.Response == nil, "the request is still in flight")
+	})
+
+	t.Run("should_return_the_completed_response", func(t *testing.T) {
+		store := idempotency.NewMemoryStore()
+
+		_, _, err := store.Reserve(ctx, "k", "a", expires)
+		require.NoError(t, err)
+		response := idempotency.Response{Status: http.StatusCreated, Header: http.Header{"Location": {"/jobs/1"}}, Body: []byte("{}")}
+		require.NoError(t, store.Complete(ctx, "k", response))
+
+		record, reserved, err := store.Reserve(ctx, "k", "a", expires)
+		require.NoError(t, err)
+		assert.Assert(t, !reserved)
+		assert.DeepEqual(t, &response, record.Response)
+	})
+
+	t.Run("should_reserve_a_released_or_expired_key_again", func(t *testing.T) {
+		store := idempotency.NewMemoryStore()
+
+		_, _, err := store.Reserve(ctx, "released", "a", expires)
+		require.NoError(t, err)
+		require.NoError(t, store.Release(ctx, "released"))
+		_, reserved, err := store.Reserve(ctx, "released", "b", expires)
+		require.NoError(t, err)
+		assert.Assert(t, reserved)
+
+		_, _, err = store.Reserve(ctx, "expired", "a", time.Now().Add(-time.Second))
+		require.NoError(t, err)
+		_, reserved, err = store.Reserve(ctx, "expired", "b", expires)
+		require.NoError(t, err)
+		assert.Assert(t, reserved)
+	})
+
+	t.Run("failure_response_when_completing_an_unknown_key", func(t *testing.T) {
+		store := idempotency.NewMemoryStore()
+		assert.Equal(t, idempotency.ErrNotFound, store.Complete(ctx, "k", idempotency.Response{}))
+	})
+}
@@ -0,0 +1,49 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+)
+
+// subjects holds the travelers whose data the response of a request holds
+type subjects struct {
+	mu      sync.Mutex
+	userIDs []string
+}
+
+type subjectsKey struct{}
+
+// NewContext returns a copy of ctx collecting the travelers of the response of its request
+func NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, subjectsKey{}, &subjects{})
+}
+
+// Track records that the response of the request of ctx holds the data of userID, so that its stored copy can be
+// erased. It does nothing when userID is empty or the request isn't idempotent.
+func Track(ctx context.Context, userID string) {
+	s, ok := ctx.Value(subjectsKey{}).(*subjects)
+	if !ok || userID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tracked := range s.userIDs {
+		if tracked == userID {
+			return
+		}
+	}
+	s.userIDs = append(s.userIDs, userID)
+}
+
+// Subjects returns the travelers tracked in ctx
+func Subjects(ctx context.Context) []string {
+	s, ok := ctx.Value(subjectsKey{}).(*subjects)
+	if !ok {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.userIDs...)
+}
@@ -0,0 +1,370 @@
+package jobqueue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/tracing"
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+// Job statuses
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+var (
+	// ErrNotFound is returned for unknown, deleted or expired jobs
+	ErrNotFound = errors.New("job not found")
+	// ErrQueueFull is returned when no more jobs can be enqueued
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrClosed is returned once the queue has been shut down
+	ErrClosed = errors.New("job queue is closed")
+)
+
+// Task is the work of a job, it should return early once ctx is canceled
+type Task func(ctx context.Context) (interface{}, error)
+
+// Job is a snapshot of an enqueued task
+type Job struct {
+	ID string
+	// Owner identifies the caller that created the job, empty for anonymous callers
+	Owner string
+	// Subject identifies the person whose data the job processes, empty when unknown
+	Subject string
+	// Status is the lifecycle state, Progress is a percentage reported by the task
+	Status   Status
+	Progress int
+	// Result holds the output of a succeeded job and Err the failure of a failed one
+	Result interface{}
+	Err    error
+
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// ExpiresAt is the time the finished job will be removed, zero while it is pending
+	ExpiresAt time.Time
+}
+
+// Finished reports whether the job has succeeded or failed
+func (j Job) Finished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed
+}
+
+// job is the mutable state of a job, guarded by the queue mutex
+type job struct {
+	Job
+	ctx    context.Context
+	task   Task
+	cancel context.CancelFunc
+}
+
+// Queue runs tasks on a fixed pool of workers and retains their results for a while
+type Queue struct {
+	Logger *log.Entry
+	// TTL is how long finished jobs are retained
+	TTL time.Duration
+	Now func() time.Time
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	pending chan *job
+	closed  bool
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewQueue returns a queue started with the given number of workers, accepting up to size pending jobs
+func NewQueue(log *log.Entry, workers, size int, ttl time.Duration) (*Queue, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case workers <= 0:
+		return nil, errors.New("workers")
+	case size <= 0:
+		return nil, errors.New("size")
+	case ttl <= 0:
+		return nil, errors.New("ttl")
+	}
+
+	q := &Queue{
+		Logger:  log,
+		TTL:     ttl,
+		Now:     time.Now,
+		jobs:    make(map[string]*job),
+		pending: make(chan *job, size),
+		done:    make(chan struct{}),
+	}
+
+	q.wg.Add(workers + 1)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	go q.janitor()
+
+	return q, nil
+}
+
+// Submit enqueues task on behalf of owner, processing the data of subject. The values of ctx, e.g. the request id, are passed to the task
+// but its cancellation is not: the job only stops when it is deleted or the queue shuts down.
+func (q *Queue) Submit(ctx context.Context, owner, subject string, task Task) (Job, error) {
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return Job{}, ErrClosed
+	}
+	q.purge()
+
+	j := &job{
+		Job: Job{
+			ID:        id,
+			Owner:     owner,
+			Subject:   subject,
+			Status:    StatusQueued,
+			CreatedAt: q.Now(),
+		},
+		ctx:  detached{ctx},
+		task: task,
+	}
+
+	select {
+	case q.pending <- j:
+	default:
+		return Job{}, ErrQueueFull
+	}
+	q.jobs[id] = j
+
+	return j.Job, nil
+}
+
+// Get returns a snapshot of the job
+func (q *Queue) Get(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.purge()
+	j, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+
+	return j.Job, nil
+}
+
+// Delete cancels the job if it is pending and removes it with its result
+func (q *Queue) Delete(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.purge()
+	j, ok := q.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	if j.cancel != nil {
+		j.cancel()
+	}
+	delete(q.jobs, id)
+
+	return nil
+}
+
+// DeleteSubject cancels and removes the jobs processing the data of subject, returning their number
+func (q *Queue) DeleteSubject(subject string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var deleted int
+	for id, j := range q.jobs {
+		if subject == "" || j.Subject != subject {
+			continue
+		}
+		if j.cancel != nil {
+			j.cancel()
+		}
+		delete(q.jobs, id)
+		deleted++
+	}
+
+	return deleted
+}
+
+// Shutdown stops accepting jobs, cancels the running ones and waits for the workers to return
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	close(q.done)
+	for _, j := range q.jobs {
+		if j.cancel != nil {
+			j.cancel()
+		}
+	}
+	q.mu.Unlock()
+
+	stopped := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-q.done:
+			return
+		case j := <-q.pending:
+			q.run(j)
+		}
+	}
+}
+
+// run executes the task of j unless the job was deleted while queued
+func (q *Queue) run(j *job) {
+	q.mu.Lock()
+	if q.closed || q.jobs[j.ID] != j {
+		q.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(j.ctx)
+	j.cancel = cancel
+	j.Status = StatusRunning
+	j.StartedAt = q.Now()
+	q.mu.Unlock()
+	defer cancel()
+
+	fields := log.Fields{logging.FieldJobID: j.ID}
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		fields[logging.FieldRequestID] = requestID
+	}
+	ctx = logging.NewContext(ctx, fields)
+	ctx = context.WithValue(ctx, progressKey{}, func(percent int) { q.setProgress(j, percent) })
+	ctx, span := tracing.StartSpan(ctx, "jobqueue.run")
+	span.SetAttribute("job.id", j.ID)
+
+	result, err := j.task(ctx)
+	span.RecordError(err)
+	span.End()
+
+	logger := logging.FromContext(ctx, q.Logger)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j.cancel = nil
+	j.FinishedAt = q.Now()
+	j.ExpiresAt = j.FinishedAt.Add(q.TTL)
+	if err != nil {
+		j.Status, j.Err = StatusFailed, err
+		logger.WithError(err).Warn("job failed")
+		return
+	}
+	j.Status, j.Result, j.Progress = StatusSucceeded, result, 100
+	logger.Debug("job succeeded")
+}
+
+func (q *Queue) setProgress(j *job, percent int) {
+	switch {
+	case percent < 0:
+		percent = 0
+	case percent > 100:
+		percent = 100
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if j.Status == StatusRunning {
+		j.Progress = percent
+	}
+}
+
+// janitor periodically removes the expired jobs, so that unread results don't pile up
+func (q *Queue) janitor() {
+	defer q.wg.Done()
+
+	interval := q.TTL
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.done:
+			return
+		case <-ticker.C:
+			q.mu.Lock()
+			q.purge()
+			q.mu.Unlock()
+		}
+	}
+}
+
+// purge removes the expired jobs, the caller must hold the mutex
+func (q *Queue) purge() {
+	now := q.Now()
+	for id, j := range q.jobs {
+		if j.Finished() && !now.Before(j.ExpiresAt) {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+type progressKey struct{}
+
+// ReportProgress records the completion percentage of the job running with ctx, it is a no-op outside of a job
+func ReportProgress(ctx context.Context, percent int) {
+	if report, ok := ctx.Value(progressKey{}).(func(int)); ok {
+		report(percent)
+	}
+}
+
+// detached keeps the values of a context without its deadline and cancellation
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
+
+func newID() (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id[:]), nil
+}
@@ -0,0 +1,212 @@
+package jobqueue_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/jobqueue"
+)
+
+func TestJobQueue_NewQueue(t *testing.T) {
+	logger := log.NewEntry(log.New())
+
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		workers   int
+		size      int
+		ttl       time.Duration
+		wantError error
+	}{
+		{name: "should_return_success", logger: logger, workers: 1, size: 1, ttl: time.Minute},
+		{name: "should_return_error_when_the_logger_is_nil", workers: 1, size: 1, ttl: time.Minute, wantError: errors.New("logger")},
+		{name: "should_return_error_without_workers", logger: logger, size: 1, ttl: time.Minute, wantError: errors.New("workers")},
+		{name: "should_return_error_without_size", logger: logger, workers: 1, ttl: time.Minute, wantError: errors.New("size")},
+		{name: "should_return_error_without_ttl", logger: logger, workers: 1, size: 1, wantError: errors.New("ttl")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := jobqueue.NewQueue(tt.logger, tt.workers, tt.size, tt.ttl)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, q.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestJobQueue_Lifecycle(t *testing.T) {
+	newQueue := func(t *testing.T, workers, size int) *jobqueue.Queue {
+		q, err := jobqueue.NewQueue(log.NewEntry(log.New()), workers, size, time.Minute)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = q.Shutdown(context.Background()) })
+		return q
+	}
+
+	t.Run("should_report_progress_and_keep_the_result", func(t *testing.T) {
+		q := newQueue(t, 1, 1)
+		reported, release := make(chan struct{}), make(chan struct{})
+
+		job, err := q.Submit(context.Background(), "apiKey:batch", "acme/u-1", func(ctx context.Context) (interface{}, error) {
+			jobqueue.ReportProgress(ctx, 40)
+			close(reported)
+			<-release
+			return "done", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, jobqueue.StatusQueued, job.Status)
+		assert.Equal(t, "apiKey:batch", job.Owner)
+		assert.Equal(t, "acme/u-1", job.Subject)
+
+		<-reported
+		running, err := q.Get(job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, jobqueue.StatusRunning, running.Status)
+		assert.Equal(t, 40, running.Progress)
+
+		close(release)
+		finished := waitFinished(t, q, job.ID)
+		assert.Equal(t, jobqueue.StatusSucceeded, finished.Status)
+		assert.Equal(t, 100, finished.Progress)
+		assert.Equal(t, "done", finished.Result)
+		assert.Equal(t, finished.FinishedAt.Add(time.Minute), finished.ExpiresAt)
+	})
+
+	t.Run("should_keep_the_error_of_a_failed_job", func(t *testing.T) {
+		q := newQueue(t, 1, 1)
+
+		job, err := q.Submit(context.Background(), "", "", func(context.Context) (interface{}, error) {
+			return nil, errors.New("no initial flight found")
+		})
+		require.NoError(t, err)
+
+		finished := waitFinished(t, q, job.ID)
+		assert.Equal(t, jobqueue.StatusFailed, finished.Status)
+		assert.Error(t, finished.Err, "no initial flight found")
+	})
+
+	t.Run("should_cancel_a_running_job_when_deleted", func(t *testing.T) {
+		q := newQueue(t, 1, 1)
+		started, canceled := make(chan struct{}), make(chan struct{})
+
+		job, err := q.Submit(context.Background(), "", "", func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-ctx.Done()
+			close(canceled)
+			return nil, ctx.Err()
+		})
+		require.NoError(t, err)
+
+		<-started
+		require.NoError(t, q.Delete(job.ID))
+		<-canceled
+
+		_, err = q.Get(job.ID)
+		assert.Assert(t, errors.Is(err, jobqueue.ErrNotFound))
+		assert.Assert(t, errors.Is(q.Delete(job.ID), jobqueue.ErrNotFound))
+	})
+
+	t.Run("should_delete_the_jobs_of_a_subject", func(t *testing.T) {
+		q := newQueue(t, 1, 2)
+		done := func(context.Context) (interface{}, error) { return "done", nil }
+
+		erased, err := q.Submit(context.Background(), "", "acme/u-1", done)
+		require.NoError(t, err)
+		kept, err := q.Submit(context.Background(), "", "acme/u-2", done)
+		require.NoError(t, err)
+		waitFinished(t, q, erased.ID)
+		waitFinished(t, q, kept.ID)
+
+		assert.Equal(t, 1, q.DeleteSubject("acme/u-1"))
+		assert.Equal(t, 0, q.DeleteSubject(""))
+
+		_, err = q.Get(erased.ID)
+		assert.Assert(t, errors.Is(err, jobqueue.ErrNotFound))
+		_, err = q.Get(kept.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("should_not_cancel_the_job_with_the_submitting_context", func(t *testing.T) {
+		q := newQueue(t, 1, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		job, err := q.Submit(ctx, "", "", func(ctx context.Context) (interface{}, error) {
+			return nil, ctx.Err()
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, jobqueue.StatusSucceeded, waitFinished(t, q, job.ID).Status)
+	})
+
+	t.Run("should_reject_jobs_when_the_queue_is_full", func(t *testing.T) {
+		q := newQueue(t, 1, 1)
+		started, release := make(chan struct{}), make(chan struct{})
+		defer close(release)
+
+		block := func(context.Context) (interface{}, error) {
+			<-release
+			return nil, nil
+		}
+		_, err := q.Submit(context.Background(), "", "", func(ctx context.Context) (interface{}, error) {
+			close(started)
+			return block(ctx)
+		})
+		require.NoError(t, err)
+		<-started
+
+		_, err = q.Submit(context.Background(), "", "", block)
+		require.NoError(t, err)
+		_, err = q.Submit(context.Background(), "", "", block)
+		assert.Assert(t, errors.Is(err, jobqueue.ErrQueueFull))
+	})
+
+	t.Run("should_forget_finished_jobs_after_the_ttl", func(t *testing.T) {
+		q := newQueue(t, 1, 1)
+		now := time.Date(2023, 6, 13, 10, 0, 0, 0, time.UTC)
+		q.Now = func() time.Time { return now }
+
+		job, err := q.Submit(context.Background(), "", "", func(context.Context) (interface{}, error) { return nil, nil })
+		require.NoError(t, err)
+		waitFinished(t, q, job.ID)
+
+		now = now.Add(time.Minute)
+		_, err = q.Get(job.ID)
+		assert.Assert(t, errors.Is(err, jobqueue.ErrNotFound))
+	})
+
+	t.Run("should_reject_jobs_after_shutdown", func(t *testing.T) {
+		q := newQueue(t, 1, 1)
+		require.NoError(t, q.Shutdown(context.Background()))
+
+		_, err := q.Submit(context.Background(), "", "", func(context.Context) (interface{}, error) { return nil, nil })
+		assert.Assert(t, errors.Is(err, jobqueue.ErrClosed))
+	})
+}
+
+func waitFinished(t *testing.T, q *jobqueue.Queue, id string) jobqueue.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := q.Get(id)
+		require.NoError(t, err)
+		if job.Finished() {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("job %s did not finish", id)
+	return jobqueue.Job{}
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Field names shared by the request scoped logs
+const (
+	FieldRequestID = "request_id"
+	FieldRoute     = "route"
+	FieldStatus    = "status"
+	FieldLatency   = "latency_ms"
+	FieldLegs      = "legs"
+	FieldCaller    = "caller"
+	FieldJobID     = "job_id"
+	FieldTenant    = "tenant"
+)
+
+// scope holds the fields collected during a request
+type scope struct {
+	mu     sync.Mutex
+	fields log.Fields
+}
+
+type scopeKey struct{}
+
+type requestIDKey struct{}
+
+// NewContext returns a copy of ctx carrying a request scope initialized with fields
+func NewContext(ctx context.Context, fields log.Fields) context.Context {
+	s := &scope{fields: make(log.Fields, len(fields))}
+	for k, v := range fields {
+		s.fields[k] = v
+	}
+	return context.WithValue(ctx, scopeKey{}, s)
+}
+
+// AddFields adds fields to the request scope of ctx, so that every later log of the request carries them
+func AddFields(ctx context.Context, fields log.Fields) {
+	s, ok := ctx.Value(scopeKey{}).(*scope)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range fields {
+		s.fields[k] = v
+	}
+}
+
+// FromContext returns logger enriched with the fields of the request scope stored in ctx
+func FromContext(ctx context.Context, logger *log.Entry) *log.Entry {
+	s, ok := ctx.Value(scopeKey{}).(*scope)
+	if !ok {
+		return logger
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return logger.WithFields(s.fields)
+}
+
+// WithRequestID returns a copy of ctx carrying the request id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request id stored in ctx, if any
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,32 @@
+package logging_test
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/logging"
+)
+
+func TestLogging_FromContext(t *testing.T) {
+	logger := log.NewEntry(log.New()).WithField("gateway", "FlightTracker")
+
+	t.Run("should_return_the_logger_without_request_scope", func(t *testing.T) {
+		entry := logging.FromContext(context.Background(), logger)
+
+		assert.Equal(t, logger, entry)
+	})
+
+	t.Run("should_add_the_request_scope_fields", func(t *testing.T) {
+		ctx := logging.NewContext(context.Background(), log.Fields{logging.FieldRequestID: "abc"})
+		logging.AddFields(ctx, log.Fields{logging.FieldLegs: 2})
+
+		entry := logging.FromContext(ctx, logger)
+
+		assert.Equal(t, "FlightTracker", entry.Data["gateway"])
+		assert.Equal(t, "abc", entry.Data[logging.FieldRequestID])
+		assert.Equal(t, 2, entry.Data[logging.FieldLegs])
+	})
+}
@@ -2,49 +2,243 @@ package mediators
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/volume/service/user-flight-tracking/cache"
 	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/duplicates"
 	"github.com/volume/service/user-flight-tracking/gateways"
+	"github.com/volume/service/user-flight-tracking/logging"
 	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/pathdiff"
+	"github.com/volume/service/user-flight-tracking/privacy"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+	"github.com/volume/service/user-flight-tracking/tracing"
+	"github.com/volume/service/user-flight-tracking/webhooks"
 )
 
 // FlightTracker specifies the methods to get flights
 type FlightTracker interface {
 	GetFlightsPath(ctx context.Context, req models.PathRequest) (dto.Path, error)
+	DiffFlightsPaths(ctx context.Context, previous, current models.PathRequest) (dto.PathDiff, error)
+}
+
+// Versions of the itineraries compared by DiffFlightsPaths
+const (
+	VersionOld = "old"
+	VersionNew = "new"
+)
+
+// VersionError is returned by DiffFlightsPaths when the path of a version of the itinerary fails
+type VersionError struct {
+	// Version is VersionOld or VersionNew
+	Version string
+	Err     error
+}
+
+func (e *VersionError) Error() string {
+	return e.Version + " itinerary: " + e.Err.Error()
+}
+
+func (e *VersionError) Unwrap() error {
+	return e.Err
+}
+
+// PathError is returned when the legs of a request don't form a single path
+type PathError = gateways.PathError
+
+// WithProgress returns a copy of ctx whose path calculations report their completed percentage to report, e.g. the
+// progress of the job running them
+func WithProgress(ctx context.Context, report func(percent int)) context.Context {
+	return gateways.WithProgress(ctx, report)
 }
 
 // flightTracker is the concrete implementation of the FlightTracker interface
 type flightTracker struct {
 	Logger               *log.Entry
 	FlightTrackerGateway gateways.FlightTracker
+	Publisher            webhooks.Publisher
+	Cache                cache.Backend
+	// Index records the cached paths of every traveler, so that they can be erased
+	Index privacy.Tracker
+	// DuplicateLegs is the policy of the duplicate legs of the tenants without their own
+	DuplicateLegs duplicates.Policy
 }
 
 // NewFlightTracker returns a new instance of FlightTracker mediator
-func NewFlightTracker(log *log.Entry, flightTrackerGateway gateways.FlightTracker) (FlightTracker, error) {
+func NewFlightTracker(
+	log *log.Entry,
+	flightTrackerGateway gateways.FlightTracker,
+	publisher webhooks.Publisher,
+	pathCache cache.Backend,
+	index privacy.Tracker,
+	duplicateLegs duplicates.Policy,
+) (FlightTracker, error) {
 	switch {
 	case log == nil:
 		return nil, errors.New("logger")
 	case flightTrackerGateway == nil:
 		return nil, errors.New("flightTrackerGateway")
+	case publisher == nil:
+		return nil, errors.New("publisher")
+	case pathCache == nil:
+		return nil, errors.New("cache")
+	case index == nil:
+		return nil, errors.New("index")
+	case !duplicateLegs.Valid():
+		return nil, errors.New("duplicateLegs")
 	}
 
 	return &flightTracker{
 		Logger:               log,
 		FlightTrackerGateway: flightTrackerGateway,
+		Publisher:            publisher,
+		Cache:                pathCache,
+		Index:                index,
+		DuplicateLegs:        duplicateLegs,
 	}, nil
 }
 
 // GetFlightsPath returns a flights path
 func (m *flightTracker) GetFlightsPath(ctx context.Context, req models.PathRequest) (dto.Path, error) {
-	var path dto.Path
+	ctx, span := tracing.StartSpan(ctx, "mediator.GetFlightsPath")
+	defer span.End()
+	span.SetAttribute("legs", len(req.Pairs()))
+
+	logger := logging.FromContext(ctx, m.Logger)
+	logger.Debug("getting flights path")
+
+	data := models.WebhookEventData{UserID: req.UserID, Legs: len(req.Pairs())}
+
+	path, err := m.flightsPath(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		logger.WithError(err).Warn("flights path could not be reconstructed")
+
+		data.Error = err.Error()
+		m.Publisher.Publish(ctx, tenancy.Owner(ctx), models.WebhookEvent{Type: models.WebhookEventPathFailed, Data: data})
+		return dto.Path{}, err
+	}
+
+	data.Path = append(data.Path, path.Airports...)
+	if len(data.Path) > 0 {
+		data.Start, data.End = data.Path[0], data.Path[len(data.Path)-1]
+	}
+	m.Publisher.Publish(ctx, tenancy.Owner(ctx), models.WebhookEvent{Type: models.WebhookEventPathComputed, Data: data})
+
+	return path, nil
+}
+
+// DiffFlightsPaths returns the differences between the paths of two versions of an itinerary. Unlike GetFlightsPath,
+// no webhook event is published: the versions are compared, not calculated.
+func (m *flightTracker) DiffFlightsPaths(ctx context.Context, previous, current models.PathRequest) (dto.PathDiff, error) {
+	ctx, span := tracing.StartSpan(ctx, "mediator.DiffFlightsPaths")
+	defer span.End()
+	span.SetAttribute("legs", len(current.Pairs()))
+
+	logger := logging.FromContext(ctx, m.Logger)
+	logger.Debug("comparing flights paths")
+
+	old, err := m.flightsPath(ctx, previous)
+	if err != nil {
+		span.RecordError(err)
+		return dto.PathDiff{}, &VersionError{Version: VersionOld, Err: err}
+	}
+	updated, err := m.flightsPath(ctx, current)
+	if err != nil {
+		span.RecordError(err)
+		return dto.PathDiff{}, &VersionError{Version: VersionNew, Err: err}
+	}
+
+	return pathdiff.Compare(old, updated), nil
+}
+
+// flightsPath returns the path of the legs of req once its duplicate legs are handled
+func (m *flightTracker) flightsPath(ctx context.Context, req models.PathRequest) (dto.Path, error) {
+	// the duplicates are handled before the cache, the same legs merged by dedupe share their path
+	req, found, err := m.duplicateLegs(ctx).Apply(req)
+	if err != nil {
+		return dto.Path{}, err
+	}
+	path, err := m.cachedFlightsPath(ctx, req)
+	if err != nil {
+		return dto.Path{}, err
+	}
+	if len(found) > 0 {
+		logging.FromContext(ctx, m.Logger).WithField("duplicates", len(found)).Info("duplicate legs found")
+		path.Duplicates = found
+	}
+	return path, nil
+}
+
+// duplicateLegs returns the policy of the duplicate legs of the tenant of ctx
+func (m *flightTracker) duplicateLegs(ctx context.Context) duplicates.Policy {
+	if tenant, _ := tenancy.FromContext(ctx); tenant.DuplicateLegs != "" {
+		return tenant.DuplicateLegs
+	}
+	return m.DuplicateLegs
+}
+
+// cachedPath is the encoding of the paths in the cache
+type cachedPath struct {
+	Airports []string                `json:"airports"`
+	Legs     []dto.Leg               `json:"legs"`
+	Timeline *dto.Timeline           `json:"timeline,omitempty"`
+	Warnings []dto.ConnectionWarning `json:"warnings,omitempty"`
+}
+
+// cacheKey returns the key of the itinerary of req. The details of the legs are part of the key, as they are echoed
+// in the path; the requests of bare pairs keep the key of the pairs.
+func cacheKey(req models.PathRequest) string {
+	if len(req.Legs) == 0 {
+		return cache.Key(req.Flights)
+	}
+
+	legs := make([][]string, len(req.Legs))
+	for i, leg := range req.Legs {
+		legs[i] = []string{leg.Origin, leg.Destination, leg.Carrier, leg.FlightNumber, leg.OperatingCarrier, leg.Cabin, leg.BookingReference, leg.Departure, leg.Arrival}
+		if leg.Inferred {
+			legs[i] = append(legs[i], "inferred")
+		}
+	}
+	return cache.Key(legs)
+}
+
+// cachedFlightsPath returns the path of the legs from the cache, reconstructing and caching it on a miss.
+// Cache failures are logged and the path is reconstructed.
+func (m *flightTracker) cachedFlightsPath(ctx context.Context, req models.PathRequest) (dto.Path, error) {
+	logger := logging.FromContext(ctx, m.Logger)
+	key := tenancy.Scope(ctx, cacheKey(req))
+	if req.UserID != "" {
+		m.Index.Track(ctx, req.UserID, key)
+	}
+
+	cached, ok, err := m.Cache.Get(ctx, key)
+	if err != nil {
+		logger.WithError(err).Warn("error reading the path cache")
+	}
+	tracing.SpanFromContext(ctx).SetAttribute("cache.hit", ok)
+	if ok {
+		var entry cachedPath
+		decodeErr := json.Unmarshal(cached, &entry)
+		if decodeErr == nil {
+			return dto.Path{Airports: entry.Airports, Legs: entry.Legs, Timeline: entry.Timeline, Warnings: entry.Warnings}, nil
+		}
+		logger.WithError(decodeErr).Warn("error decoding the cached path")
+	}
 
 	path, err := m.FlightTrackerGateway.GetFlightsPath(ctx, req)
 	if err != nil {
 		return dto.Path{}, err
 	}
 
+	encoded, _ := json.Marshal(cachedPath{Airports: path.Airports, Legs: path.Legs, Timeline: path.Timeline, Warnings: path.Warnings})
+	if err := m.Cache.Set(ctx, key, encoded); err != nil {
+		logger.WithError(err).Warn("error writing the path cache")
+	}
+
 	return path, nil
 }
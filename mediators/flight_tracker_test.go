@@ -4,17 +4,25 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 	"gotest.tools/assert"
 
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/cache"
 	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/duplicates"
 	"github.com/volume/service/user-flight-tracking/gateways"
 	"github.com/volume/service/user-flight-tracking/mediators"
 	mock_flightTracker_gateway "github.com/volume/service/user-flight-tracking/mocks/mockgateways"
+	mock_webhooks "github.com/volume/service/user-flight-tracking/mocks/mockwebhooks"
 	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/privacy"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+	"github.com/volume/service/user-flight-tracking/webhooks"
 )
 
 func TestMediators_NewFlightTracker(t *testing.T) {
@@ -22,13 +30,20 @@ func TestMediators_NewFlightTracker(t *testing.T) {
 	defer ctrl.Finish()
 
 	var (
-		logger      = log.NewEntry(nil)
-		mockGateway = mock_flightTracker_gateway.NewMockFlightTracker(ctrl)
+		logger        = log.NewEntry(nil)
+		mockGateway   = mock_flightTracker_gateway.NewMockFlightTracker(ctrl)
+		mockPublisher = mock_webhooks.NewMockPublisher(ctrl)
+		pathCache     = newCache(t)
+		index         = newIndex(t, pathCache)
 	)
 
 	type args struct {
-		logger      *log.Entry
-		mockGateway gateways.FlightTracker
+		logger        *log.Entry
+		mockGateway   gateways.FlightTracker
+		mockPublisher webhooks.Publisher
+		pathCache     cache.Backend
+		index         privacy.Tracker
+		duplicateLegs duplicates.Policy
 	}
 	tests := []struct {
 		name      string
@@ -38,32 +53,92 @@ func TestMediators_NewFlightTracker(t *testing.T) {
 		{
 			name: "should_return_success",
 			args: args{
-				logger:      logger,
-				mockGateway: mockGateway,
+				logger:        logger,
+				mockGateway:   mockGateway,
+				mockPublisher: mockPublisher,
+				pathCache:     pathCache,
+				index:         index,
+				duplicateLegs: duplicates.DefaultPolicy,
 			},
 			wantError: nil,
 		},
 		{
 			name: "should_return_error_when_the_logger_is_nil",
 			args: args{
-				logger:      nil,
-				mockGateway: mockGateway,
+				logger:        nil,
+				mockGateway:   mockGateway,
+				mockPublisher: mockPublisher,
+				pathCache:     pathCache,
+				index:         index,
+				duplicateLegs: duplicates.DefaultPolicy,
 			},
 			wantError: errors.New("logger"),
 		},
 		{
 			name: "should_return_error_when_the_mediator_is_nil",
 			args: args{
-				logger:      logger,
-				mockGateway: nil,
+				logger:        logger,
+				mockGateway:   nil,
+				mockPublisher: mockPublisher,
+				pathCache:     pathCache,
+				index:         index,
+				duplicateLegs: duplicates.DefaultPolicy,
 			},
 			wantError: errors.New("flightTrackerGateway"),
 		},
+		{
+			name: "should_return_error_when_the_publisher_is_nil",
+			args: args{
+				logger:        logger,
+				mockGateway:   mockGateway,
+				mockPublisher: nil,
+				pathCache:     pathCache,
+				index:         index,
+				duplicateLegs: duplicates.DefaultPolicy,
+			},
+			wantError: errors.New("publisher"),
+		},
+		{
+			name: "should_return_error_when_the_cache_is_nil",
+			args: args{
+				logger:        logger,
+				mockGateway:   mockGateway,
+				mockPublisher: mockPublisher,
+				pathCache:     nil,
+				index:         index,
+				duplicateLegs: duplicates.DefaultPolicy,
+			},
+			wantError: errors.New("cache"),
+		},
+		{
+			name: "should_return_error_when_the_index_is_nil",
+			args: args{
+				logger:        logger,
+				mockGateway:   mockGateway,
+				mockPublisher: mockPublisher,
+				pathCache:     pathCache,
+				index:         nil,
+				duplicateLegs: duplicates.DefaultPolicy,
+			},
+			wantError: errors.New("index"),
+		},
+		{
+			name: "should_return_error_when_the_duplicate_legs_policy_is_unknown",
+			args: args{
+				logger:        logger,
+				mockGateway:   mockGateway,
+				mockPublisher: mockPublisher,
+				pathCache:     pathCache,
+				index:         index,
+				duplicateLegs: "ignore",
+			},
+			wantError: errors.New("duplicateLegs"),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := mediators.NewFlightTracker(tt.args.logger, tt.args.mockGateway)
+			_, err := mediators.NewFlightTracker(tt.args.logger, tt.args.mockGateway, tt.args.mockPublisher, tt.args.pathCache, tt.args.index, tt.args.duplicateLegs)
 			if err != nil {
 				assert.Equal(t, tt.wantError.Error(), err.Error())
 			}
@@ -76,41 +151,246 @@ func TestMediators_GetFlightsPath(t *testing.T) {
 	defer ctrl.Finish()
 
 	var (
-		logger      = log.NewEntry(log.New())
-		mockGateway = mock_flightTracker_gateway.NewMockFlightTracker(ctrl)
+		logger        = log.NewEntry(log.New())
+		mockGateway   = mock_flightTracker_gateway.NewMockFlightTracker(ctrl)
+		mockPublisher = mock_webhooks.NewMockPublisher(ctrl)
 	)
 
 	t.Run("should_return_path", func(t *testing.T) {
 		wantedPath := dto.Path{
-			Flights: []*dto.Flight{
-				{Name: "SFO"},
-				{Name: "ATL"},
-				{Name: "GSO"},
-				{Name: "IND"},
-				{Name: "EWR"},
-			},
+			Airports: []string{"SFO", "ATL", "GSO", "IND", "EWR"},
 		}
 
 		mockGateway.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(wantedPath, nil)
+		mockPublisher.EXPECT().Publish(gomock.Any(), "api_key:batch-job", models.WebhookEvent{
+			Type: models.WebhookEventPathComputed,
+			Data: models.WebhookEventData{
+				UserID: "u-1",
+				Legs:   4,
+				Start:  "SFO",
+				End:    "EWR",
+				Path:   []string{"SFO", "ATL", "GSO", "IND", "EWR"},
+			},
+		})
 
-		m, err := mediators.NewFlightTracker(logger, mockGateway)
+		pathCache := newCache(t)
+		m, err := mediators.NewFlightTracker(logger, mockGateway, mockPublisher, pathCache, newIndex(t, pathCache), duplicates.DefaultPolicy)
 		require.NoError(t, err)
 
-		resp, err := m.GetFlightsPath(context.Background(), models.PathRequest{})
+		ctx := auth.NewContext(context.Background(), auth.Identity{Subject: "batch-job", Method: auth.MethodAPIKey})
+		resp, err := m.GetFlightsPath(ctx, models.PathRequest{
+			Flights: [][]string{{"IND", "EWR"}, {"SFO", "ATL"}, {"GSO", "IND"}, {"ATL", "GSO"}},
+			UserID:  "u-1",
+		})
 
-		assert.Equal(t, len(wantedPath.Flights), len(resp.Flights))
+		assert.Equal(t, len(wantedPath.Airports), len(resp.Airports))
 		assert.NilError(t, err)
 	})
 
 	t.Run("failure_response_when_gateway_retrun_error", func(t *testing.T) {
 		mockGateway.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(dto.Path{}, errors.New("internal server error"))
+		mockPublisher.EXPECT().Publish(gomock.Any(), "", models.WebhookEvent{
+			Type: models.WebhookEventPathFailed,
+			Data: models.WebhookEventData{Error: "internal server error"},
+		})
 
-		m, err := mediators.NewFlightTracker(logger, mockGateway)
+		pathCache := newCache(t)
+		m, err := mediators.NewFlightTracker(logger, mockGateway, mockPublisher, pathCache, newIndex(t, pathCache), duplicates.DefaultPolicy)
 		require.NoError(t, err)
 
 		resp, err := m.GetFlightsPath(context.Background(), models.PathRequest{})
 
-		assert.Equal(t, 0, len(resp.Flights))
+		assert.Equal(t, 0, len(resp.Airports))
 		assert.Error(t, err, "internal server error")
 	})
 }
+
+func TestMediators_GetFlightsPath_Cache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		logger        = log.NewEntry(log.New())
+		mockGateway   = mock_flightTracker_gateway.NewMockFlightTracker(ctrl)
+		mockPublisher = mock_webhooks.NewMockPublisher(ctrl)
+	)
+
+	t.Run("should_reconstruct_the_same_leg_set_once", func(t *testing.T) {
+		wantedPath := dto.Path{
+			Airports: []string{"SFO", "ATL", "EWR"},
+		}
+
+		mockGateway.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(wantedPath, nil).Times(1)
+		mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+		pathCache := newCache(t)
+		m, err := mediators.NewFlightTracker(logger, mockGateway, mockPublisher, pathCache, newIndex(t, pathCache), duplicates.DefaultPolicy)
+		require.NoError(t, err)
+
+		first, err := m.GetFlightsPath(context.Background(), models.PathRequest{Flights: [][]string{{"SFO", "ATL"}, {"ATL", "EWR"}}})
+		require.NoError(t, err)
+		second, err := m.GetFlightsPath(context.Background(), models.PathRequest{Flights: [][]string{{"ATL", "EWR"}, {"SFO", "ATL"}}})
+		require.NoError(t, err)
+
+		assert.DeepEqual(t, first, second)
+	})
+
+	t.Run("should_not_share_the_cache_between_tenants", func(t *testing.T) {
+		mockGateway.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(dto.Path{
+			Airports: []string{"SFO", "ATL"},
+		}, nil).Times(2)
+		mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+		pathCache := newCache(t)
+		m, err := mediators.NewFlightTracker(logger, mockGateway, mockPublisher, pathCache, newIndex(t, pathCache), duplicates.DefaultPolicy)
+		require.NoError(t, err)
+
+		req := models.PathRequest{Flights: [][]string{{"SFO", "ATL"}}}
+		for _, id := range []string{"acme", "globex"} {
+			_, err = m.GetFlightsPath(tenancy.NewContext(context.Background(), tenancy.Tenant{ID: id}), req)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("should_erase_the_cached_paths_of_a_traveler", func(t *testing.T) {
+		mockGateway.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(dto.Path{
+			Airports: []string{"SFO", "ATL"},
+		}, nil).Times(2)
+		mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+
+		pathCache := newCache(t)
+		index := newIndex(t, pathCache)
+		m, err := mediators.NewFlightTracker(logger, mockGateway, mockPublisher, pathCache, index, duplicates.DefaultPolicy)
+		require.NoError(t, err)
+
+		req := models.PathRequest{UserID: "u-1", Flights: [][]string{{"SFO", "ATL"}}}
+		_, err = m.GetFlightsPath(context.Background(), req)
+		require.NoError(t, err)
+
+		erased, err := index.Erase(context.Background(), "u-1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, erased)
+
+		for i := 0; i < 2; i++ {
+			_, err = m.GetFlightsPath(context.Background(), req)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("should_not_cache_errors", func(t *testing.T) {
+		mockGateway.EXPECT().GetFlightsPath(gomock.Any(), gomock.Any()).Return(dto.Path{}, errors.New("internal server error")).Times(2)
+		mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+		pathCache := newCache(t)
+		m, err := mediators.NewFlightTracker(logger, mockGateway, mockPublisher, pathCache, newIndex(t, pathCache), duplicates.DefaultPolicy)
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			_, err = m.GetFlightsPath(context.Background(), models.PathRequest{Flights: [][]string{{"SFO", "SFO"}}})
+			assert.Error(t, err, "internal server error")
+		}
+	})
+}
+
+func TestMediators_GetFlightsPath_Duplicates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		logger        = log.NewEntry(log.New())
+		mockGateway   = mock_flightTracker_gateway.NewMockFlightTracker(ctrl)
+		mockPublisher = mock_webhooks.NewMockPublisher(ctrl)
+		req           = models.PathRequest{Flights: [][]string{{"SFO", "ATL"}, {"ATL", "EWR"}, {"SFO", "ATL"}}}
+	)
+
+	t.Run("should_merge_the_duplicate_legs", func(t *testing.T) {
+		mockGateway.EXPECT().GetFlightsPath(gomock.Any(), models.PathRequest{Flights: [][]string{{"SFO", "ATL"}, {"ATL", "EWR"}}}).
+			Return(dto.Path{Airports: []string{"SFO", "ATL", "EWR"}}, nil)
+		mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any())
+
+		pathCache := newCache(t)
+		m, err := mediators.NewFlightTracker(logger, mockGateway, mockPublisher, pathCache, newIndex(t, pathCache), duplicates.DefaultPolicy)
+		require.NoError(t, err)
+
+		path, err := m.GetFlightsPath(context.Background(), req)
+		require.NoError(t, err)
+
+		assert.DeepEqual(t, []dto.DuplicateLegs{{Origin: "SFO", Destination: "ATL", Legs: []int{0, 2}, Merged: true}}, path.Duplicates)
+	})
+
+	t.Run("failure_response_when_the_tenant_rejects_the_duplicate_legs", func(t *testing.T) {
+		mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any())
+
+		pathCache := newCache(t)
+		m, err := mediators.NewFlightTracker(logger, mockGateway, mockPublisher, pathCache, newIndex(t, pathCache), duplicates.PolicyKeep)
+		require.NoError(t, err)
+
+		ctx := tenancy.NewContext(context.Background(), tenancy.Tenant{ID: "acme", DuplicateLegs: duplicates.PolicyReject})
+		_, err = m.GetFlightsPath(ctx, req)
+
+		var rejected *duplicates.Error
+		require.True(t, errors.As(err, &rejected))
+		assert.DeepEqual(t, []dto.DuplicateLegs{{Origin: "SFO", Destination: "ATL", Legs: []int{0, 2}}}, rejected.Duplicates)
+	})
+}
+
+func TestMediators_DiffFlightsPaths(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		logger      = log.NewEntry(log.New())
+		mockGateway = mock_flightTracker_gateway.NewMockFlightTracker(ctrl)
+		previous    = models.PathRequest{Flights: [][]string{{"SFO", "ATL"}, {"ATL", "EWR"}}}
+		current     = models.PathRequest{Flights: [][]string{{"SFO", "ORD"}, {"ORD", "EWR"}}}
+	)
+
+	t.Run("should_return_the_differences_without_publishing", func(t *testing.T) {
+		mockGateway.EXPECT().GetFlightsPath(gomock.Any(), previous).Return(dto.Path{
+			Airports: []string{"SFO", "ATL", "EWR"},
+			Legs:     []dto.Leg{{Origin: "SFO", Destination: "ATL"}, {Origin: "ATL", Destination: "EWR"}},
+		}, nil)
+		mockGateway.EXPECT().GetFlightsPath(gomock.Any(), current).Return(dto.Path{
+			Airports: []string{"SFO", "ORD", "EWR"},
+			Legs:     []dto.Leg{{Origin: "SFO", Destination: "ORD"}, {Origin: "ORD", Destination: "EWR"}},
+		}, nil)
+
+		pathCache := newCache(t)
+		m, err := mediators.NewFlightTracker(logger, mockGateway, mock_webhooks.NewMockPublisher(ctrl), pathCache, newIndex(t, pathCache), duplicates.DefaultPolicy)
+		require.NoError(t, err)
+
+		diff, err := m.DiffFlightsPaths(context.Background(), previous, current)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, len(diff.Added))
+		assert.Equal(t, 2, len(diff.Removed))
+		assert.Assert(t, !diff.StartChanged && !diff.EndChanged)
+	})
+
+	t.Run("failure_response_when_a_version_has_no_path", func(t *testing.T) {
+		mockGateway.EXPECT().GetFlightsPath(gomock.Any(), previous).
+			Return(dto.Path{}, errors.New("disconnections detected between flights: [GSO IND]"))
+
+		pathCache := newCache(t)
+		m, err := mediators.NewFlightTracker(logger, mockGateway, mock_webhooks.NewMockPublisher(ctrl), pathCache, newIndex(t, pathCache), duplicates.DefaultPolicy)
+		require.NoError(t, err)
+
+		_, err = m.DiffFlightsPaths(context.Background(), previous, current)
+		assert.Error(t, err, "old itinerary: disconnections detected between flights: [GSO IND]")
+		var versionErr *mediators.VersionError
+		require.True(t, errors.As(err, &versionErr))
+		assert.Equal(t, mediators.VersionOld, versionErr.Version)
+	})
+}
+
+func newCache(t *testing.T) cache.Backend {
+	lru, err := cache.NewLRU(10, time.Minute)
+	require.NoError(t, err)
+	return lru
+}
+
+func newIndex(t *testing.T, pathCache cache.Backend) *privacy.CacheIndex {
+	index, err := privacy.NewCacheIndex(pathCache, time.Minute)
+	require.NoError(t, err)
+	return index
+}
@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// labelSeparator joins the label values of a series, it can't appear in valid UTF-8 text
+const labelSeparator = "\xff"
+
+// Registry holds the counters exposed in the Prometheus text format
+type Registry struct {
+	mu       sync.Mutex
+	counters []*Counter
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Counter registers a monotonic counter, labels name the dimensions of its series
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	c := &Counter{
+		Name:   name,
+		Help:   help,
+		Labels: labels,
+		values: make(map[string]uint64),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters = append(r.counters, c)
+
+	return c
+}
+
+// WriteText writes every counter in the Prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	counters := append([]*Counter(nil), r.counters...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range counters {
+		fmt.Fprintf(bw, "# HELP %s %s\n", c.Name, escape(c.Help, false))
+		fmt.Fprintf(bw, "# TYPE %s counter\n", c.Name)
+		for _, s := range c.series() {
+			fmt.Fprintf(bw, "%s%s %d\n", c.Name, s.labels, s.value)
+		}
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP answers with the metrics of the registry
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = r.WriteText(w)
+}
+
+// Counter is a monotonic counter split in series by its labels
+type Counter struct {
+	Name   string
+	Help   string
+	Labels []string
+
+	mu     sync.Mutex
+	values map[string]uint64
+}
+
+// Inc adds one to the series identified by the label values, given in the order of Labels
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds n to the series identified by the label values, given in the order of Labels
+func (c *Counter) Add(n uint64, labelValues ...string) {
+	key := strings.Join(labelValues, labelSeparator)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] += n
+}
+
+// Value returns the current value of the series identified by the label values
+func (c *Counter) Value(labelValues ...string) uint64 {
+	key := strings.Join(labelValues, labelSeparator)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[key]
+}
+
+type series struct {
+	labels string
+	value  uint64
+}
+
+// series returns the formatted series of the counter, sorted by labels
+func (c *Counter) series() []series {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make([]series, 0, len(c.values))
+	for key, value := range c.values {
+		result = append(result, series{labels: c.formatLabels(key), value: value})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].labels < result[j].labels })
+
+	return result
+}
+
+func (c *Counter) formatLabels(key string) string {
+	if len(c.Labels) == 0 {
+		return ""
+	}
+
+	values := strings.Split(key, labelSeparator)
+	pairs := make([]string, len(c.Labels))
+	for i, name := range c.Labels {
+		var value string
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs[i] = name + `="` + escape(value, true) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// escape escapes the backslashes and line feeds of help texts, and the double quotes of label values
+func escape(s string, quotes bool) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	if quotes {
+		s = strings.ReplaceAll(s, `"`, `\"`)
+	}
+	return s
+}
@@ -0,0 +1,49 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/metrics"
+)
+
+func TestMetrics_WriteText(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	requests := registry.Counter("cache_requests_total", "Lookups of the cache.", "result")
+	requests.Inc("miss")
+	requests.Add(2, "hit")
+	requests.Inc(`a"b`)
+	registry.Counter("restarts_total", "Restarts.\nEver.").Inc()
+
+	var b strings.Builder
+	require.NoError(t, registry.WriteText(&b))
+
+	want := `# HELP cache_requests_total Lookups of the cache.
+# TYPE cache_requests_total counter
+cache_requests_total{result="a\"b"} 1
+cache_requests_total{result="hit"} 2
+cache_requests_total{result="miss"} 1
+# HELP restarts_total Restarts.\nEver.
+# TYPE restarts_total counter
+restarts_total 1
+`
+	assert.Equal(t, want, b.String())
+}
+
+func TestMetrics_ServeHTTP(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.Counter("restarts_total", "Restarts.").Inc()
+
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Assert(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain"))
+	assert.Assert(t, strings.Contains(recorder.Body.String(), "restarts_total 1\n"))
+}
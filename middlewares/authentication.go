@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/logging"
+)
+
+// Authentication rejects requests whose caller can not be identified by any of the authenticators
+type Authentication struct {
+	Logger         *log.Entry
+	Authenticators []auth.Authenticator
+}
+
+// NewAuthentication returns a new instance of the Authentication middleware
+func NewAuthentication(log *log.Entry, authenticators ...auth.Authenticator) (*Authentication, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case len(authenticators) == 0:
+		return nil, errors.New("authenticators")
+	}
+
+	return &Authentication{
+		Logger:         log,
+		Authenticators: authenticators,
+	}, nil
+}
+
+// Handle wraps next, injecting the caller identity into the request context
+func (m *Authentication) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, authenticator := range m.Authenticators {
+			identity, err := authenticator.Authenticate(r)
+			if errors.Is(err, auth.ErrNoCredentials) {
+				continue
+			}
+			if err != nil {
+				logging.FromContext(r.Context(), m.Logger).WithError(err).Warn("authentication failed")
+				unauthorized(w)
+				return
+			}
+
+			logging.AddFields(r.Context(), log.Fields{logging.FieldCaller: identity.Subject})
+			next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), identity)))
+			return
+		}
+
+		unauthorized(w)
+	})
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer, ApiKey`)
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
@@ -0,0 +1,109 @@
+package middlewares_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/config"
+	"github.com/volume/service/user-flight-tracking/middlewares"
+)
+
+func TestMiddlewares_NewAuthentication(t *testing.T) {
+	authenticator, err := auth.NewAPIKeyAuthenticator([]config.APIKey{{ID: "batch", Hash: auth.HashAPIKey("secret")}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		logger         *log.Entry
+		authenticators []auth.Authenticator
+		wantError      error
+	}{
+		{
+			name:           "should_return_success",
+			logger:         log.NewEntry(nil),
+			authenticators: []auth.Authenticator{authenticator},
+			wantError:      nil,
+		},
+		{
+			name:           "should_return_error_when_the_logger_is_nil",
+			logger:         nil,
+			authenticators: []auth.Authenticator{authenticator},
+			wantError:      errors.New("logger"),
+		},
+		{
+			name:           "should_return_error_when_there_are_no_authenticators",
+			logger:         log.NewEntry(nil),
+			authenticators: nil,
+			wantError:      errors.New("authenticators"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := middlewares.NewAuthentication(tt.logger, tt.authenticators...)
+			if err != nil {
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+			}
+		})
+	}
+}
+
+func TestMiddlewares_AuthenticationHandle(t *testing.T) {
+	authenticator, err := auth.NewAPIKeyAuthenticator([]config.APIKey{{ID: "batch", Hash: auth.HashAPIKey("secret")}})
+	require.NoError(t, err)
+
+	m, err := middlewares.NewAuthentication(log.NewEntry(log.New()), authenticator)
+	require.NoError(t, err)
+
+	var caller string
+	handler := m.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ := auth.FromContext(r.Context())
+		caller = identity.Subject
+	}))
+
+	tests := []struct {
+		name       string
+		key        string
+		wantStatus int
+		wantCaller string
+	}{
+		{
+			name:       "should_inject_identity_when_the_key_is_valid",
+			key:        "secret",
+			wantStatus: http.StatusOK,
+			wantCaller: "batch",
+		},
+		{
+			name:       "should_return_unauthorized_when_the_key_is_invalid",
+			key:        "other",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "should_return_unauthorized_without_credentials",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller = ""
+			request := httptest.NewRequest(http.MethodPost, "/calculate", nil)
+			if tt.key != "" {
+				request.Header.Set(auth.APIKeyHeader, tt.key)
+			}
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.wantStatus, recorder.Code)
+			assert.Equal(t, tt.wantCaller, caller)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/idempotency"
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/privacy"
+)
+
+// volatileHeaders describe a single request rather than its outcome, they are not replayed
+var volatileHeaders = []string{
+	RequestIDHeader,
+	"RateLimit-Limit",
+	"RateLimit-Remaining",
+	"RateLimit-Reset",
+	"X-Quota-Limit",
+	"X-Quota-Remaining",
+}
+
+// validIdempotencyKey restricts the keys to printable ASCII values
+var validIdempotencyKey = regexp.MustCompile(`^[\x21-\x7e]{1,255}$`)
+
+// Idempotency replays the stored response of a state-changing request retried with the same Idempotency-Key
+type Idempotency struct {
+	Logger *log.Entry
+	Store  idempotency.Store
+	// Index records the keys of the responses holding the data of a traveler, tracked by idempotency.Track
+	Index privacy.Tracker
+	// Window is how long the response of a key is kept
+	Window time.Duration
+	Now    func() time.Time
+}
+
+// NewIdempotency returns a new instance of the Idempotency middleware
+func NewIdempotency(log *log.Entry, store idempotency.Store, index privacy.Tracker, window time.Duration) (*Idempotency, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case store == nil:
+		return nil, errors.New("store")
+	case index == nil:
+		return nil, errors.New("index")
+	case window <= 0:
+		return nil, errors.New("window")
+	}
+
+	return &Idempotency{
+		Logger: log,
+		Store:  store,
+		Index:  index,
+		Window: window,
+		Now:    time.Now,
+	}, nil
+}
+
+// Handle wraps next, storing the first response of every key of the caller and replaying it on retries.
+// A key reused with a different request is answered 422 Unprocessable Entity, and a retry arriving while
+// the first request is in flight 409 Conflict.
+func (m *Idempotency) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotency.KeyHeader)
+		if key == "" || !stateChanging(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		logger := logging.FromContext(r.Context(), m.Logger).WithField("idempotency_key", key)
+		if !validIdempotencyKey.MatchString(key) {
+			http.Error(w, "Bad Request: invalid "+idempotency.KeyHeader, http.StatusBadRequest)
+			return
+		}
+
+		fingerprint, err := requestFingerprint(r)
+		if err != nil {
+			logger.WithError(err).Error("error reading request body")
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+
+		ctx := r.Context()
+		scope := ClientKey(r) + "|" + key
+		record, reserved, err := m.Store.Reserve(ctx, scope, fingerprint, m.Now().Add(m.Window))
+		if err != nil {
+			logger.WithError(err).Error("error reserving idempotency key")
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		if !reserved {
+			switch {
+			case record.Fingerprint != fingerprint:
+				logger.Warn("idempotency key reused with a different request")
+				http.Error(w, "Unprocessable Entity: "+idempotency.KeyHeader+" already used with a different request", http.StatusUnprocessableEntity)
+			case record.Response == nil:
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, "Conflict: a request with this "+idempotency.KeyHeader+" is in progress", http.StatusConflict)
+			default:
+				logger.Debug("replaying idempotent response")
+				replay(w, *record.Response)
+			}
+			return
+		}
+
+		rw := newRecordingWriter(w)
+		completed := false
+		defer func() {
+			// the key is released when the request panics or fails, so that it can be retried
+			if !completed {
+				if err := m.Store.Release(ctx, scope); err != nil {
+					logger.WithError(err).Error("error releasing idempotency key")
+				}
+			}
+		}()
+
+		r = r.WithContext(idempotency.NewContext(ctx))
+		next.ServeHTTP(rw, r)
+
+		if rw.status >= http.StatusInternalServerError || rw.status == http.StatusTooManyRequests {
+			return
+		}
+		if err := m.Store.Complete(ctx, scope, rw.response()); err != nil {
+			logger.WithError(err).Error("error storing idempotent response")
+			return
+		}
+		completed = true
+		for _, userID := range idempotency.Subjects(r.Context()) {
+			m.Index.Track(ctx, userID, scope)
+		}
+	})
+}
+
+// stateChanging reports whether requests with method change the state of the service
+func stateChanging(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestFingerprint returns the hex SHA-256 of the method, path and body of r, leaving the body readable
+func requestFingerprint(r *http.Request) (string, error) {
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return "", err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	h := sha256.New()
+	_, _ = io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+	_, _ = h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// replay writes a stored response, keeping the headers already set for this request
+func replay(w http.ResponseWriter, response idempotency.Response) {
+	header := w.Header()
+	for name, values := range response.Header {
+		if _, ok := header[name]; !ok {
+			header[name] = values
+		}
+	}
+	header.Set(idempotency.ReplayedHeader, "true")
+
+	w.WriteHeader(response.Status)
+	_, _ = w.Write(response.Body)
+}
+
+// recordingWriter copies the response written by the next handlers
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	header      http.Header
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func newRecordingWriter(w http.ResponseWriter) *recordingWriter {
+	return &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = status
+		w.header = w.ResponseWriter.Header().Clone()
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *recordingWriter) response() idempotency.Response {
+	header := w.header
+	if !w.wroteHeader {
+		header = w.ResponseWriter.Header().Clone()
+	}
+	for _, name := range volatileHeaders {
+		header.Del(name)
+	}
+	return idempotency.Response{Status: w.status, Header: header, Body: w.body.Bytes()}
+}
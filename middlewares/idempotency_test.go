@@ -0,0 +1,192 @@
+package middlewares_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/idempotency"
+	"github.com/volume/service/user-flight-tracking/middlewares"
+	"github.com/volume/service/user-flight-tracking/privacy"
+)
+
+func TestMiddlewares_NewIdempotency(t *testing.T) {
+	store := idempotency.NewMemoryStore()
+	index := newIdempotencyIndex(t, store)
+
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		store     idempotency.Store
+		index     privacy.Tracker
+		window    time.Duration
+		wantError error
+	}{
+		{name: "should_return_success", logger: log.NewEntry(nil), store: store, index: index, window: time.Hour},
+		{name: "should_return_error_when_the_logger_is_nil", store: store, index: index, window: time.Hour, wantError: errors.New("logger")},
+		{name: "should_return_error_when_the_store_is_nil", logger: log.NewEntry(nil), index: index, window: time.Hour, wantError: errors.New("store")},
+		{name: "should_return_error_when_the_index_is_nil", logger: log.NewEntry(nil), store: store, window: time.Hour, wantError: errors.New("index")},
+		{name: "should_return_error_when_the_window_is_not_positive", logger: log.NewEntry(nil), store: store, index: index, wantError: errors.New("window")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := middlewares.NewIdempotency(tt.logger, tt.store, tt.index, tt.window)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestMiddlewares_IdempotencyHandle(t *testing.T) {
+	logger := log.NewEntry(log.New())
+
+	// newHandler returns the middleware wrapping a handler counting its calls and answering status
+	newHandler := func(t *testing.T, status int) (http.Handler, *int32) {
+		store := idempotency.NewMemoryStore()
+		m, err := middlewares.NewIdempotency(logger, store, newIdempotencyIndex(t, store), time.Hour)
+		require.NoError(t, err)
+
+		var calls int32
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&calls, 1)
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Location", "/jobs/1")
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(string(body) + strings.Repeat("!", int(n))))
+		})
+		return m.Handle(next), &calls
+	}
+
+	serve := func(handler http.Handler, method, key, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, "/jobs", strings.NewReader(body))
+		if key != "" {
+			request.Header.Set(idempotency.KeyHeader, key)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("should_replay_the_first_response_of_a_key", func(t *testing.T) {
+		handler, calls := newHandler(t, http.StatusAccepted)
+
+		first := serve(handler, http.MethodPost, "k-1", "body")
+		retry := serve(handler, http.MethodPost, "k-1", "body")
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+		assert.Equal(t, http.StatusAccepted, retry.Code)
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, "/jobs/1", retry.Header().Get("Location"))
+		assert.Equal(t, "true", retry.Header().Get(idempotency.ReplayedHeader))
+		assert.Equal(t, "", first.Header().Get(idempotency.ReplayedHeader))
+	})
+
+	t.Run("failure_response_when_the_key_is_reused_with_a_different_body", func(t *testing.T) {
+		handler, calls := newHandler(t, http.StatusOK)
+
+		serve(handler, http.MethodPost, "k-1", "body")
+		recorder := serve(handler, http.MethodPost, "k-1", "other body")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+
+	t.Run("should_not_store_server_errors", func(t *testing.T) {
+		handler, calls := newHandler(t, http.StatusServiceUnavailable)
+
+		serve(handler, http.MethodPost, "k-1", "body")
+		serve(handler, http.MethodPost, "k-1", "body")
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+	})
+
+	t.Run("should_ignore_requests_without_key_or_safe_methods", func(t *testing.T) {
+		handler, calls := newHandler(t, http.StatusOK)
+
+		serve(handler, http.MethodPost, "", "body")
+		serve(handler, http.MethodPost, "", "body")
+		serve(handler, http.MethodGet, "k-1", "")
+		serve(handler, http.MethodGet, "k-1", "")
+
+		assert.Equal(t, int32(4), atomic.LoadInt32(calls))
+	})
+
+	t.Run("failure_response_when_the_key_is_invalid", func(t *testing.T) {
+		handler, calls := newHandler(t, http.StatusOK)
+
+		recorder := serve(handler, http.MethodPost, "bad key", "body")
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, int32(0), atomic.LoadInt32(calls))
+	})
+
+	t.Run("failure_response_when_the_first_request_is_in_flight", func(t *testing.T) {
+		store := idempotency.NewMemoryStore()
+		m, err := middlewares.NewIdempotency(logger, store, newIdempotencyIndex(t, store), time.Hour)
+		require.NoError(t, err)
+
+		started, release := make(chan struct{}), make(chan struct{})
+		handler := m.Handle(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			close(started)
+			<-release
+		}))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			serve(handler, http.MethodDelete, "k-1", "")
+		}()
+		<-started
+
+		recorder := serve(handler, http.MethodDelete, "k-1", "")
+		close(release)
+		<-done
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+		assert.Equal(t, "1", recorder.Header().Get("Retry-After"))
+	})
+
+	t.Run("should_erase_the_responses_of_a_traveler", func(t *testing.T) {
+		store := idempotency.NewMemoryStore()
+		index := newIdempotencyIndex(t, store)
+		m, err := middlewares.NewIdempotency(logger, store, index, time.Hour)
+		require.NoError(t, err)
+
+		var calls int32
+		handler := m.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			idempotency.Track(r.Context(), "u-1")
+		}))
+
+		serve(handler, http.MethodPost, "k-1", "body")
+		erased, err := index.Erase(context.Background(), "u-1")
+		require.NoError(t, err)
+		retry := serve(handler, http.MethodPost, "k-1", "body")
+
+		assert.Equal(t, 1, erased)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "the erased response should not be replayed")
+		assert.Equal(t, "", retry.Header().Get(idempotency.ReplayedHeader))
+	})
+}
+
+// newIdempotencyIndex returns the index of the responses of store by traveler
+func newIdempotencyIndex(t *testing.T, store idempotency.Store) *privacy.CacheIndex {
+	index, err := privacy.NewCacheIndex(privacy.DeleterFunc(store.Release), time.Hour)
+	require.NoError(t, err)
+	return index
+}
@@ -0,0 +1,208 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/ratelimit"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+// maxCountedBody is the largest request body whose legs are counted against the quota
+const maxCountedBody = 10 << 20
+
+// RateLimit rejects the requests of clients exceeding their request rate or daily leg quota
+type RateLimit struct {
+	Logger *log.Entry
+	// Default applies to the routes without a limiter in Routes, nil means unlimited
+	Default ratelimit.Limiter
+	// Routes holds the limiters by route template
+	Routes map[string]ratelimit.Limiter
+	// Quota limits the flight legs submitted per client and day, nil means unlimited
+	Quota *ratelimit.Quota
+}
+
+// NewRateLimit returns a new instance of the RateLimit middleware
+func NewRateLimit(log *log.Entry, defaultLimiter ratelimit.Limiter, routes map[string]ratelimit.Limiter, quota *ratelimit.Quota) (*RateLimit, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case defaultLimiter == nil && len(routes) == 0 && quota == nil:
+		return nil, errors.New("limiters")
+	}
+
+	return &RateLimit{
+		Logger:  log,
+		Default: defaultLimiter,
+		Routes:  routes,
+		Quota:   quota,
+	}, nil
+}
+
+// Handle wraps next, answering 429 Too Many Requests once a client exceeds its limits
+func (m *RateLimit) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context(), m.Logger)
+		client := ClientKey(r)
+		route := routeTemplate(r)
+
+		limiter := m.Default
+		if l, ok := m.Routes[route]; ok {
+			limiter = l
+		}
+
+		if limiter != nil {
+			decision := limiter.Allow(route + "|" + client)
+			setRateLimitHeaders(w.Header(), decision)
+			if !decision.Allowed {
+				logger.WithField("client", client).WithField("route", route).Warn("rate limit exceeded")
+				tooManyRequests(w, decision)
+				return
+			}
+		}
+
+		if m.Quota != nil {
+			legs, err := countLegs(w, r)
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					logger.WithField("client", client).WithField("limit", tooLarge.Limit).Warn("request body too large")
+					http.Error(w, "Request Entity Too Large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				logger.WithError(err).Error("error reading request body")
+				http.Error(w, "Bad Request", http.StatusBadRequest)
+				return
+			}
+
+			if legs > 0 {
+				decision, err := m.Quota.Consume(r.Context(), client, legs)
+				if err != nil {
+					logger.WithError(err).Error("error consuming quota")
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					return
+				}
+
+				w.Header().Set("X-Quota-Limit", strconv.Itoa(decision.Limit))
+				w.Header().Set("X-Quota-Remaining", strconv.Itoa(decision.Remaining))
+				if !decision.Allowed {
+					logger.WithField("client", client).WithField("legs", legs).Warn("daily leg quota exceeded")
+					tooManyRequests(w, decision)
+					return
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ClientKey identifies the caller of a request by its authenticated identity or, if anonymous, by its IP,
+// scoped by its tenant
+func ClientKey(r *http.Request) string {
+	if identity, ok := auth.FromContext(r.Context()); ok {
+		return tenancy.Scope(r.Context(), identity.Method+":"+identity.Subject)
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return tenancy.Scope(r.Context(), "ip:"+host)
+}
+
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return r.URL.Path
+}
+
+// countLegs returns the number of flight legs of the request body, leaving the body readable for the next handler.
+// The legs are counted before the request is validated, the requests later rejected with 400 Bad Request are charged
+// too. Bodies larger than maxCountedBody fail with an *http.MaxBytesError
+func countLegs(w http.ResponseWriter, r *http.Request) (int, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return 0, nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCountedBody))
+	if err != nil {
+		return 0, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/csv" {
+		// uploads that can't be parsed are rejected by the controllers
+		flights, err := models.CountCSVFlights(bytes.NewReader(body))
+		if err != nil {
+			return 0, nil
+		}
+		return flights, nil
+	}
+
+	var request legCount
+	// bodies that can't be decoded are rejected by the controllers
+	if err := json.Unmarshal(body, &request); err != nil {
+		return 0, nil
+	}
+
+	return request.total(), nil
+}
+
+// legCount holds the legs of a request body, with those of the itineraries it nests
+type legCount struct {
+	Flights []json.RawMessage `json:"flights"`
+	Legs    []json.RawMessage `json:"legs"`
+	// Sources holds the itineraries of POST /reconcile
+	Sources []legCount `json:"sources"`
+	// Old and New hold the versions of the itinerary of POST /diff
+	Old *legCount `json:"old"`
+	New *legCount `json:"new"`
+}
+
+// total returns the number of legs of the body
+func (c legCount) total() int {
+	total := len(c.Flights) + len(c.Legs)
+	for _, source := range c.Sources {
+		total += source.total()
+	}
+	for _, version := range []*legCount{c.Old, c.New} {
+		if version != nil {
+			total += version.total()
+		}
+	}
+	return total
+}
+
+func setRateLimitHeaders(h http.Header, decision ratelimit.Decision) {
+	h.Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(seconds(decision.Reset)))
+}
+
+func tooManyRequests(w http.ResponseWriter, decision ratelimit.Decision) {
+	w.Header().Set("Retry-After", strconv.Itoa(seconds(decision.RetryAfter)))
+	http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+}
+
+// seconds rounds d up to whole seconds, as expected by the Retry-After and RateLimit-Reset headers
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,167 @@
+package middlewares_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/middlewares"
+	"github.com/volume/service/user-flight-tracking/ratelimit"
+)
+
+func TestMiddlewares_NewRateLimit(t *testing.T) {
+	limiter, err := ratelimit.NewTokenBucket(1, 1)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		limiter   ratelimit.Limiter
+		wantError error
+	}{
+		{
+			name:      "should_return_success",
+			logger:    log.NewEntry(nil),
+			limiter:   limiter,
+			wantError: nil,
+		},
+		{
+			name:      "should_return_error_when_the_logger_is_nil",
+			logger:    nil,
+			limiter:   limiter,
+			wantError: errors.New("logger"),
+		},
+		{
+			name:      "should_return_error_when_there_are_no_limiters",
+			logger:    log.NewEntry(nil),
+			limiter:   nil,
+			wantError: errors.New("limiters"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := middlewares.NewRateLimit(tt.logger, tt.limiter, nil, nil)
+			if err != nil {
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+			}
+		})
+	}
+}
+
+func TestMiddlewares_RateLimitHandle(t *testing.T) {
+	logger := log.NewEntry(log.New())
+
+	newRouter := func(m *middlewares.RateLimit) http.Handler {
+		router := mux.NewRouter()
+		router.Use(m.Handle)
+		router.HandleFunc("/calculate", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodPost)
+		return router
+	}
+
+	t.Run("should_reject_requests_over_the_route_limit", func(t *testing.T) {
+		limiter, err := ratelimit.NewTokenBucket(0.5, 1)
+		require.NoError(t, err)
+		m, err := middlewares.NewRateLimit(logger, nil, map[string]ratelimit.Limiter{"/calculate": limiter}, nil)
+		require.NoError(t, err)
+		router := newRouter(m)
+
+		first := httptest.NewRecorder()
+		router.ServeHTTP(first, httptest.NewRequest(http.MethodPost, "/calculate", nil))
+		second := httptest.NewRecorder()
+		router.ServeHTTP(second, httptest.NewRequest(http.MethodPost, "/calculate", nil))
+
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, "1", first.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "0", first.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "2", first.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, http.StatusTooManyRequests, second.Code)
+		assert.Equal(t, "2", second.Header().Get("Retry-After"))
+	})
+
+	t.Run("should_reject_requests_over_the_daily_leg_quota", func(t *testing.T) {
+		quota, err := ratelimit.NewQuota(ratelimit.NewMemoryQuotaStore(), 3)
+		require.NoError(t, err)
+		m, err := middlewares.NewRateLimit(logger, nil, nil, quota)
+		require.NoError(t, err)
+		router := newRouter(m)
+
+		body := `{"flights": [["SFO", "ATL"], ["ATL", "EWR"]]}`
+		first := httptest.NewRecorder()
+		router.ServeHTTP(first, httptest.NewRequest(http.MethodPost, "/calculate", strings.NewReader(body)))
+		second := httptest.NewRecorder()
+		router.ServeHTTP(second, httptest.NewRequest(http.MethodPost, "/calculate", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, "1", first.Header().Get("X-Quota-Remaining"))
+		assert.Equal(t, http.StatusTooManyRequests, second.Code)
+		assert.Assert(t, second.Header().Get("Retry-After") != "")
+	})
+
+	t.Run("should_count_the_legs_of_the_nested_itineraries", func(t *testing.T) {
+		tests := []struct {
+			name          string
+			body          string
+			wantRemaining string
+		}{
+			{
+				name:          "sources",
+				body:          `{"sources": [{"name": "booking", "legs": [{"origin": "SFO", "destination": "ATL"}]}, {"name": "feed", "legs": [{"origin": "SFO", "destination": "ATL"}, {"origin": "ATL", "destination": "EWR"}]}]}`,
+				wantRemaining: "7",
+			},
+			{
+				name:          "versions",
+				body:          `{"old": {"flights": [["SFO", "ATL"], ["ATL", "EWR"]]}, "new": {"legs": [{"origin": "SFO", "destination": "EWR"}]}}`,
+				wantRemaining: "7",
+			},
+		}
+		for _, tt := range tests {
+			quota, err := ratelimit.NewQuota(ratelimit.NewMemoryQuotaStore(), 10)
+			require.NoError(t, err)
+			m, err := middlewares.NewRateLimit(logger, nil, nil, quota)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			newRouter(m).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/calculate", strings.NewReader(tt.body)))
+
+			assert.Equal(t, http.StatusOK, recorder.Code, tt.name)
+			assert.Equal(t, tt.wantRemaining, recorder.Header().Get("X-Quota-Remaining"), tt.name)
+		}
+	})
+	t.Run("should_count_the_flight_rows_of_a_csv_upload", func(t *testing.T) {
+		quota, err := ratelimit.NewQuota(ratelimit.NewMemoryQuotaStore(), 10)
+		require.NoError(t, err)
+		m, err := middlewares.NewRateLimit(logger, nil, nil, quota)
+		require.NoError(t, err)
+
+		// the rows are charged before the controllers validate them
+		request := httptest.NewRequest(http.MethodPost, "/calculate", strings.NewReader("Arrival,Departure\nATL,SFO\nEWR,ATL\nBOS,X\n"))
+		request.Header.Set("Content-Type", "text/csv")
+		recorder := httptest.NewRecorder()
+		newRouter(m).ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "7", recorder.Header().Get("X-Quota-Remaining"))
+	})
+
+	t.Run("should_reject_bodies_too_large_to_count", func(t *testing.T) {
+		quota, err := ratelimit.NewQuota(ratelimit.NewMemoryQuotaStore(), 10)
+		require.NoError(t, err)
+		m, err := middlewares.NewRateLimit(logger, nil, nil, quota)
+		require.NoError(t, err)
+
+		body := `{"flights": [["SFO", "ATL"]], "userId": "` + strings.Repeat("a", 10<<20) + `"}`
+		recorder := httptest.NewRecorder()
+		newRouter(m).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/calculate", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+		assert.Equal(t, "", recorder.Header().Get("X-Quota-Remaining"))
+	})
+}
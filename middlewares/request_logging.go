@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/logging"
+)
+
+// RequestIDHeader is the header carrying the correlation id of a request
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID restricts the propagated ids to safe printable values
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestLogging assigns a correlation id to every request and logs its outcome
+type RequestLogging struct {
+	Logger *log.Entry
+}
+
+// NewRequestLogging returns a new instance of the RequestLogging middleware
+func NewRequestLogging(log *log.Entry) (*RequestLogging, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	}
+
+	return &RequestLogging{
+		Logger: log,
+	}, nil
+}
+
+// Handle wraps next, propagating the X-Request-ID header and storing a request scoped log entry in the context
+func (m *RequestLogging) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := logging.WithRequestID(r.Context(), id)
+		ctx = logging.NewContext(ctx, log.Fields{
+			logging.FieldRequestID: id,
+			logging.FieldRoute:     routeTemplate(r),
+		})
+
+		rw := newResponseWriter(w)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		logging.FromContext(ctx, m.Logger).WithFields(log.Fields{
+			"method":             r.Method,
+			logging.FieldStatus:  rw.status,
+			logging.FieldLatency: time.Since(start).Milliseconds(),
+		}).Info("request completed")
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
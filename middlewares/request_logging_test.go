@@ -0,0 +1,95 @@
+package middlewares_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/middlewares"
+)
+
+func TestMiddlewares_NewRequestLogging(t *testing.T) {
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		wantError error
+	}{
+		{
+			name:      "should_return_success",
+			logger:    log.NewEntry(nil),
+			wantError: nil,
+		},
+		{
+			name:      "should_return_error_when_the_logger_is_nil",
+			logger:    nil,
+			wantError: errors.New("logger"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := middlewares.NewRequestLogging(tt.logger)
+			if err != nil {
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+			}
+		})
+	}
+}
+
+func TestMiddlewares_RequestLoggingHandle(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := log.New()
+	logger.SetOutput(&buffer)
+	logger.SetFormatter(&log.JSONFormatter{})
+
+	m, err := middlewares.NewRequestLogging(log.NewEntry(logger))
+	require.NoError(t, err)
+
+	var requestID string
+	router := mux.NewRouter()
+	router.Use(m.Handle)
+	router.HandleFunc("/calculate", func(w http.ResponseWriter, r *http.Request) {
+		requestID = logging.RequestID(r.Context())
+		logging.AddFields(r.Context(), log.Fields{logging.FieldLegs: 4})
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodPost)
+
+	t.Run("should_propagate_the_request_id", func(t *testing.T) {
+		buffer.Reset()
+		request := httptest.NewRequest(http.MethodPost, "/calculate", nil)
+		request.Header.Set(middlewares.RequestIDHeader, "abc-123")
+		recorder := httptest.NewRecorder()
+
+		router.ServeHTTP(recorder, request)
+
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(buffer.Bytes(), &entry))
+
+		assert.Equal(t, "abc-123", recorder.Header().Get(middlewares.RequestIDHeader))
+		assert.Equal(t, "abc-123", requestID)
+		assert.Equal(t, "abc-123", entry[logging.FieldRequestID])
+		assert.Equal(t, "/calculate", entry[logging.FieldRoute])
+		assert.Equal(t, float64(http.StatusNotFound), entry[logging.FieldStatus])
+		assert.Equal(t, float64(4), entry[logging.FieldLegs])
+	})
+
+	t.Run("should_assign_a_request_id_when_missing_or_invalid", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/calculate", nil)
+		request.Header.Set(middlewares.RequestIDHeader, "bad id\n")
+		recorder := httptest.NewRecorder()
+
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, 32, len(recorder.Header().Get(middlewares.RequestIDHeader)))
+		assert.Equal(t, recorder.Header().Get(middlewares.RequestIDHeader), requestID)
+	})
+}
@@ -0,0 +1,98 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/metrics"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+// Tenancy resolves the tenant of every request, rejecting unknown tenants and the features they don't have
+type Tenancy struct {
+	Logger  *log.Entry
+	Tenants *tenancy.Registry
+	// Features holds the optional feature of each route template
+	Features map[string]string
+	// Requests counts the requests by tenant, route and status code
+	Requests *metrics.Counter
+}
+
+// NewTenancy returns a new instance of the Tenancy middleware
+func NewTenancy(log *log.Entry, tenants *tenancy.Registry, features map[string]string, requests *metrics.Counter) (*Tenancy, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case tenants == nil:
+		return nil, errors.New("tenants")
+	case requests == nil:
+		return nil, errors.New("requests")
+	}
+
+	return &Tenancy{
+		Logger:   log,
+		Tenants:  tenants,
+		Features: features,
+		Requests: requests,
+	}, nil
+}
+
+// Handle wraps next, storing the tenant in the request context. The tenant is the one bound to the
+// credentials of the caller or, for the unbound admins, the one named by the X-Tenant-ID header.
+// Once tenants are configured, the other unbound callers are rejected; until then, they are served
+// outside of any tenant.
+func (m *Tenancy) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := logging.FromContext(r.Context(), m.Logger)
+		route := routeTemplate(r)
+
+		id := r.Header.Get(tenancy.Header)
+		identity, _ := auth.FromContext(r.Context())
+		switch {
+		case identity.Tenant != "":
+			if id != "" && id != identity.Tenant {
+				logger.WithField(logging.FieldTenant, id).Warn("tenant not allowed for the credentials")
+				http.Error(w, "Forbidden: tenant not allowed", http.StatusForbidden)
+				return
+			}
+			id = identity.Tenant
+		case identity.HasRole(auth.RoleAdmin):
+			// the operators act on behalf of the tenant they name, if any
+		case id != "":
+			logger.WithField(logging.FieldTenant, id).Warn("tenant header not allowed for the credentials")
+			http.Error(w, "Forbidden: tenant not allowed", http.StatusForbidden)
+			return
+		case m.Tenants.Len() > 0:
+			logger.Warn("credentials not bound to a tenant")
+			http.Error(w, "Forbidden: credentials not bound to a tenant", http.StatusForbidden)
+			return
+		}
+
+		ctx := r.Context()
+		if id != "" {
+			tenant, ok := m.Tenants.Lookup(id)
+			if !ok {
+				logger.WithField(logging.FieldTenant, id).Warn("unknown tenant")
+				http.Error(w, "Forbidden: unknown tenant", http.StatusForbidden)
+				return
+			}
+			if feature := m.Features[route]; feature != "" && !tenant.Allows(feature) {
+				logger.WithField(logging.FieldTenant, id).WithField("feature", feature).Warn("feature not enabled for the tenant")
+				http.Error(w, "Forbidden: "+feature+" is not enabled for the tenant", http.StatusForbidden)
+				return
+			}
+
+			ctx = tenancy.NewContext(ctx, tenant)
+			logging.AddFields(ctx, log.Fields{logging.FieldTenant: id})
+		}
+
+		rw := newResponseWriter(w)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+		m.Requests.Inc(id, route, strconv.Itoa(rw.status))
+	})
+}
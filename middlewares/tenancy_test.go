@@ -0,0 +1,153 @@
+package middlewares_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/config"
+	"github.com/volume/service/user-flight-tracking/metrics"
+	"github.com/volume/service/user-flight-tracking/middlewares"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+func TestMiddlewares_NewTenancy(t *testing.T) {
+	var (
+		tenants, _ = tenancy.NewRegistry(nil)
+		requests   = metrics.NewRegistry().Counter("requests_total", "Requests.", "tenant", "route", "code")
+	)
+
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		tenants   *tenancy.Registry
+		requests  *metrics.Counter
+		wantError error
+	}{
+		{name: "should_return_success", logger: log.NewEntry(nil), tenants: tenants, requests: requests},
+		{name: "should_return_error_when_the_logger_is_nil", tenants: tenants, requests: requests, wantError: errors.New("logger")},
+		{name: "should_return_error_when_the_tenants_are_nil", logger: log.NewEntry(nil), requests: requests, wantError: errors.New("tenants")},
+		{name: "should_return_error_when_the_requests_counter_is_nil", logger: log.NewEntry(nil), tenants: tenants, wantError: errors.New("requests")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := middlewares.NewTenancy(tt.logger, tt.tenants, nil, tt.requests)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestMiddlewares_TenancyHandle(t *testing.T) {
+	tenants, err := tenancy.NewRegistry([]config.Tenant{
+		{ID: "acme", Features: []string{tenancy.FeatureJobs}},
+		{ID: "globex"},
+	})
+	require.NoError(t, err)
+	requests := metrics.NewRegistry().Counter("requests_total", "Requests.", "tenant", "route", "code")
+
+	m, err := middlewares.NewTenancy(log.NewEntry(log.New()), tenants, map[string]string{
+		"/webhooks": tenancy.FeatureWebhooks,
+	}, requests)
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.Use(m.Handle)
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(tenancy.ID(r.Context())))
+	}
+	router.HandleFunc("/calculate", echo)
+	router.HandleFunc("/webhooks", echo)
+
+	bound := auth.Identity{Subject: "agent", Method: auth.MethodAPIKey, Tenant: "acme"}
+	unbound := auth.Identity{Subject: "support", Method: auth.MethodAPIKey}
+	admin := auth.Identity{Subject: "operator", Method: auth.MethodAPIKey, Roles: []string{auth.RoleAdmin}}
+
+	tests := []struct {
+		name       string
+		target     string
+		identity   *auth.Identity
+		header     string
+		wantStatus int
+		wantTenant string
+	}{
+		{name: "should_resolve_the_tenant_of_the_credentials", target: "/calculate", identity: &bound, wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "should_resolve_the_tenant_of_the_header_for_admins", target: "/calculate", identity: &admin, header: "globex", wantStatus: http.StatusOK, wantTenant: "globex"},
+		{name: "should_serve_admins_without_tenant", target: "/calculate", identity: &admin, wantStatus: http.StatusOK},
+		{name: "should_accept_the_header_matching_the_credentials", target: "/calculate", identity: &bound, header: "acme", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "failure_response_when_the_header_differs_from_the_credentials", target: "/calculate", identity: &bound, header: "globex", wantStatus: http.StatusForbidden},
+		{name: "failure_response_when_unbound_credentials_name_a_tenant", target: "/calculate", identity: &unbound, header: "globex", wantStatus: http.StatusForbidden},
+		{name: "failure_response_when_anonymous_callers_name_a_tenant", target: "/calculate", header: "globex", wantStatus: http.StatusForbidden},
+		{name: "failure_response_when_the_credentials_are_unbound", target: "/calculate", identity: &unbound, wantStatus: http.StatusForbidden},
+		{name: "failure_response_when_the_caller_is_anonymous", target: "/calculate", wantStatus: http.StatusForbidden},
+		{name: "failure_response_when_the_tenant_is_unknown", target: "/calculate", identity: &admin, header: "initech", wantStatus: http.StatusForbidden},
+		{name: "failure_response_when_the_feature_is_not_enabled", target: "/webhooks", identity: &bound, wantStatus: http.StatusForbidden},
+		{name: "should_serve_the_features_enabled", target: "/webhooks", identity: &admin, header: "globex", wantStatus: http.StatusOK, wantTenant: "globex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, tt.target, nil)
+			if tt.identity != nil {
+				request = request.WithContext(auth.NewContext(request.Context(), *tt.identity))
+			}
+			if tt.header != "" {
+				request.Header.Set(tenancy.Header, tt.header)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.wantStatus, recorder.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.wantTenant, recorder.Body.String())
+			}
+		})
+	}
+
+	t.Run("should_count_the_requests_by_tenant", func(t *testing.T) {
+		assert.Equal(t, uint64(2), requests.Value("acme", "/calculate", "200"))
+		assert.Equal(t, uint64(1), requests.Value("", "/calculate", "200"))
+		assert.Equal(t, uint64(1), requests.Value("globex", "/calculate", "200"))
+		assert.Equal(t, uint64(1), requests.Value("globex", "/webhooks", "200"))
+	})
+}
+
+func TestMiddlewares_TenancyHandleWithoutTenants(t *testing.T) {
+	tenants, err := tenancy.NewRegistry(nil)
+	require.NoError(t, err)
+	m, err := middlewares.NewTenancy(log.NewEntry(log.New()), tenants, nil, metrics.NewRegistry().Counter("requests_total", "Requests.", "tenant", "route", "code"))
+	require.NoError(t, err)
+
+	handler := m.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(tenancy.ID(r.Context())))
+	}))
+
+	t.Run("should_serve_anonymous_callers_without_tenant", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/calculate", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "", recorder.Body.String())
+	})
+
+	t.Run("failure_response_when_anonymous_callers_name_a_tenant", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/calculate", nil)
+		request.Header.Set(tenancy.Header, "acme")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+}
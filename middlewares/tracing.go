@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/tracing"
+)
+
+// Tracing starts a server span for every request, continuing the trace received in the traceparent header
+type Tracing struct {
+	Tracer *tracing.Tracer
+}
+
+// NewTracing returns a new instance of the Tracing middleware
+func NewTracing(tracer *tracing.Tracer) (*Tracing, error) {
+	switch {
+	case tracer == nil:
+		return nil, errors.New("tracer")
+	}
+
+	return &Tracing{
+		Tracer: tracer,
+	}, nil
+}
+
+// Handle wraps next, making the tracer and the server span available to the next handlers through the context
+func (m *Tracing) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+
+		ctx := tracing.Extract(r.Context(), r.Header)
+		ctx = tracing.ContextWithTracer(ctx, m.Tracer)
+		ctx, span := m.Tracer.Start(ctx, "HTTP "+r.Method+" "+route, tracing.KindServer)
+		defer span.End()
+
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.route", route)
+		tracing.Inject(ctx, w.Header())
+		logging.AddFields(ctx, log.Fields{"trace_id": span.SpanContext().TraceID.String()})
+
+		rw := newResponseWriter(w)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		span.SetAttribute("http.status_code", rw.status)
+		if rw.status >= http.StatusInternalServerError {
+			span.RecordError(errors.New(http.StatusText(rw.status)))
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package middlewares_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/middlewares"
+	"github.com/volume/service/user-flight-tracking/tracing"
+)
+
+func TestMiddlewares_NewTracing(t *testing.T) {
+	_, err := middlewares.NewTracing(nil)
+	assert.Equal(t, errors.New("tracer").Error(), err.Error())
+}
+
+func TestMiddlewares_TracingHandle(t *testing.T) {
+	var buffer bytes.Buffer
+	exporter, err := tracing.NewStdoutExporter(&buffer)
+	require.NoError(t, err)
+	tracer, err := tracing.NewTracer("user-flight-tracking", exporter)
+	require.NoError(t, err)
+
+	m, err := middlewares.NewTracing(tracer)
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.Use(m.Handle)
+	router.HandleFunc("/calculate", func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracing.StartSpan(r.Context(), "mediator.GetFlightsPath")
+		span.End()
+	}).Methods(http.MethodPost)
+
+	request := httptest.NewRequest(http.MethodPost, "/calculate", nil)
+	request.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var child, server map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &child))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &server))
+
+	assert.Equal(t, "HTTP POST /calculate", server["name"])
+	assert.Equal(t, "00f067aa0ba902b7", server["parent_id"])
+	assert.Equal(t, server["span_id"], child["parent_id"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", child["trace_id"])
+
+	response, err := tracing.ParseTraceparent(recorder.Header().Get(tracing.TraceparentHeader))
+	require.NoError(t, err)
+	assert.Equal(t, server["span_id"], response.SpanID.String())
+}
@@ -36,6 +36,21 @@ func (m *MockFlightTracker) EXPECT() *MockFlightTrackerMockRecorder {
 	return m.recorder
 }
 
+// DiffFlightsPaths mocks base method.
+func (m *MockFlightTracker) DiffFlightsPaths(arg0 context.Context, arg1, arg2 models.PathRequest) (dto.PathDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffFlightsPaths", arg0, arg1, arg2)
+	ret0, _ := ret[0].(dto.PathDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffFlightsPaths indicates an expected call of DiffFlightsPaths.
+func (mr *MockFlightTrackerMockRecorder) DiffFlightsPaths(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffFlightsPaths", reflect.TypeOf((*MockFlightTracker)(nil).DiffFlightsPaths), arg0, arg1, arg2)
+}
+
 // GetFlightsPath mocks base method.
 func (m *MockFlightTracker) GetFlightsPath(arg0 context.Context, arg1 models.PathRequest) (dto.Path, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,17 @@
+package models
+
+// AnomalyReport model, the legs of an itinerary that couldn't physically happen
+type AnomalyReport struct {
+	// Score is the risk of the itinerary, from 0 to 100
+	Score   int             `json:"score"`
+	Reasons []AnomalyReason `json:"reasons"`
+}
+
+// AnomalyReason model
+type AnomalyReason struct {
+	// Kind is "supersonic", "overlap", "teleportation" or "duplicate"
+	Kind string `json:"kind"`
+	// Legs holds the positions of the legs involved in the request, starting at 0
+	Legs    []int  `json:"legs"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// AuditEntryResponse model
+type AuditEntryResponse struct {
+	ID          string    `json:"id"`
+	Time        time.Time `json:"time"`
+	RequestID   string    `json:"requestId,omitempty"`
+	Actor       string    `json:"actor"`
+	Tenant      string    `json:"tenant,omitempty"`
+	Action      string    `json:"action"`
+	UserID      string    `json:"userId,omitempty"`
+	RequestHash string    `json:"requestHash,omitempty"`
+	Outcome     string    `json:"outcome"`
+	Status      int       `json:"status"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// Header names recognized for each CSV column
+var (
+	csvOriginColumns      = []string{"origin", "source", "from", "departure"}
+	csvDestinationColumns = []string{"destination", "dest", "to", "arrival"}
+	csvUserColumns        = []string{"user", "user_id", "userid", "traveler"}
+	csvDateColumns        = []string{"date", "departure_date", "flight_date"}
+)
+
+// CSVColumns holds the indexes of the columns of a CSV upload, -1 for the missing ones
+type CSVColumns struct {
+	Origin, Destination, User, Date int
+}
+
+// NewCSVReader returns a reader of the CSV uploads with one flight per row
+func NewCSVReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return reader
+}
+
+// DetectCSVColumns returns the column indexes found in the header, or the default ones when record is not a header:
+// the first two columns are then the origin and the destination
+func DetectCSVColumns(record []string) (CSVColumns, bool) {
+	columns := CSVColumns{Origin: -1, Destination: -1, User: -1, Date: -1}
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case contains(csvOriginColumns, name):
+			columns.Origin = i
+		case contains(csvDestinationColumns, name):
+			columns.Destination = i
+		case contains(csvUserColumns, name):
+			columns.User = i
+		case contains(csvDateColumns, name):
+			columns.Date = i
+		}
+	}
+
+	if columns.Origin < 0 || columns.Destination < 0 {
+		return CSVColumns{Origin: 0, Destination: 1, User: -1, Date: -1}, false
+	}
+	return columns, true
+}
+
+// CountCSVFlights returns the number of flight rows of a CSV upload, without validating them
+func CountCSVFlights(r io.Reader) (int, error) {
+	reader := NewCSVReader(r)
+	reader.ReuseRecord = true
+
+	var count int
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if _, hasHeader := DetectCSVColumns(record); first && hasHeader {
+			continue
+		}
+		count++
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
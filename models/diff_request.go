@@ -0,0 +1,24 @@
+package models
+
+import validation "github.com/go-ozzo/ozzo-validation"
+
+// DiffRequest model, two versions of an itinerary, e.g. before and after a rebooking
+type DiffRequest struct {
+	Old PathRequest `json:"old"`
+	New PathRequest `json:"new"`
+}
+
+func (dr DiffRequest) Validate() error {
+	return validation.ValidateStruct(&dr,
+		validation.Field(&dr.Old),
+		validation.Field(&dr.New),
+	)
+}
+
+// ValidateStrict validates the request like Validate, additionally requiring IATA codes of three uppercase letters
+func (dr DiffRequest) ValidateStrict() error {
+	return validation.Errors{
+		"old": dr.Old.ValidateStrict(),
+		"new": dr.New.ValidateStrict(),
+	}.Filter()
+}
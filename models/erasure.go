@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ErasureResponse model
+type ErasureResponse struct {
+	UserID   string    `json:"userId"`
+	Tenant   string    `json:"tenant,omitempty"`
+	ErasedAt time.Time `json:"erasedAt"`
+	// Erased holds the number of items removed by store, e.g. "jobs"
+	Erased map[string]int `json:"erased"`
+}
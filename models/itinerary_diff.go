@@ -0,0 +1,42 @@
+package models
+
+// ItineraryDiff model, the changes between the paths of two versions of an itinerary
+type ItineraryDiff struct {
+	Old PathResponse `json:"old"`
+	New PathResponse `json:"new"`
+	// Start and End are present when the itinerary starts or ends at another airport
+	Start *AirportChange `json:"start,omitempty"`
+	End   *AirportChange `json:"end,omitempty"`
+	// Added holds the legs of the new path only, Removed those of the old path only
+	Added   []LegDiff `json:"added"`
+	Removed []LegDiff `json:"removed"`
+	// Reordered holds the legs of both paths flown in another order, Changed those with other details
+	Reordered []LegDiff `json:"reordered"`
+	Changed   []LegDiff `json:"changed"`
+}
+
+// AirportChange model
+type AirportChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// LegDiff model, a leg added, removed, reordered or changed
+type LegDiff struct {
+	Origin      string `json:"origin"`
+	Destination string `json:"destination"`
+	// OldPosition and NewPosition are the positions of the leg in the legs of the paths, missing when the leg is
+	// missing from the path
+	OldPosition *int `json:"oldPosition,omitempty"`
+	NewPosition *int `json:"newPosition,omitempty"`
+	// Changes holds the details changed, only for the changed legs
+	Changes []DetailChange `json:"changes,omitempty"`
+}
+
+// DetailChange model, a detail of a leg with another value in the new path
+type DetailChange struct {
+	// Field is the name of the detail in Leg, e.g. "flightNumber"
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
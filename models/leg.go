@@ -0,0 +1,155 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+
+	"github.com/volume/service/user-flight-tracking/airports"
+)
+
+// LocalTimeLayout is the layout of the departure and arrival times, in the time zone of each airport
+const LocalTimeLayout = "2006-01-02T15:04"
+
+// Cabin classes
+const (
+	CabinEconomy        = "economy"
+	CabinPremiumEconomy = "premium_economy"
+	CabinBusiness       = "business"
+	CabinFirst          = "first"
+)
+
+var (
+	// noSpaces matches the values without whitespace
+	noSpaces = regexp.MustCompile(`^\S+$`)
+	// carrierCode matches the IATA airline designators, e.g. "UA" or "9W"
+	carrierCode = regexp.MustCompile(`^[A-Z0-9]{2}$`)
+	// flightNumber matches the numbers of up to 4 digits with an optional suffix, e.g. "123" or "4012A"
+	flightNumber = regexp.MustCompile(`^[0-9]{1,4}[A-Z]?$`)
+	// bookingReference matches the 6 characters record locators
+	bookingReference = regexp.MustCompile(`^[A-Z0-9]{6}$`)
+)
+
+// Leg model, a flight between two airports
+type Leg struct {
+	Origin      string `json:"origin"`
+	Destination string `json:"destination"`
+	// Carrier is the IATA code of the marketing carrier, which sold the flight
+	Carrier      string `json:"carrier,omitempty"`
+	FlightNumber string `json:"flightNumber,omitempty"`
+	// OperatingCarrier is the IATA code of the carrier flying the leg, when it differs from Carrier
+	OperatingCarrier string `json:"operatingCarrier,omitempty"`
+	Cabin            string `json:"cabin,omitempty"`
+	BookingReference string `json:"bookingReference,omitempty"`
+	// Departure and Arrival are the local times at the origin and the destination, given together
+	Departure string `json:"departure,omitempty"`
+	Arrival   string `json:"arrival,omitempty"`
+	// Inferred marks the legs added by the service to join the legs of the request, it can't be set in requests
+	Inferred bool `json:"inferred,omitempty"`
+}
+
+// LegDetails are the JSON names of the details of the legs besides their airports, in the order of Leg.Details
+var LegDetails = []string{"carrier", "flightNumber", "operatingCarrier", "cabin", "bookingReference", "departure", "arrival"}
+
+// Details returns the fields of the leg besides its airports, in the order of LegDetails
+func (l *Leg) Details() []*string {
+	return []*string{&l.Carrier, &l.FlightNumber, &l.OperatingCarrier, &l.Cabin, &l.BookingReference, &l.Departure, &l.Arrival}
+}
+
+// Times returns the departure and arrival in the time zones of the airports, ok is false unless both are valid
+func (l Leg) Times() (departure, arrival time.Time, ok bool) {
+	departure, err := localTime(l.Departure, l.Origin)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	arrival, err = localTime(l.Arrival, l.Destination)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return departure, arrival, true
+}
+
+func (l Leg) Validate() error {
+	carrierRules := []validation.Rule{validation.Match(carrierCode).Error("the carrier must be an IATA code of 2 uppercase letters or digits")}
+	if l.FlightNumber != "" {
+		carrierRules = append(carrierRules, validation.Required.Error("the carrier of a flight number is required"))
+	}
+
+	return validation.ValidateStruct(&l,
+		validation.Field(&l.Origin, airportRules()...),
+		validation.Field(&l.Destination, airportRules()...),
+		validation.Field(&l.Carrier, carrierRules...),
+		validation.Field(&l.FlightNumber, validation.Match(flightNumber).Error("the flight number must have 1 to 4 digits and an optional letter")),
+		validation.Field(&l.OperatingCarrier, validation.Match(carrierCode).Error("the operating carrier must be an IATA code of 2 uppercase letters or digits")),
+		validation.Field(&l.Cabin, validation.In(CabinEconomy, CabinPremiumEconomy, CabinBusiness, CabinFirst).Error("the cabin must be economy, premium_economy, business or first")),
+		validation.Field(&l.BookingReference, validation.Match(bookingReference).Error("the booking reference must have 6 uppercase letters or digits")),
+		validation.Field(&l.Departure, localTimeRules(l.Arrival != "")...),
+		validation.Field(&l.Arrival, append(localTimeRules(l.Departure != ""),
+			validation.By(func(interface{}) error {
+				if departure, arrival, ok := l.Times(); ok && !arrival.After(departure) {
+					return errors.New("the arrival must be after the departure")
+				}
+				return nil
+			}),
+		)...),
+		validation.Field(&l.Inferred, validation.By(func(interface{}) error {
+			if l.Inferred {
+				return errors.New("the legs can't be marked as inferred")
+			}
+			return nil
+		})),
+	)
+}
+
+// validateStrict validates the leg like Validate, additionally requiring IATA airport codes
+func (l Leg) validateStrict() error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+
+	return validation.ValidateStruct(&l,
+		validation.Field(&l.Origin, validation.Match(iataCode).Error("each airport must be an IATA code of 3 uppercase letters")),
+		validation.Field(&l.Destination, validation.Match(iataCode).Error("each airport must be an IATA code of 3 uppercase letters")),
+	)
+}
+
+// localTimeRules are the rules of the departure and arrival, required when the other one is given
+func localTimeRules(required bool) []validation.Rule {
+	rules := []validation.Rule{
+		validation.By(func(value interface{}) error {
+			local, _ := value.(string)
+			if local == "" {
+				return nil
+			}
+			// the airports of unknown time zone are accepted, the path is then answered without its timeline
+			if _, err := time.Parse(LocalTimeLayout, local); err != nil {
+				return errors.New("the departure and arrival must have the format YYYY-MM-DDTHH:MM")
+			}
+			return nil
+		}),
+	}
+	if required {
+		rules = append([]validation.Rule{validation.Required.Error("the departure and arrival must be given together")}, rules...)
+	}
+	return rules
+}
+
+// localTime parses a local time in the time zone of the airport
+func localTime(value, airport string) (time.Time, error) {
+	location, err := airports.Location(airport)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.ParseInLocation(LocalTimeLayout, value, location)
+}
+
+// airportRules are the rules of the airports of the legs
+func airportRules() []validation.Rule {
+	return []validation.Rule{
+		validation.Required,
+		validation.Length(3, 3).Error("each airport must have exactly 3 characters"),
+		validation.Match(noSpaces).Error("each airport must not contain spaces"),
+	}
+}
@@ -1,23 +1,120 @@
 package models
 
 import (
+	"errors"
 	"regexp"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 )
 
-// PathRequest model
+// DateLayout is the layout of the flight dates
+const DateLayout = "2006-01-02"
+
+// iataCode matches the IATA airport codes
+var iataCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// PathRequest model, the legs are given either as origin and destination pairs in Flights or in detail in Legs
 type PathRequest struct {
-	Flights [][]string
+	Flights [][]string `json:"flights,omitempty"`
+	Legs    []Leg      `json:"legs,omitempty"`
+	// UserID identifies the traveler, optional
+	UserID string `json:"userId,omitempty"`
+	// Dates holds the departure date of each flight, optional
+	Dates []string `json:"dates,omitempty"`
+}
+
+// Itinerary returns the legs of the request, those given as pairs only have their origin and destination
+func (pr PathRequest) Itinerary() []Leg {
+	if len(pr.Legs) > 0 {
+		return pr.Legs
+	}
+
+	legs := make([]Leg, 0, len(pr.Flights))
+	for _, flight := range pr.Flights {
+		var leg Leg
+		if len(flight) > 0 {
+			leg.Origin = flight[0]
+		}
+		if len(flight) > 1 {
+			leg.Destination = flight[1]
+		}
+		legs = append(legs, leg)
+	}
+	return legs
+}
+
+// Pairs returns the origin and destination of every leg of the request
+func (pr PathRequest) Pairs() [][]string {
+	if len(pr.Legs) == 0 {
+		return pr.Flights
+	}
+
+	pairs := make([][]string, len(pr.Legs))
+	for i, leg := range pr.Legs {
+		pairs[i] = []string{leg.Origin, leg.Destination}
+	}
+	return pairs
 }
 
 func (pr PathRequest) Validate() error {
+	flightsRules := []validation.Rule{
+		validation.Each(validation.Length(2, 2).Error("each flght must contain exactly 2 airports")),
+		validation.Each(validation.Each(validation.Required, validation.Length(3, 3).Error("each airport must have exactly 3 characters"))),
+		validation.Each(validation.Each(validation.Match(noSpaces).Error("each airport must not contain spaces"))),
+	}
+	if len(pr.Legs) == 0 {
+		flightsRules = append([]validation.Rule{validation.Required}, flightsRules...)
+	}
+
+	return validation.ValidateStruct(&pr,
+		validation.Field(&pr.Flights, flightsRules...),
+		validation.Field(&pr.Legs,
+			validation.By(func(interface{}) error {
+				if len(pr.Legs) > 0 && len(pr.Flights) > 0 {
+					return errors.New("the legs can't be given along with flights")
+				}
+				return nil
+			}),
+		),
+		validation.Field(&pr.Dates,
+			validation.By(func(interface{}) error {
+				if len(pr.Dates) > 0 && len(pr.Dates) != len(pr.Pairs()) {
+					return errors.New("there must be one date per flight")
+				}
+				return nil
+			}),
+			validation.Each(validation.By(validDate)),
+		),
+	)
+}
+
+// ValidateStrict validates the request like Validate, additionally requiring IATA codes of three uppercase letters
+func (pr PathRequest) ValidateStrict() error {
+	if err := pr.Validate(); err != nil {
+		return err
+	}
+
 	return validation.ValidateStruct(&pr,
 		validation.Field(&pr.Flights,
-			validation.Required,
-			validation.Each(validation.Length(2, 2).Error("each flght must contain exactly 2 airports")),
-			validation.Each(validation.Each(validation.Required, validation.Length(3, 3).Error("each airport must have exactly 3 characters"))),
-			validation.Each(validation.Each(validation.Match(regexp.MustCompile(`^\S+$`)).Error("each airport must not contain spaces"))),
+			validation.Each(validation.Each(validation.Match(iataCode).Error("each airport must be an IATA code of 3 uppercase letters"))),
+		),
+		validation.Field(&pr.Legs,
+			validation.Each(validation.By(func(value interface{}) error {
+				leg, _ := value.(Leg)
+				return leg.validateStrict()
+			})),
 		),
 	)
 }
+
+func validDate(value interface{}) error {
+	date, _ := value.(string)
+	if date == "" {
+		return nil
+	}
+	if _, err := time.Parse(DateLayout, date); err != nil {
+		return errors.New("each date must have the format YYYY-MM-DD")
+	}
+	return nil
+}
@@ -5,4 +5,24 @@ type PathResponse struct {
 	Start string   `json:"start"`
 	End   string   `json:"end"`
 	Path  []string `json:"path"`
+	// Legs holds the flight between each airport of Path and the next one
+	Legs []Leg `json:"legs"`
+	// Timeline holds the schedule of Legs, only when every leg has its departure and arrival
+	Timeline *Timeline `json:"timeline,omitempty"`
+	// Warnings holds the tight and impossible connections of Timeline
+	Warnings []ConnectionWarning `json:"warnings,omitempty"`
+	// Duplicates holds the legs of the request sharing their origin and destination
+	Duplicates []DuplicateLegs `json:"duplicates,omitempty"`
+}
+
+// DuplicateLegs model, legs of the request sharing their origin and destination
+type DuplicateLegs struct {
+	Origin      string `json:"origin"`
+	Destination string `json:"destination"`
+	// Legs holds the positions of the legs in the request, starting at 0
+	Legs []int `json:"legs"`
+	// Merged reports whether the legs were merged into the first one, or kept as separate legs of the path
+	Merged bool `json:"merged"`
+	// Conflicting reports whether the legs disagree on a detail, the first leg wins when they are merged
+	Conflicting bool `json:"conflicting"`
 }
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// MaxSources bounds the sources of a reconciliation
+const MaxSources = 10
+
+// ReconcileRequest model, the legs of a traveler reported by several sources
+type ReconcileRequest struct {
+	// UserID identifies the traveler, optional
+	UserID string `json:"userId,omitempty"`
+	// Sources are given by precedence, the details of the first sources win on conflicts
+	Sources []LegSource `json:"sources"`
+}
+
+// LegSource model, the legs reported by a source, e.g. the booking tool or an airline feed
+type LegSource struct {
+	Name string `json:"name"`
+	Legs []Leg  `json:"legs"`
+}
+
+func (rr ReconcileRequest) Validate() error {
+	return validation.ValidateStruct(&rr,
+		validation.Field(&rr.Sources,
+			validation.Required,
+			validation.Length(2, MaxSources).Error(fmt.Sprintf("there must be 2 to %d sources", MaxSources)),
+			validation.By(func(interface{}) error {
+				names := make(map[string]bool, len(rr.Sources))
+				for _, source := range rr.Sources {
+					if names[source.Name] {
+						return fmt.Errorf("the source %s is given twice", source.Name)
+					}
+					names[source.Name] = true
+				}
+				return nil
+			}),
+		),
+	)
+}
+
+// ValidateStrict validates the request like Validate, additionally requiring IATA codes of three uppercase letters
+func (rr ReconcileRequest) ValidateStrict() error {
+	if err := rr.Validate(); err != nil {
+		return err
+	}
+
+	return validation.ValidateStruct(&rr,
+		validation.Field(&rr.Sources,
+			validation.Each(validation.By(func(value interface{}) error {
+				source, _ := value.(LegSource)
+				return validation.Validate(source.Legs, validation.Each(validation.By(func(value interface{}) error {
+					leg, _ := value.(Leg)
+					return leg.validateStrict()
+				})))
+			})),
+		),
+	)
+}
+
+func (ls LegSource) Validate() error {
+	return validation.ValidateStruct(&ls,
+		validation.Field(&ls.Name, validation.Required),
+		validation.Field(&ls.Legs, validation.Required),
+	)
+}
@@ -0,0 +1,41 @@
+package models
+
+// Reconciliation model, the legs of a traveler merged from several sources and compared with each of them
+type Reconciliation struct {
+	// Legs holds the consensus legs, merged from the legs of the sources sharing their origin and destination
+	Legs []ReconciledLeg `json:"legs"`
+	// Path is the path of the consensus legs, missing when they don't form a single path
+	Path *PathResponse `json:"path,omitempty"`
+	// PathError tells why the consensus legs don't form a single path
+	PathError string       `json:"pathError,omitempty"`
+	Sources   []SourceDiff `json:"sources"`
+}
+
+// ReconciledLeg model, a leg of the consensus
+type ReconciledLeg struct {
+	Leg Leg `json:"leg"`
+	// Status is "matching", "conflicting" or "source_only"
+	Status string `json:"status"`
+	// Sources holds the names of the sources reporting the leg
+	Sources []string `json:"sources"`
+	// Conflicts holds the details the sources disagree on, the first source giving a detail wins
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+// SourceDiff model, the differences between the legs of a source and the consensus
+type SourceDiff struct {
+	Name string `json:"name"`
+	// Matching, Conflicting and Only hold the positions of the legs in the source, starting at 0
+	Matching    []int         `json:"matching"`
+	Conflicting []LegConflict `json:"conflicting"`
+	// Only holds the legs no other source reports
+	Only []int `json:"only"`
+	// Missing holds the positions in Legs of the consensus legs the source doesn't report
+	Missing []int `json:"missing"`
+}
+
+// LegConflict model, a leg of a source disagreeing with another source
+type LegConflict struct {
+	Leg    int      `json:"leg"`
+	Fields []string `json:"fields"`
+}
@@ -0,0 +1,18 @@
+package models
+
+// RouteSuggestions model, the shortest routes found between two airports
+type RouteSuggestions struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// By is the metric the routes are ranked by, "hops" or "distance"
+	By     string           `json:"by"`
+	Routes []SuggestedRoute `json:"routes"`
+}
+
+// SuggestedRoute model, a way between two airports over the known routes
+type SuggestedRoute struct {
+	Path  []string `json:"path"`
+	Stops int      `json:"stops"`
+	// DistanceKm is the great-circle distance flown, missing when an airport has no coordinates
+	DistanceKm *int `json:"distanceKm,omitempty"`
+}
@@ -0,0 +1,40 @@
+package models
+
+// Timeline model, the schedule of the legs of a path
+type Timeline struct {
+	Legs []TimelineLeg `json:"legs"`
+	// ElapsedMinutes is the time from the first departure to the last arrival
+	ElapsedMinutes int `json:"elapsedMinutes"`
+}
+
+// TimelineLeg model, the local times have the offset of the time zone of each airport
+type TimelineLeg struct {
+	Origin         string `json:"origin"`
+	Destination    string `json:"destination"`
+	DepartureLocal string `json:"departureLocal"`
+	DepartureUTC   string `json:"departureUtc"`
+	ArrivalLocal   string `json:"arrivalLocal"`
+	ArrivalUTC     string `json:"arrivalUtc"`
+	BlockMinutes   int    `json:"blockMinutes"`
+	// LayoverMinutes is the time since the arrival of the previous leg, missing on the first leg
+	LayoverMinutes  *int `json:"layoverMinutes,omitempty"`
+	Overnight       bool `json:"overnight"`
+	CrossesDateLine bool `json:"crossesDateLine"`
+}
+
+// ConnectionWarning model, a connection shorter than or close to the minimum connection time of its airport
+type ConnectionWarning struct {
+	Airport string `json:"airport"`
+	// Severity is "tight" or "impossible"
+	Severity string `json:"severity"`
+	// Connection is "domestic" or "international"
+	Connection     string `json:"connection"`
+	LayoverMinutes int    `json:"layoverMinutes"`
+	MinimumMinutes int    `json:"minimumMinutes"`
+}
+
+// Connection types of the warnings
+const (
+	ConnectionDomestic      = "domestic"
+	ConnectionInternational = "international"
+)
@@ -0,0 +1,14 @@
+package models
+
+// ValidationErrorResponse model
+type ValidationErrorResponse struct {
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors"`
+}
+
+// FieldError describes an invalid value, Row is the 1-based line of the uploaded file, if any
+type FieldError struct {
+	Row     int    `json:"row,omitempty"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
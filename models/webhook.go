@@ -0,0 +1,83 @@
+package models
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Webhook event types
+const (
+	WebhookEventPathComputed = "path.computed"
+	WebhookEventPathFailed   = "path.failed"
+)
+
+// WebhookEventTypes lists the event types a subscription can receive
+var WebhookEventTypes = []interface{}{WebhookEventPathComputed, WebhookEventPathFailed}
+
+// minWebhookSecretLength is the minimum length of the signing secrets
+const minWebhookSecretLength = 16
+
+// WebhookSubscriptionRequest model
+type WebhookSubscriptionRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret is the key of the HMAC-SHA256 signature of the deliveries
+	Secret string `json:"secret"`
+}
+
+func (wr WebhookSubscriptionRequest) Validate() error {
+	return validation.ValidateStruct(&wr,
+		validation.Field(&wr.URL, validation.Required, validation.By(validWebhookURL)),
+		validation.Field(&wr.Events, validation.Required, validation.Each(validation.In(WebhookEventTypes...).Error("unknown event type"))),
+		validation.Field(&wr.Secret, validation.Required, validation.RuneLength(minWebhookSecretLength, 0)),
+	)
+}
+
+func validWebhookURL(value interface{}) error {
+	raw, _ := value.(string)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("the url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// WebhookSubscriptionResponse model, the secret is never returned
+type WebhookSubscriptionResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookEvent model, body of the webhook deliveries
+type WebhookEvent struct {
+	ID        string           `json:"id"`
+	Type      string           `json:"type"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Data      WebhookEventData `json:"data"`
+}
+
+// WebhookEventData describes the calculation that raised the event
+type WebhookEventData struct {
+	UserID string   `json:"userId,omitempty"`
+	Legs   int      `json:"legs"`
+	Start  string   `json:"start,omitempty"`
+	End    string   `json:"end,omitempty"`
+	Path   []string `json:"path,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// DeadLetterResponse model, a delivery abandoned after its last attempt
+type DeadLetterResponse struct {
+	DeliveryID     string       `json:"deliveryId"`
+	SubscriptionID string       `json:"subscriptionId"`
+	URL            string       `json:"url"`
+	Event          WebhookEvent `json:"event"`
+	Attempts       int          `json:"attempts"`
+	LastError      string       `json:"lastError"`
+	FailedAt       time.Time    `json:"failedAt"`
+}
@@ -0,0 +1,140 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/volume/service/user-flight-tracking/airports"
+	"github.com/volume/service/user-flight-tracking/graph"
+)
+
+// Metrics the routes are ranked by
+const (
+	// ByHops ranks the routes by their number of legs
+	ByHops = "hops"
+	// ByDistance ranks the routes by their great-circle distance
+	ByDistance = "distance"
+)
+
+// Query selects the routes suggested between two airports
+type Query struct {
+	From string
+	To   string
+	// K is the number of routes returned, at most
+	K int
+	// By is the metric the routes are ranked by, ByHops or ByDistance
+	By string
+	// MaxStops is the number of connecting airports of each route, at most
+	MaxStops int
+	// Exclude holds the airports the routes can't connect at
+	Exclude []string
+}
+
+// Route is a suggested way between two airports
+type Route struct {
+	Airports []string
+	// Distance is the great-circle distance flown, in kilometers, zero unless DistanceKnown
+	Distance      float64
+	DistanceKnown bool
+}
+
+// Stops returns the number of connecting airports of the route
+func (r Route) Stops() int {
+	return len(r.Airports) - 2
+}
+
+// Network holds the known routes flown between airports. It is not modified once built,
+// so it can be queried concurrently
+type Network struct {
+	graph *graph.Graph
+}
+
+// New returns the network of the [origin, destination] routes
+func New(routes [][]string) *Network {
+	return &Network{graph: graph.FromPairs(routes)}
+}
+
+// LoadRoutes reads a routes file from disk and returns its network
+func LoadRoutes(path string) (*Network, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading routes: %w", err)
+	}
+
+	return ParseRoutes(data)
+}
+
+// ParseRoutes decodes a routes file, a JSON object whose "routes" holds [origin, destination] pairs.
+// The routes are directed, the returns are listed separately
+func ParseRoutes(data []byte) (*Network, error) {
+	var file struct {
+		Routes [][]string `json:"routes"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decoding routes: %w", err)
+	}
+
+	for i, route := range file.Routes {
+		if len(route) != 2 || route[0] == "" || route[1] == "" || route[0] == route[1] {
+			return nil, fmt.Errorf("route %d: must be 2 different airports", i)
+		}
+	}
+
+	return New(file.Routes), nil
+}
+
+// Len returns the number of airports of the network
+func (n *Network) Len() int {
+	return n.graph.Len()
+}
+
+// Contains reports whether the airport has routes in the network
+func (n *Network) Contains(airport string) bool {
+	return n.graph.Contains(graph.Airport(airport))
+}
+
+// Suggest returns the shortest routes of the query, best first. Ranked by distance, the routes through
+// airports without coordinates are left out
+func (n *Network) Suggest(q Query) []Route {
+	opts := graph.PathOptions{MaxLegs: q.MaxStops + 1}
+	for _, airport := range q.Exclude {
+		opts.Excluded = append(opts.Excluded, graph.Airport(airport))
+	}
+	if q.By == ByDistance {
+		opts.Weight = distance
+	}
+
+	paths := n.graph.ShortestPaths(graph.Airport(q.From), graph.Airport(q.To), q.K, opts)
+	routes := make([]Route, len(paths))
+	for i, path := range paths {
+		route := Route{Airports: make([]string, len(path.Airports)), DistanceKnown: true}
+		for j, airport := range path.Airports {
+			route.Airports[j] = string(airport)
+			if j == 0 {
+				continue
+			}
+			km, ok := distance(graph.Leg{From: path.Airports[j-1], To: airport})
+			route.Distance += km
+			route.DistanceKnown = route.DistanceKnown && ok
+		}
+		if !route.DistanceKnown {
+			route.Distance = 0
+		}
+		routes[i] = route
+	}
+	return routes
+}
+
+// distance weighs the legs by their great-circle distance, in kilometers
+func distance(leg graph.Leg) (float64, bool) {
+	from, ok := airports.Lookup(string(leg.From))
+	if !ok {
+		return 0, false
+	}
+	to, ok := airports.Lookup(string(leg.To))
+	if !ok {
+		return 0, false
+	}
+	return airports.Distance(from, to), true
+}
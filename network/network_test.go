@@ -0,0 +1,83 @@
+package network_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/network"
+)
+
+func TestNetwork_ParseRoutes(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantLen   int
+		wantError error
+	}{
+		{name: "should_return_the_network", data: `{"routes": [["SFO", "ATL"], ["ATL", "EWR"], ["EWR", "ATL"]]}`, wantLen: 3},
+		{name: "should_return_an_empty_network", data: `{}`},
+		{name: "should_return_error_when_the_route_is_incomplete", data: `{"routes": [["SFO", "ATL"], ["ATL"]]}`, wantError: errors.New("route 1: must be 2 different airports")},
+		{name: "should_return_error_when_the_route_is_circular", data: `{"routes": [["SFO", "SFO"]]}`, wantError: errors.New("route 0: must be 2 different airports")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := network.ParseRoutes([]byte(tt.data))
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLen, n.Len())
+		})
+	}
+}
+
+func TestNetwork_LoadRoutes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routes.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"routes": [["SFO", "ATL"]]}`), 0o600))
+
+	n, err := network.LoadRoutes(path)
+	require.NoError(t, err)
+	assert.Assert(t, n.Contains("ATL"))
+
+	_, err = network.LoadRoutes(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "reading routes")
+}
+
+func TestNetwork_Suggest(t *testing.T) {
+	n := network.New([][]string{
+		{"SFO", "ATL"}, {"ATL", "EWR"}, {"SFO", "XXX"}, {"XXX", "EWR"}, {"SFO", "DEN"}, {"DEN", "EWR"}, {"DEN", "ORD"}, {"ORD", "EWR"},
+	})
+
+	t.Run("should_return_the_routes_with_the_fewest_stops", func(t *testing.T) {
+		routes := n.Suggest(network.Query{From: "SFO", To: "EWR", K: 3, By: network.ByHops, MaxStops: 2})
+
+		require.Equal(t, 3, len(routes))
+		assert.DeepEqual(t, []string{"SFO", "ATL", "EWR"}, routes[0].Airports)
+		assert.DeepEqual(t, []string{"SFO", "XXX", "EWR"}, routes[1].Airports)
+		assert.Assert(t, !routes[1].DistanceKnown, "XXX has no coordinates")
+		assert.DeepEqual(t, []string{"SFO", "DEN", "EWR"}, routes[2].Airports)
+		assert.Equal(t, 1, routes[2].Stops())
+	})
+
+	t.Run("should_return_the_shortest_routes", func(t *testing.T) {
+		routes := n.Suggest(network.Query{From: "SFO", To: "EWR", K: 5, By: network.ByDistance, MaxStops: 2, Exclude: []string{"ORD"}})
+
+		require.Equal(t, 2, len(routes))
+		assert.DeepEqual(t, []string{"SFO", "DEN", "EWR"}, routes[0].Airports)
+		assert.DeepEqual(t, []string{"SFO", "ATL", "EWR"}, routes[1].Airports)
+		assert.Assert(t, routes[0].DistanceKnown)
+		assert.Assert(t, routes[0].Distance < routes[1].Distance)
+	})
+
+	t.Run("should_return_no_route_beyond_the_maximum_stops", func(t *testing.T) {
+		assert.Equal(t, 0, len(n.Suggest(network.Query{From: "SFO", To: "ORD", K: 3, By: network.ByHops, MaxStops: 0})))
+	})
+}
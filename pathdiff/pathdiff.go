@@ -0,0 +1,133 @@
+package pathdiff
+
+import (
+	"sort"
+
+	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// Compare returns the differences between the paths of two versions of an itinerary. The legs are matched by their
+// origin and destination, the repeated ones in the order they are flown. Of the legs of both paths, the fewest are
+// reported as reordered to account for the new order.
+func Compare(previous, current dto.Path) dto.PathDiff {
+	diff := dto.PathDiff{
+		Old:          previous,
+		New:          current,
+		StartChanged: first(previous.Airports) != first(current.Airports),
+		EndChanged:   last(previous.Airports) != last(current.Airports),
+	}
+
+	positions := make(map[[2]string][]int)
+	for i, leg := range current.Legs {
+		pair := [2]string{leg.Origin, leg.Destination}
+		positions[pair] = append(positions[pair], i)
+	}
+
+	// oldPositions[i] is the position in the old path of the leg i of the new path, order the new positions of the
+	// legs of both paths in the old order
+	oldPositions := make([]int, len(current.Legs))
+	for i := range oldPositions {
+		oldPositions[i] = -1
+	}
+	var order []int
+	for i, leg := range previous.Legs {
+		pair := [2]string{leg.Origin, leg.Destination}
+		next := positions[pair]
+		if len(next) == 0 {
+			diff.Removed = append(diff.Removed, dto.LegDiff{Old: leg, OldPosition: i, NewPosition: -1})
+			continue
+		}
+		oldPositions[next[0]], positions[pair] = i, next[1:]
+		order = append(order, next[0])
+	}
+
+	kept := make(map[int]bool, len(order))
+	for i, inOrder := range increasing(order) {
+		if inOrder {
+			kept[order[i]] = true
+		}
+	}
+
+	for i, leg := range current.Legs {
+		old := oldPositions[i]
+		if old < 0 {
+			diff.Added = append(diff.Added, dto.LegDiff{New: leg, OldPosition: -1, NewPosition: i})
+			continue
+		}
+
+		legDiff := dto.LegDiff{Old: previous.Legs[old], New: leg, OldPosition: old, NewPosition: i}
+		if !kept[i] {
+			diff.Reordered = append(diff.Reordered, legDiff)
+		}
+		if legDiff.Changes = changes(legDiff.Old, leg); len(legDiff.Changes) > 0 {
+			diff.Changed = append(diff.Changed, legDiff)
+		}
+	}
+
+	return diff
+}
+
+// changes returns the details differing between the versions of a leg, named as in models.LegDetails
+func changes(previous, current dto.Leg) []string {
+	differs := []bool{
+		previous.Carrier != current.Carrier,
+		previous.FlightNumber != current.FlightNumber,
+		previous.OperatingCarrier != current.OperatingCarrier,
+		previous.Cabin != current.Cabin,
+		previous.BookingReference != current.BookingReference,
+		!previous.Departure.Equal(current.Departure),
+		!previous.Arrival.Equal(current.Arrival),
+	}
+
+	var fields []string
+	for i, name := range models.LegDetails {
+		if differs[i] {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// increasing marks the values of a longest increasing subsequence of values, the legs kept in their relative order
+func increasing(values []int) []bool {
+	// tails[k] is the index of the smallest last value of the increasing subsequences of length k+1
+	var tails []int
+	previous := make([]int, len(values))
+	for i, value := range values {
+		k := sort.Search(len(tails), func(j int) bool { return values[tails[j]] >= value })
+		previous[i] = -1
+		if k > 0 {
+			previous[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	marked := make([]bool, len(values))
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = previous[i] {
+			marked[i] = true
+		}
+	}
+	return marked
+}
+
+// first returns the first airport, empty when there is none
+func first(airports []string) string {
+	if len(airports) == 0 {
+		return ""
+	}
+	return airports[0]
+}
+
+// last returns the last airport, empty when there is none
+func last(airports []string) string {
+	if len(airports) == 0 {
+		return ""
+	}
+	return airports[len(airports)-1]
+}
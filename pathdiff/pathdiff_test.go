@@ -0,0 +1,104 @@
+package pathdiff_test
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/pathdiff"
+)
+
+func TestPathDiff_Compare(t *testing.T) {
+	departure := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		previous dto.Path
+		current  dto.Path
+		want     dto.PathDiff
+	}{
+		{
+			name:     "should_return_no_difference",
+			previous: path(dto.Leg{Origin: "SFO", Destination: "ATL"}, dto.Leg{Origin: "ATL", Destination: "EWR"}),
+			current:  path(dto.Leg{Origin: "SFO", Destination: "ATL"}, dto.Leg{Origin: "ATL", Destination: "EWR"}),
+			want:     dto.PathDiff{},
+		},
+		{
+			name:     "should_return_the_added_and_removed_legs",
+			previous: path(dto.Leg{Origin: "SFO", Destination: "ATL"}, dto.Leg{Origin: "ATL", Destination: "EWR"}),
+			current:  path(dto.Leg{Origin: "SFO", Destination: "ATL"}, dto.Leg{Origin: "ATL", Destination: "GSO"}, dto.Leg{Origin: "GSO", Destination: "EWR"}),
+			want: dto.PathDiff{
+				Added: []dto.LegDiff{
+					{New: dto.Leg{Origin: "ATL", Destination: "GSO"}, OldPosition: -1, NewPosition: 1},
+					{New: dto.Leg{Origin: "GSO", Destination: "EWR"}, OldPosition: -1, NewPosition: 2},
+				},
+				Removed: []dto.LegDiff{{Old: dto.Leg{Origin: "ATL", Destination: "EWR"}, OldPosition: 1, NewPosition: -1}},
+			},
+		},
+		{
+			name:     "should_return_the_changed_start_and_end",
+			previous: path(dto.Leg{Origin: "SFO", Destination: "ATL"}, dto.Leg{Origin: "ATL", Destination: "EWR"}),
+			current:  path(dto.Leg{Origin: "ATL", Destination: "EWR"}, dto.Leg{Origin: "EWR", Destination: "BOS"}),
+			want: dto.PathDiff{
+				StartChanged: true,
+				EndChanged:   true,
+				Added:        []dto.LegDiff{{New: dto.Leg{Origin: "EWR", Destination: "BOS"}, OldPosition: -1, NewPosition: 1}},
+				Removed:      []dto.LegDiff{{Old: dto.Leg{Origin: "SFO", Destination: "ATL"}, OldPosition: 0, NewPosition: -1}},
+			},
+		},
+		{
+			// either round trip could be reported as reordered, a single one is
+			name: "should_return_the_fewest_reordered_legs",
+			previous: path(
+				dto.Leg{Origin: "ATL", Destination: "SFO"}, dto.Leg{Origin: "SFO", Destination: "ATL"},
+				dto.Leg{Origin: "ATL", Destination: "EWR"}, dto.Leg{Origin: "EWR", Destination: "ATL"},
+				dto.Leg{Origin: "ATL", Destination: "BOS"},
+			),
+			current: path(
+				dto.Leg{Origin: "ATL", Destination: "EWR"}, dto.Leg{Origin: "EWR", Destination: "ATL"},
+				dto.Leg{Origin: "ATL", Destination: "SFO"}, dto.Leg{Origin: "SFO", Destination: "ATL"},
+				dto.Leg{Origin: "ATL", Destination: "BOS"},
+			),
+			want: dto.PathDiff{
+				Reordered: []dto.LegDiff{
+					{Old: dto.Leg{Origin: "ATL", Destination: "SFO"}, New: dto.Leg{Origin: "ATL", Destination: "SFO"}, OldPosition: 0, NewPosition: 2},
+					{Old: dto.Leg{Origin: "SFO", Destination: "ATL"}, New: dto.Leg{Origin: "SFO", Destination: "ATL"}, OldPosition: 1, NewPosition: 3},
+				},
+			},
+		},
+		{
+			name:     "should_return_the_changed_details",
+			previous: path(dto.Leg{Origin: "SFO", Destination: "ATL", Carrier: "UA", FlightNumber: "88", Departure: departure}),
+			current:  path(dto.Leg{Origin: "SFO", Destination: "ATL", Carrier: "UA", FlightNumber: "89", Departure: departure.Add(time.Hour)}),
+			want: dto.PathDiff{
+				Changed: []dto.LegDiff{{
+					Old:         dto.Leg{Origin: "SFO", Destination: "ATL", Carrier: "UA", FlightNumber: "88", Departure: departure},
+					New:         dto.Leg{Origin: "SFO", Destination: "ATL", Carrier: "UA", FlightNumber: "89", Departure: departure.Add(time.Hour)},
+					OldPosition: 0,
+					NewPosition: 0,
+					Changes:     []string{"flightNumber", "departure"},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := pathdiff.Compare(tt.previous, tt.current)
+
+			tt.want.Old, tt.want.New = tt.previous, tt.current
+			assert.DeepEqual(t, tt.want, diff)
+		})
+	}
+}
+
+// path returns the path of the legs, flown in their order
+func path(legs ...dto.Leg) dto.Path {
+	airports := []string{legs[0].Origin}
+	for _, leg := range legs {
+		airports = append(airports, leg.Destination)
+	}
+	return dto.Path{Airports: airports, Legs: legs}
+}
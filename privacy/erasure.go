@@ -0,0 +1,99 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+// Target holds personal data of the travelers, implementations must be safe for concurrent use
+type Target interface {
+	// Erase removes the data of userID in the tenant of ctx and returns the number of items removed
+	Erase(ctx context.Context, userID string) (int, error)
+}
+
+// TargetFunc adapts a function to a Target
+type TargetFunc func(ctx context.Context, userID string) (int, error)
+
+// Erase calls f
+func (f TargetFunc) Erase(ctx context.Context, userID string) (int, error) {
+	return f(ctx, userID)
+}
+
+// Subject returns the key of userID in the stores shared by the tenants, empty when userID is
+func Subject(ctx context.Context, userID string) string {
+	if userID == "" {
+		return ""
+	}
+	return tenancy.Scope(ctx, userID)
+}
+
+// Erasure is the outcome of the erasure of a traveler
+type Erasure struct {
+	UserID string
+	Tenant string
+	Time   time.Time
+	// Erased holds the number of items removed by target name
+	Erased map[string]int
+}
+
+// Eraser removes the data of a traveler from all of its targets
+type Eraser struct {
+	Logger  *log.Entry
+	Targets map[string]Target
+	Now     func() time.Time
+}
+
+// NewEraser returns an eraser of the targets, keyed by the name reported in the erasures
+func NewEraser(log *log.Entry, targets map[string]Target) (*Eraser, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case len(targets) == 0:
+		return nil, errors.New("targets")
+	}
+
+	return &Eraser{
+		Logger:  log,
+		Targets: targets,
+		Now:     time.Now,
+	}, nil
+}
+
+// Erase removes the data of userID in the tenant of ctx from every target, even when some of them fail.
+// It is safe to retry: the targets already erased then report no item.
+func (e *Eraser) Erase(ctx context.Context, userID string) (Erasure, error) {
+	logger := logging.FromContext(ctx, e.Logger)
+
+	names := make([]string, 0, len(e.Targets))
+	for name := range e.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	erasure := Erasure{
+		UserID: userID,
+		Tenant: tenancy.ID(ctx),
+		Time:   e.Now().UTC(),
+		Erased: make(map[string]int, len(names)),
+	}
+
+	var errs []error
+	for _, name := range names {
+		count, err := e.Targets[name].Erase(ctx, userID)
+		if err != nil {
+			logger.WithError(err).WithField("target", name).Error("error erasing user data")
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		erasure.Erased[name] = count
+	}
+
+	return erasure, errors.Join(errs...)
+}
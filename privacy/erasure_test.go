@@ -0,0 +1,136 @@
+package privacy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/cache"
+	"github.com/volume/service/user-flight-tracking/privacy"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+func TestPrivacy_NewEraser(t *testing.T) {
+	targets := map[string]privacy.Target{"jobs": privacy.TargetFunc(func(context.Context, string) (int, error) { return 0, nil })}
+
+	tests := []struct {
+		name      string
+		logger    *log.Entry
+		targets   map[string]privacy.Target
+		wantError error
+	}{
+		{name: "should_return_success", logger: log.NewEntry(nil), targets: targets},
+		{name: "should_return_error_when_the_logger_is_nil", targets: targets, wantError: errors.New("logger")},
+		{name: "should_return_error_without_targets", logger: log.NewEntry(nil), wantError: errors.New("targets")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := privacy.NewEraser(tt.logger, tt.targets)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPrivacy_Erase(t *testing.T) {
+	now := time.Date(2023, 6, 13, 10, 0, 0, 0, time.UTC)
+	ctx := tenancy.NewContext(context.Background(), tenancy.Tenant{ID: "acme"})
+
+	var subjects []string
+	eraser, err := privacy.NewEraser(log.NewEntry(log.New()), map[string]privacy.Target{
+		"audit": privacy.TargetFunc(func(ctx context.Context, userID string) (int, error) {
+			subjects = append(subjects, privacy.Subject(ctx, userID))
+			return 3, nil
+		}),
+		"jobs": privacy.TargetFunc(func(context.Context, string) (int, error) {
+			return 1, errors.New("unavailable")
+		}),
+	})
+	require.NoError(t, err)
+	eraser.Now = func() time.Time { return now }
+
+	erasure, err := eraser.Erase(ctx, "u-1")
+
+	assert.Error(t, err, "jobs: unavailable")
+	assert.DeepEqual(t, privacy.Erasure{
+		UserID: "u-1",
+		Tenant: "acme",
+		Time:   now,
+		Erased: map[string]int{"audit": 3, "jobs": 1},
+	}, erasure)
+	assert.DeepEqual(t, []string{"acme/u-1"}, subjects)
+}
+
+func TestPrivacy_CacheIndex(t *testing.T) {
+	ctx := context.Background()
+	acme := tenancy.NewContext(ctx, tenancy.Tenant{ID: "acme"})
+
+	lru, err := cache.NewLRU(10, time.Minute)
+	require.NoError(t, err)
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, lru.Set(ctx, key, []byte(key)))
+	}
+
+	_, err = privacy.NewCacheIndex(nil, time.Minute)
+	assert.Error(t, err, "cache")
+	_, err = privacy.NewCacheIndex(lru, 0)
+	assert.Error(t, err, "ttl")
+
+	index, err := privacy.NewCacheIndex(lru, time.Minute)
+	require.NoError(t, err)
+	index.Track(acme, "u-1", "a")
+	index.Track(acme, "u-1", "b")
+	index.Track(ctx, "u-1", "c")
+	index.Track(acme, "", "c")
+
+	erased, err := index.Erase(acme, "u-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, erased)
+	assert.Equal(t, 1, lru.Len(), "the value of u-1 outside of the tenant should be kept")
+
+	erased, err = index.Erase(acme, "u-1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, erased)
+
+	t.Run("should_keep_the_keys_not_deleted", func(t *testing.T) {
+		backend := &failingBackend{Backend: lru, failures: 1}
+		index, err := privacy.NewCacheIndex(backend, time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, lru.Set(ctx, "d", []byte("d")))
+		index.Track(acme, "u-2", "d")
+
+		erased, err := index.Erase(acme, "u-2")
+		assert.Error(t, err, "unavailable")
+		assert.Equal(t, 0, erased)
+
+		erased, err = index.Erase(acme, "u-2")
+		require.NoError(t, err)
+		assert.Equal(t, 1, erased, "the retry should delete the key")
+		_, found, _ := lru.Get(ctx, "d")
+		assert.Assert(t, !found)
+	})
+}
+
+// failingBackend fails the first deletions
+type failingBackend struct {
+	cache.Backend
+	failures int
+}
+
+func (b *failingBackend) Delete(ctx context.Context, key string) error {
+	if b.failures > 0 {
+		b.failures--
+		return errors.New("unavailable")
+	}
+	return b.Backend.Delete(ctx, key)
+}
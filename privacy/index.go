@@ -0,0 +1,142 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the minimum time between two sweeps of the expired keys
+const sweepInterval = time.Minute
+
+// Tracker records which cached values hold the data of a traveler
+type Tracker interface {
+	Track(ctx context.Context, userID, key string)
+}
+
+// Deleter removes the values of a store, e.g. the path cache
+type Deleter interface {
+	// Delete removes the value stored under key, if any
+	Delete(ctx context.Context, key string) error
+}
+
+// DeleterFunc adapts a function to a Deleter
+type DeleterFunc func(ctx context.Context, key string) error
+
+// Delete calls f
+func (f DeleterFunc) Delete(ctx context.Context, key string) error {
+	return f(ctx, key)
+}
+
+// CacheIndex remembers the keys of the values of every traveler in a store expiring them, e.g. the path cache, so
+// that they can be erased. Keys are forgotten once the store has expired them.
+type CacheIndex struct {
+	Cache Deleter
+	// TTL is the time the store keeps a value
+	TTL time.Duration
+	Now func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewCacheIndex returns an index of the keys of backend, whose values are kept for ttl
+func NewCacheIndex(backend Deleter, ttl time.Duration) (*CacheIndex, error) {
+	switch {
+	case backend == nil:
+		return nil, errors.New("cache")
+	case ttl <= 0:
+		return nil, errors.New("ttl")
+	}
+
+	return &CacheIndex{
+		Cache: backend,
+		TTL:   ttl,
+		Now:   time.Now,
+		keys:  make(map[string]map[string]time.Time),
+	}, nil
+}
+
+// Track records that the value of key holds the data of userID in the tenant of ctx
+func (i *CacheIndex) Track(ctx context.Context, userID, key string) {
+	subject := Subject(ctx, userID)
+	if subject == "" {
+		return
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	now := i.Now()
+	i.sweep(now)
+
+	keys, ok := i.keys[subject]
+	if !ok {
+		keys = make(map[string]time.Time)
+		i.keys[subject] = keys
+	}
+	keys[key] = now.Add(i.TTL)
+}
+
+// Erase deletes the cached values of userID in the tenant of ctx, values shared with other travelers included.
+// The keys whose deletion failed are kept, for a retry to delete them.
+func (i *CacheIndex) Erase(ctx context.Context, userID string) (int, error) {
+	subject := Subject(ctx, userID)
+
+	i.mu.Lock()
+	keys := make(map[string]time.Time, len(i.keys[subject]))
+	for key, expires := range i.keys[subject] {
+		keys[key] = expires
+	}
+	i.mu.Unlock()
+
+	var (
+		erased int
+		errs   []error
+	)
+	for key, expires := range keys {
+		if err := i.Cache.Delete(ctx, key); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		i.forget(subject, key, expires)
+		erased++
+	}
+	return erased, errors.Join(errs...)
+}
+
+// forget drops a key of subject, unless it was tracked again since its expiry was read
+func (i *CacheIndex) forget(subject, key string, expires time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	keys := i.keys[subject]
+	if tracked, ok := keys[key]; !ok || !tracked.Equal(expires) {
+		return
+	}
+	delete(keys, key)
+	if len(keys) == 0 {
+		delete(i.keys, subject)
+	}
+}
+
+// sweep forgets the expired keys, at most once per sweepInterval
+func (i *CacheIndex) sweep(now time.Time) {
+	if now.Sub(i.lastSweep) < sweepInterval {
+		return
+	}
+	i.lastSweep = now
+
+	for subject, keys := range i.keys {
+		for key, expires := range keys {
+			if !now.Before(expires) {
+				delete(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			delete(i.keys, subject)
+		}
+	}
+}
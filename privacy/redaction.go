@@ -0,0 +1,87 @@
+package privacy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Redaction is how the airports of an itinerary are written to the logs
+type Redaction string
+
+// Redactions of the itineraries
+const (
+	// RedactionPlain writes the airports as they are
+	RedactionPlain Redaction = "plain"
+	// RedactionHashed replaces the airports with their HMAC-SHA256 keyed by a secret, so that the logs of an itinerary
+	// can be correlated without the airports being found back by hashing the likely itineraries
+	RedactionHashed Redaction = "hashed"
+	// RedactionOmitted doesn't write the airports at all
+	RedactionOmitted Redaction = "omitted"
+)
+
+// DefaultRedaction applies when the configuration sets none
+const DefaultRedaction = RedactionOmitted
+
+// minRedactionKeyLength is the minimum length of the secret of RedactionHashed
+const minRedactionKeyLength = 16
+
+// ParseRedaction returns the redaction named value, DefaultRedaction when it is empty
+func ParseRedaction(value string) (Redaction, error) {
+	if value == "" {
+		return DefaultRedaction, nil
+	}
+	if r := Redaction(value); r.Valid() {
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown redaction %q", value)
+}
+
+// Valid reports whether r is a known redaction
+func (r Redaction) Valid() bool {
+	switch r {
+	case RedactionPlain, RedactionHashed, RedactionOmitted:
+		return true
+	}
+	return false
+}
+
+// Redactor writes the paths to the logs with a redaction
+type Redactor struct {
+	Redaction Redaction
+	// Key is the secret of the HMAC of RedactionHashed
+	Key []byte
+}
+
+// NewRedactor returns a redactor of the paths, key is required by RedactionHashed
+func NewRedactor(redaction Redaction, key string) (Redactor, error) {
+	switch {
+	case !redaction.Valid():
+		return Redactor{}, fmt.Errorf("unknown redaction %q", redaction)
+	case redaction == RedactionHashed && len(key) < minRedactionKeyLength:
+		return Redactor{}, fmt.Errorf("the key of the %s redaction must have at least %d characters", redaction, minRedactionKeyLength)
+	}
+	return Redactor{Redaction: redaction, Key: []byte(key)}, nil
+}
+
+// Valid reports whether r is a known redaction, with its key when it needs one
+func (r Redactor) Valid() bool {
+	return r.Redaction.Valid() && (r.Redaction != RedactionHashed || len(r.Key) >= minRedactionKeyLength)
+}
+
+// Path returns the value to log for path, and false when it must not be logged
+func (r Redactor) Path(path string) (string, bool) {
+	switch r.Redaction {
+	case RedactionPlain:
+		return path, true
+	case RedactionHashed:
+		if len(r.Key) == 0 {
+			return "", false
+		}
+		mac := hmac.New(sha256.New, r.Key)
+		_, _ = mac.Write([]byte(path))
+		return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil)), true
+	}
+	return "", false
+}
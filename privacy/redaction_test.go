@@ -0,0 +1,67 @@
+package privacy_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/privacy"
+)
+
+func TestPrivacy_ParseRedaction(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		want      privacy.Redaction
+		wantError string
+	}{
+		{name: "should_default_to_omitted", value: "", want: privacy.RedactionOmitted},
+		{name: "should_parse_hashed", value: "hashed", want: privacy.RedactionHashed},
+		{name: "should_return_error_when_the_redaction_is_unknown", value: "masked", wantError: `unknown redaction "masked"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := privacy.ParseRedaction(tt.value)
+			if tt.wantError != "" {
+				assert.Error(t, err, tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPrivacy_NewRedactor(t *testing.T) {
+	_, err := privacy.NewRedactor(privacy.RedactionPlain, "")
+	assert.NilError(t, err)
+	_, err = privacy.NewRedactor(privacy.RedactionHashed, "short")
+	assert.Error(t, err, "the key of the hashed redaction must have at least 16 characters")
+	_, err = privacy.NewRedactor("masked", "")
+	assert.Error(t, err, `unknown redaction "masked"`)
+}
+
+func TestPrivacy_RedactorPath(t *testing.T) {
+	path := "[SFO,EWR]=>[SFO,EWR]"
+
+	value, ok := privacy.Redactor{Redaction: privacy.RedactionPlain}.Path(path)
+	assert.Assert(t, ok)
+	assert.Equal(t, path, value)
+
+	hashed, err := privacy.NewRedactor(privacy.RedactionHashed, "0123456789abcdef")
+	assert.NilError(t, err)
+	value, ok = hashed.Path(path)
+	assert.Assert(t, ok)
+	assert.Equal(t, "hmac-sha256:", value[:12])
+	assert.Equal(t, 12+64, len(value))
+
+	other, err := privacy.NewRedactor(privacy.RedactionHashed, "fedcba9876543210")
+	assert.NilError(t, err)
+	otherValue, _ := other.Path(path)
+	assert.Assert(t, value != otherValue, "the hash should depend on the key")
+
+	_, ok = privacy.Redactor{Redaction: privacy.RedactionOmitted}.Path(path)
+	assert.Assert(t, !ok)
+}
@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// maxIdleBuckets is the number of buckets kept before idle ones are pruned
+const maxIdleBuckets = 10000
+
+// Decision describes the outcome of a rate limit check
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter decides whether a client identified by a key may perform a request
+type Limiter interface {
+	Allow(key string) Decision
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// TokenBucket is an in-memory Limiter granting each key a bucket refilled at Rate tokens per second
+type TokenBucket struct {
+	Rate  float64
+	Burst int
+	Now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewTokenBucket returns a new instance of TokenBucket limiter
+func NewTokenBucket(rate float64, burst int) (*TokenBucket, error) {
+	switch {
+	case rate <= 0:
+		return nil, errors.New("rate")
+	case burst <= 0:
+		return nil, errors.New("burst")
+	}
+
+	return &TokenBucket{
+		Rate:    rate,
+		Burst:   burst,
+		Now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}, nil
+}
+
+// Allow takes a token from the bucket of key, if available
+func (l *TokenBucket) Allow(key string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.prune(now)
+		}
+		b = &bucket{tokens: float64(l.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(l.Burst), b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	b.last = now
+
+	decision := Decision{Limit: l.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = l.duration(1 - b.tokens)
+	}
+	decision.Remaining = int(b.tokens)
+	decision.Reset = l.duration(float64(l.Burst) - b.tokens)
+
+	return decision
+}
+
+// prune removes the buckets that have been refilled completely
+func (l *TokenBucket) prune(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.Rate >= float64(l.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func (l *TokenBucket) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.Rate * float64(time.Second))
+}
@@ -0,0 +1,88 @@
+package ratelimit_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/ratelimit"
+)
+
+func TestRateLimit_NewTokenBucket(t *testing.T) {
+	tests := []struct {
+		name      string
+		rate      float64
+		burst     int
+		wantError error
+	}{
+		{
+			name:      "should_return_success",
+			rate:      1,
+			burst:     1,
+			wantError: nil,
+		},
+		{
+			name:      "should_return_error_when_the_rate_is_not_positive",
+			rate:      0,
+			burst:     1,
+			wantError: errors.New("rate"),
+		},
+		{
+			name:      "should_return_error_when_the_burst_is_not_positive",
+			rate:      1,
+			burst:     0,
+			wantError: errors.New("burst"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ratelimit.NewTokenBucket(tt.rate, tt.burst)
+			if err != nil {
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+			}
+		})
+	}
+}
+
+func TestRateLimit_TokenBucketAllow(t *testing.T) {
+	now := time.Date(2023, 6, 13, 10, 0, 0, 0, time.UTC)
+
+	l, err := ratelimit.NewTokenBucket(1, 2)
+	require.NoError(t, err)
+	l.Now = func() time.Time { return now }
+
+	t.Run("should_allow_the_burst", func(t *testing.T) {
+		first := l.Allow("client")
+		second := l.Allow("client")
+
+		assert.Assert(t, first.Allowed)
+		assert.Equal(t, 1, first.Remaining)
+		assert.Assert(t, second.Allowed)
+		assert.Equal(t, 0, second.Remaining)
+		assert.Equal(t, 2*time.Second, second.Reset)
+	})
+
+	t.Run("should_reject_when_the_bucket_is_empty", func(t *testing.T) {
+		decision := l.Allow("client")
+
+		assert.Assert(t, !decision.Allowed)
+		assert.Equal(t, time.Second, decision.RetryAfter)
+	})
+
+	t.Run("should_keep_a_bucket_per_key", func(t *testing.T) {
+		decision := l.Allow("other")
+
+		assert.Assert(t, decision.Allowed)
+	})
+
+	t.Run("should_refill_over_time", func(t *testing.T) {
+		now = now.Add(time.Second)
+		decision := l.Allow("client")
+
+		assert.Assert(t, decision.Allowed)
+	})
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// QuotaStore keeps the daily usage of each client
+type QuotaStore interface {
+	// Add increments the usage of key for day by n, unless it would exceed limit, and returns the resulting usage
+	Add(ctx context.Context, key string, day time.Time, n, limit int) (used int, ok bool, err error)
+}
+
+// memoryQuotaStore is an in-memory QuotaStore
+type memoryQuotaStore struct {
+	mu    sync.Mutex
+	day   time.Time
+	usage map[string]int
+}
+
+// NewMemoryQuotaStore returns a QuotaStore keeping the usage of the current day in memory
+func NewMemoryQuotaStore() QuotaStore {
+	return &memoryQuotaStore{usage: make(map[string]int)}
+}
+
+func (s *memoryQuotaStore) Add(_ context.Context, key string, day time.Time, n, limit int) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// usage of previous days is no longer needed
+	if !day.Equal(s.day) {
+		s.day = day
+		s.usage = make(map[string]int)
+	}
+
+	used := s.usage[key]
+	if used+n > limit {
+		return used, false, nil
+	}
+	s.usage[key] = used + n
+
+	return used + n, true, nil
+}
+
+// Quota enforces a daily limit of units, e.g. flight legs, per client
+type Quota struct {
+	Store QuotaStore
+	Limit int
+	Now   func() time.Time
+}
+
+// NewQuota returns a new instance of Quota
+func NewQuota(store QuotaStore, limit int) (*Quota, error) {
+	switch {
+	case store == nil:
+		return nil, errors.New("store")
+	case limit <= 0:
+		return nil, errors.New("limit")
+	}
+
+	return &Quota{
+		Store: store,
+		Limit: limit,
+		Now:   time.Now,
+	}, nil
+}
+
+// Consume records n units for key, the decision is not allowed when the daily limit would be exceeded
+func (q *Quota) Consume(ctx context.Context, key string, n int) (Decision, error) {
+	now := q.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	reset := day.AddDate(0, 0, 1).Sub(now)
+
+	used, ok, err := q.Store.Add(ctx, key, day, n, q.Limit)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	decision := Decision{
+		Allowed:   ok,
+		Limit:     q.Limit,
+		Remaining: q.Limit - used,
+		Reset:     reset,
+	}
+	if !ok {
+		decision.RetryAfter = reset
+	}
+
+	return decision, nil
+}
@@ -0,0 +1,85 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/ratelimit"
+)
+
+func TestRateLimit_NewQuota(t *testing.T) {
+	tests := []struct {
+		name      string
+		store     ratelimit.QuotaStore
+		limit     int
+		wantError error
+	}{
+		{
+			name:      "should_return_success",
+			store:     ratelimit.NewMemoryQuotaStore(),
+			limit:     10,
+			wantError: nil,
+		},
+		{
+			name:      "should_return_error_when_the_store_is_nil",
+			store:     nil,
+			limit:     10,
+			wantError: errors.New("store"),
+		},
+		{
+			name:      "should_return_error_when_the_limit_is_not_positive",
+			store:     ratelimit.NewMemoryQuotaStore(),
+			limit:     0,
+			wantError: errors.New("limit"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ratelimit.NewQuota(tt.store, tt.limit)
+			if err != nil {
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+			}
+		})
+	}
+}
+
+func TestRateLimit_QuotaConsume(t *testing.T) {
+	now := time.Date(2023, 6, 13, 18, 0, 0, 0, time.UTC)
+
+	q, err := ratelimit.NewQuota(ratelimit.NewMemoryQuotaStore(), 5)
+	require.NoError(t, err)
+	q.Now = func() time.Time { return now }
+
+	t.Run("should_consume_within_the_limit", func(t *testing.T) {
+		decision, err := q.Consume(context.Background(), "client", 4)
+
+		assert.NilError(t, err)
+		assert.Assert(t, decision.Allowed)
+		assert.Equal(t, 1, decision.Remaining)
+		assert.Equal(t, 6*time.Hour, decision.Reset)
+	})
+
+	t.Run("should_reject_when_the_limit_would_be_exceeded", func(t *testing.T) {
+		decision, err := q.Consume(context.Background(), "client", 2)
+
+		assert.NilError(t, err)
+		assert.Assert(t, !decision.Allowed)
+		assert.Equal(t, 1, decision.Remaining)
+		assert.Equal(t, 6*time.Hour, decision.RetryAfter)
+	})
+
+	t.Run("should_reset_the_next_day", func(t *testing.T) {
+		now = now.Add(7 * time.Hour)
+		decision, err := q.Consume(context.Background(), "client", 5)
+
+		assert.NilError(t, err)
+		assert.Assert(t, decision.Allowed)
+		assert.Equal(t, 0, decision.Remaining)
+	})
+}
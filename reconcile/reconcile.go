@@ -0,0 +1,176 @@
+package reconcile
+
+import (
+	"sort"
+
+	"github.com/volume/service/user-flight-tracking/duplicates"
+	"github.com/volume/service/user-flight-tracking/models"
+)
+
+// Statuses of the consensus legs
+const (
+	// StatusMatching is a leg reported by several sources agreeing on its details
+	StatusMatching = "matching"
+	// StatusConflicting is a leg reported by several sources disagreeing on a detail
+	StatusConflicting = "conflicting"
+	// StatusSourceOnly is a leg reported by a single source
+	StatusSourceOnly = "source_only"
+)
+
+// Source is the list of legs of a traveler reported by a system, e.g. the booking tool or an airline feed
+type Source struct {
+	Name string
+	Legs []models.Leg
+}
+
+// Leg is a leg of the consensus, merged from the legs of the sources sharing its origin and destination
+type Leg struct {
+	Leg    models.Leg
+	Status string
+	// Sources holds the names of the sources reporting the leg
+	Sources []string
+	// Conflicts holds the details the sources disagree on, named as in models.LegDetails
+	Conflicts []string
+}
+
+// Conflict is a leg of a source disagreeing with the other sources
+type Conflict struct {
+	// Leg is the position of the leg in the source
+	Leg int
+	// Fields holds the details of the leg given differently by another source
+	Fields []string
+}
+
+// Diff compares the legs of a source with the consensus
+type Diff struct {
+	Source string
+	// Matching, Conflicting and Only hold the positions of the legs of the source, starting at 0
+	Matching    []int
+	Conflicting []Conflict
+	// Only holds the legs no other source reports
+	Only []int
+	// Missing holds the positions of the consensus legs the source doesn't report
+	Missing []int
+}
+
+// Result is the reconciliation of the sources of a traveler
+type Result struct {
+	// Legs holds the consensus legs, in the order they first appear in the sources
+	Legs  []Leg
+	Diffs []Diff
+}
+
+// Request returns the request of the path of the consensus legs
+func (r Result) Request(userID string) models.PathRequest {
+	legs := make([]models.Leg, len(r.Legs))
+	for i, leg := range r.Legs {
+		legs[i] = leg.Leg
+	}
+	return models.PathRequest{Legs: legs, UserID: userID}
+}
+
+// reported is a leg of a source
+type reported struct {
+	source, position int
+	leg              models.Leg
+}
+
+// Reconcile matches the legs of the sources by their origin and destination. The sources are given by precedence:
+// the consensus legs take their details from the first source giving them.
+func Reconcile(sources []Source) Result {
+	var all []models.Leg
+	for _, source := range sources {
+		all = append(all, source.Legs...)
+	}
+	consensus := duplicates.Merge(models.PathRequest{Legs: all}, duplicates.Find(all)).Legs
+
+	position := make(map[[2]string]int, len(consensus))
+	for i, leg := range consensus {
+		position[[2]string{leg.Origin, leg.Destination}] = i
+	}
+	groups := make([][]reported, len(consensus))
+	for s, source := range sources {
+		for i, leg := range source.Legs {
+			c := position[[2]string{leg.Origin, leg.Destination}]
+			groups[c] = append(groups[c], reported{source: s, position: i, leg: leg})
+		}
+	}
+
+	result := Result{Legs: make([]Leg, len(consensus)), Diffs: make([]Diff, len(sources))}
+	for s, source := range sources {
+		result.Diffs[s].Source = source.Name
+	}
+	for c, group := range groups {
+		leg := Leg{Leg: consensus[c], Status: StatusMatching}
+
+		reporters := make(map[int]bool)
+		for _, r := range group {
+			if !reporters[r.source] {
+				reporters[r.source] = true
+				leg.Sources = append(leg.Sources, sources[r.source].Name)
+			}
+		}
+		for s := range sources {
+			if !reporters[s] {
+				result.Diffs[s].Missing = append(result.Diffs[s].Missing, c)
+			}
+		}
+
+		conflicting := make(map[string]bool)
+		for _, r := range group {
+			diff := &result.Diffs[r.source]
+			if len(reporters) == 1 {
+				diff.Only = append(diff.Only, r.position)
+				continue
+			}
+			fields := conflicts(r, group)
+			if len(fields) == 0 {
+				diff.Matching = append(diff.Matching, r.position)
+				continue
+			}
+			diff.Conflicting = append(diff.Conflicting, Conflict{Leg: r.position, Fields: fields})
+			for _, field := range fields {
+				conflicting[field] = true
+			}
+		}
+
+		for _, name := range models.LegDetails {
+			if conflicting[name] {
+				leg.Conflicts = append(leg.Conflicts, name)
+			}
+		}
+		switch {
+		case len(reporters) == 1:
+			leg.Status = StatusSourceOnly
+		case len(leg.Conflicts) > 0:
+			leg.Status = StatusConflicting
+		}
+		result.Legs[c] = leg
+	}
+
+	// the consensus follows the first sources, the legs of the next ones are sorted back to their order
+	for _, diff := range result.Diffs {
+		sort.Ints(diff.Matching)
+		sort.Ints(diff.Only)
+		sort.Slice(diff.Conflicting, func(i, j int) bool { return diff.Conflicting[i].Leg < diff.Conflicting[j].Leg })
+	}
+	return result
+}
+
+// conflicts returns the details of the leg given differently by the legs of the other sources
+func conflicts(leg reported, group []reported) []string {
+	var fields []string
+	values := leg.leg.Details()
+	for i, name := range models.LegDetails {
+		for _, other := range group {
+			if other.source == leg.source {
+				continue
+			}
+			if value := *other.leg.Details()[i]; *values[i] != "" && value != "" && value != *values[i] {
+				fields = append(fields, name)
+				break
+			}
+		}
+	}
+	return fields
+}
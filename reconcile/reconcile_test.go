@@ -0,0 +1,90 @@
+package reconcile_test
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/reconcile"
+)
+
+func TestReconcile_Reconcile(t *testing.T) {
+	sources := []reconcile.Source{
+		{Name: "booking", Legs: []models.Leg{
+			{Origin: "SFO", Destination: "ATL", Carrier: "UA", FlightNumber: "88"},
+			{Origin: "ATL", Destination: "GSO", Departure: "2024-03-01T09:00", Arrival: "2024-03-01T10:30"},
+		}},
+		{Name: "airline", Legs: []models.Leg{
+			{Origin: "ATL", Destination: "GSO", Departure: "2024-03-01T11:00", Arrival: "2024-03-01T12:30"},
+			{Origin: "SFO", Destination: "ATL", Cabin: "economy"},
+			{Origin: "GSO", Destination: "IND"},
+		}},
+		{Name: "expenses", Legs: []models.Leg{
+			{Origin: "SFO", Destination: "ATL", Carrier: "UA"},
+		}},
+	}
+
+	result := reconcile.Reconcile(sources)
+
+	assert.DeepEqual(t, []reconcile.Leg{
+		{
+			Leg:     models.Leg{Origin: "SFO", Destination: "ATL", Carrier: "UA", FlightNumber: "88", Cabin: "economy"},
+			Status:  reconcile.StatusMatching,
+			Sources: []string{"booking", "airline", "expenses"},
+		},
+		{
+			Leg:       models.Leg{Origin: "ATL", Destination: "GSO", Departure: "2024-03-01T09:00", Arrival: "2024-03-01T10:30"},
+			Status:    reconcile.StatusConflicting,
+			Sources:   []string{"booking", "airline"},
+			Conflicts: []string{"departure", "arrival"},
+		},
+		{
+			Leg:     models.Leg{Origin: "GSO", Destination: "IND"},
+			Status:  reconcile.StatusSourceOnly,
+			Sources: []string{"airline"},
+		},
+	}, result.Legs)
+	assert.DeepEqual(t, []reconcile.Diff{
+		{
+			Source:      "booking",
+			Matching:    []int{0},
+			Conflicting: []reconcile.Conflict{{Leg: 1, Fields: []string{"departure", "arrival"}}},
+			Missing:     []int{2},
+		},
+		{
+			Source:      "airline",
+			Matching:    []int{1},
+			Conflicting: []reconcile.Conflict{{Leg: 0, Fields: []string{"departure", "arrival"}}},
+			Only:        []int{2},
+		},
+		{
+			Source:   "expenses",
+			Matching: []int{0},
+			Missing:  []int{1, 2},
+		},
+	}, result.Diffs)
+
+	assert.DeepEqual(t, models.PathRequest{
+		Legs: []models.Leg{
+			{Origin: "SFO", Destination: "ATL", Carrier: "UA", FlightNumber: "88", Cabin: "economy"},
+			{Origin: "ATL", Destination: "GSO", Departure: "2024-03-01T09:00", Arrival: "2024-03-01T10:30"},
+			{Origin: "GSO", Destination: "IND"},
+		},
+		UserID: "u-1",
+	}, result.Request("u-1"))
+}
+
+func TestReconcile_ReconcileRepeatedLegs(t *testing.T) {
+	// a source repeating a leg doesn't make it reported by several sources
+	result := reconcile.Reconcile([]reconcile.Source{
+		{Name: "booking", Legs: []models.Leg{{Origin: "SFO", Destination: "ATL", Carrier: "UA"}, {Origin: "SFO", Destination: "ATL", Carrier: "DL"}}},
+		{Name: "airline", Legs: []models.Leg{{Origin: "ATL", Destination: "GSO"}}},
+	})
+
+	assert.DeepEqual(t, []reconcile.Leg{
+		{Leg: models.Leg{Origin: "SFO", Destination: "ATL", Carrier: "UA"}, Status: reconcile.StatusSourceOnly, Sources: []string{"booking"}},
+		{Leg: models.Leg{Origin: "ATL", Destination: "GSO"}, Status: reconcile.StatusSourceOnly, Sources: []string{"airline"}},
+	}, result.Legs)
+	assert.DeepEqual(t, []int{0, 1}, result.Diffs[0].Only)
+}
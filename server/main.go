@@ -13,6 +13,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/volume/service/user-flight-tracking/api"
+	"github.com/volume/service/user-flight-tracking/config"
 )
 
 func main() {
@@ -23,7 +24,27 @@ func main() {
 }
 
 func run() error {
-	router := api.Routes()
+	cfg, err := config.Load(os.Getenv("CONFIG_PATH"))
+	if err != nil {
+		return err
+	}
+
+	if err := setupLogger(cfg.Log); err != nil {
+		return err
+	}
+
+	router, shutdownRoutes, err := api.Routes(cfg)
+	if err != nil {
+		return fmt.Errorf("routes: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(10))
+		defer cancel()
+
+		if err := shutdownRoutes(ctx); err != nil {
+			log.WithError(err).Error("releasing routes resources failed")
+		}
+	}()
 
 	srv := &http.Server{
 		Addr:         ":8080",
@@ -77,3 +98,20 @@ func run() error {
 
 	return nil
 }
+
+// setupLogger configures the global logger to write JSON entries
+func setupLogger(cfg config.Log) error {
+	log.SetFormatter(&log.JSONFormatter{})
+
+	if cfg.Level == "" {
+		return nil
+	}
+
+	level, err := log.ParseLevel(cfg.Level)
+	if err != nil {
+		return fmt.Errorf("log level: %w", err)
+	}
+	log.SetLevel(level)
+
+	return nil
+}
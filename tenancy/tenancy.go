@@ -0,0 +1,145 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/config"
+	"github.com/volume/service/user-flight-tracking/duplicates"
+)
+
+// Header is the header naming the tenant of callers whose credentials are not bound to one
+const Header = "X-Tenant-ID"
+
+// Optional features, enabled per tenant
+const (
+	FeatureCSV       = "csv"
+	FeatureJobs      = "jobs"
+	FeatureWebhooks  = "webhooks"
+	FeatureAnalyze   = "analyze"
+	FeatureComplete  = "complete"
+	FeatureReconcile = "reconcile"
+	FeatureDiff      = "diff"
+	FeatureRoutes    = "routes"
+)
+
+// Features are the optional features that can be enabled per tenant
+var Features = []string{FeatureCSV, FeatureJobs, FeatureWebhooks, FeatureAnalyze, FeatureComplete, FeatureReconcile, FeatureDiff, FeatureRoutes}
+
+// Tenant is a customer whose itineraries, caches, limits and metrics are isolated from the other tenants
+type Tenant struct {
+	ID string
+	// StrictAirports only accepts IATA codes of three uppercase letters
+	StrictAirports bool
+	// Features lists the optional features enabled, all of them when empty
+	Features []string
+	// DuplicateLegs is the policy of the duplicate legs of the tenant, the one of the service when empty
+	DuplicateLegs duplicates.Policy
+}
+
+// Allows reports whether feature is enabled for the tenant
+func (t Tenant) Allows(feature string) bool {
+	if len(t.Features) == 0 {
+		return true
+	}
+	for _, f := range t.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry holds the configured tenants
+type Registry struct {
+	tenants map[string]Tenant
+}
+
+// NewRegistry returns the registry of the configured tenants
+func NewRegistry(tenants []config.Tenant) (*Registry, error) {
+	r := &Registry{tenants: make(map[string]Tenant, len(tenants))}
+	for _, t := range tenants {
+		switch {
+		case t.ID == "":
+			return nil, errors.New("tenant id")
+		case r.tenants[t.ID].ID != "":
+			return nil, fmt.Errorf("duplicate tenant %q", t.ID)
+		}
+		for _, feature := range t.Features {
+			if !known(feature) {
+				return nil, fmt.Errorf("tenant %q: unknown feature %q", t.ID, feature)
+			}
+		}
+
+		tenant := Tenant{
+			ID:             t.ID,
+			StrictAirports: t.StrictAirportValidation,
+			Features:       t.Features,
+		}
+		if t.DuplicateLegs != "" {
+			policy, err := duplicates.ParsePolicy(t.DuplicateLegs)
+			if err != nil {
+				return nil, fmt.Errorf("tenant %q: %w", t.ID, err)
+			}
+			tenant.DuplicateLegs = policy
+		}
+		r.tenants[t.ID] = tenant
+	}
+
+	return r, nil
+}
+
+// Lookup returns the tenant identified by id
+func (r *Registry) Lookup(id string) (Tenant, bool) {
+	t, ok := r.tenants[id]
+	return t, ok
+}
+
+// Len returns the number of tenants configured
+func (r *Registry) Len() int {
+	return len(r.tenants)
+}
+
+func known(feature string) bool {
+	for _, f := range Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+type tenantKey struct{}
+
+// NewContext returns a copy of ctx carrying the tenant
+func NewContext(ctx context.Context, tenant Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// FromContext returns the tenant stored in ctx, if any
+func FromContext(ctx context.Context) (Tenant, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(Tenant)
+	return tenant, ok
+}
+
+// ID returns the id of the tenant of ctx, empty for requests outside of any tenant
+func ID(ctx context.Context) string {
+	tenant, _ := FromContext(ctx)
+	return tenant.ID
+}
+
+// Scope prefixes key with the tenant of ctx, so that the keys of different tenants never collide.
+// Keys outside of any tenant are returned unchanged.
+func Scope(ctx context.Context, key string) string {
+	if id := ID(ctx); id != "" {
+		return id + "/" + key
+	}
+	return key
+}
+
+// Owner returns the key under which the resources created by the caller of ctx are kept, scoped by its tenant
+func Owner(ctx context.Context) string {
+	return Scope(ctx, auth.Owner(ctx))
+}
@@ -0,0 +1,96 @@
+package tenancy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/auth"
+	"github.com/volume/service/user-flight-tracking/config"
+	"github.com/volume/service/user-flight-tracking/duplicates"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+func TestTenancy_NewRegistry(t *testing.T) {
+	tests := []struct {
+		name      string
+		tenants   []config.Tenant
+		wantError error
+	}{
+		{
+			name:    "should_return_success",
+			tenants: []config.Tenant{{ID: "acme", Features: []string{tenancy.FeatureJobs}}, {ID: "globex"}},
+		},
+		{
+			name:      "should_return_error_when_the_id_is_empty",
+			tenants:   []config.Tenant{{}},
+			wantError: errors.New("tenant id"),
+		},
+		{
+			name:      "should_return_error_when_the_id_is_duplicated",
+			tenants:   []config.Tenant{{ID: "acme"}, {ID: "acme"}},
+			wantError: errors.New(`duplicate tenant "acme"`),
+		},
+		{
+			name:      "should_return_error_when_the_feature_is_unknown",
+			tenants:   []config.Tenant{{ID: "acme", Features: []string{"teleport"}}},
+			wantError: errors.New(`tenant "acme": unknown feature "teleport"`),
+		},
+		{
+			name:      "should_return_error_when_the_duplicate_legs_policy_is_unknown",
+			tenants:   []config.Tenant{{ID: "acme", DuplicateLegs: "ignore"}},
+			wantError: errors.New(`tenant "acme": unknown duplicate legs policy "ignore"`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tenancy.NewRegistry(tt.tenants)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestTenancy_Registry(t *testing.T) {
+	registry, err := tenancy.NewRegistry([]config.Tenant{
+		{ID: "acme", StrictAirportValidation: true, Features: []string{tenancy.FeatureJobs}, DuplicateLegs: "reject"},
+		{ID: "globex"},
+	})
+	require.NoError(t, err)
+
+	acme, ok := registry.Lookup("acme")
+	require.True(t, ok)
+	assert.Assert(t, acme.StrictAirports)
+	assert.Assert(t, acme.Allows(tenancy.FeatureJobs))
+	assert.Assert(t, !acme.Allows(tenancy.FeatureWebhooks))
+	assert.Equal(t, duplicates.PolicyReject, acme.DuplicateLegs)
+
+	globex, ok := registry.Lookup("globex")
+	require.True(t, ok)
+	assert.Assert(t, globex.Allows(tenancy.FeatureWebhooks), "all the features are enabled when none is listed")
+	assert.Equal(t, duplicates.Policy(""), globex.DuplicateLegs)
+
+	_, ok = registry.Lookup("initech")
+	assert.Assert(t, !ok)
+}
+
+func TestTenancy_Scope(t *testing.T) {
+	identity := auth.Identity{Subject: "agent", Method: auth.MethodAPIKey}
+	ctx := auth.NewContext(context.Background(), identity)
+	acme := tenancy.NewContext(ctx, tenancy.Tenant{ID: "acme"})
+
+	assert.Equal(t, "key", tenancy.Scope(ctx, "key"))
+	assert.Equal(t, "acme/key", tenancy.Scope(acme, "key"))
+	assert.Equal(t, "api_key:agent", tenancy.Owner(ctx))
+	assert.Equal(t, "acme/api_key:agent", tenancy.Owner(acme))
+	assert.Equal(t, "", tenancy.ID(ctx))
+	assert.Equal(t, "acme", tenancy.ID(acme))
+}
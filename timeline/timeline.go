@@ -0,0 +1,61 @@
+package timeline
+
+import (
+	"math"
+	"time"
+
+	"github.com/volume/service/user-flight-tracking/airports"
+	"github.com/volume/service/user-flight-tracking/dto"
+)
+
+// Build returns the timeline of the legs of a path, ok is false unless every leg has its departure and arrival
+func Build(legs []dto.Leg) (timeline dto.Timeline, ok bool) {
+	if len(legs) == 0 {
+		return dto.Timeline{}, false
+	}
+
+	timeline.Legs = make([]dto.TimelineLeg, len(legs))
+	for i, leg := range legs {
+		if leg.Departure.IsZero() || leg.Arrival.IsZero() {
+			return dto.Timeline{}, false
+		}
+
+		entry := dto.TimelineLeg{
+			Origin:          leg.Origin,
+			Destination:     leg.Destination,
+			Departure:       leg.Departure,
+			Arrival:         leg.Arrival,
+			Block:           leg.Arrival.Sub(leg.Departure),
+			Overnight:       localDate(leg.Arrival) > localDate(leg.Departure),
+			CrossesDateLine: CrossesDateLine(leg.Origin, leg.Destination),
+		}
+		if i > 0 {
+			entry.Layover = leg.Departure.Sub(legs[i-1].Arrival)
+		}
+		timeline.Legs[i] = entry
+	}
+
+	timeline.Elapsed = legs[len(legs)-1].Arrival.Sub(legs[0].Departure)
+	return timeline, true
+}
+
+// CrossesDateLine reports whether the shortest route between the airports flies over the antimeridian,
+// false when any of them is unknown
+func CrossesDateLine(origin, destination string) bool {
+	from, ok := airports.Lookup(origin)
+	if !ok {
+		return false
+	}
+	to, ok := airports.Lookup(destination)
+	if !ok {
+		return false
+	}
+
+	return math.Signbit(from.Longitude) != math.Signbit(to.Longitude) && math.Abs(from.Longitude-to.Longitude) > 180
+}
+
+// localDate returns the number of the local date of t, to compare dates across time zones
+func localDate(t time.Time) int {
+	year, month, day := t.Date()
+	return year*10000 + int(month)*100 + day
+}
@@ -0,0 +1,81 @@
+package timeline_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/dto"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/timeline"
+)
+
+func TestTimeline_Build(t *testing.T) {
+	timed := func(t *testing.T, leg models.Leg) dto.Leg {
+		departure, arrival, ok := leg.Times()
+		require.True(t, ok)
+		return dto.Leg{Origin: leg.Origin, Destination: leg.Destination, Departure: departure, Arrival: arrival}
+	}
+
+	t.Run("should_return_the_schedule_of_the_legs", func(t *testing.T) {
+		legs := []dto.Leg{
+			timed(t, models.Leg{Origin: "SFO", Destination: "NRT", Departure: "2023-06-13T11:00", Arrival: "2023-06-14T14:30"}),
+			timed(t, models.Leg{Origin: "NRT", Destination: "SIN", Departure: "2023-06-14T17:00", Arrival: "2023-06-14T23:30"}),
+		}
+
+		schedule, ok := timeline.Build(legs)
+
+		require.True(t, ok)
+		require.Equal(t, 2, len(schedule.Legs))
+		assert.Equal(t, 21*time.Hour+30*time.Minute, schedule.Elapsed)
+
+		first := schedule.Legs[0]
+		assert.Equal(t, time.Date(2023, 6, 13, 18, 0, 0, 0, time.UTC), first.Departure.UTC())
+		assert.Equal(t, 11*time.Hour+30*time.Minute, first.Block)
+		assert.Equal(t, time.Duration(0), first.Layover)
+		assert.Assert(t, first.Overnight)
+		assert.Assert(t, first.CrossesDateLine)
+
+		second := schedule.Legs[1]
+		assert.Equal(t, 7*time.Hour+30*time.Minute, second.Block)
+		assert.Equal(t, 2*time.Hour+30*time.Minute, second.Layover)
+		assert.Assert(t, !second.Overnight)
+		assert.Assert(t, !second.CrossesDateLine)
+	})
+
+	t.Run("should_not_return_a_schedule_when_a_leg_is_not_timed", func(t *testing.T) {
+		legs := []dto.Leg{
+			timed(t, models.Leg{Origin: "SFO", Destination: "ATL", Departure: "2023-06-13T08:00", Arrival: "2023-06-13T15:30"}),
+			{Origin: "ATL", Destination: "EWR"},
+		}
+
+		_, ok := timeline.Build(legs)
+		assert.Assert(t, !ok)
+
+		_, ok = timeline.Build(nil)
+		assert.Assert(t, !ok)
+	})
+}
+
+func TestTimeline_CrossesDateLine(t *testing.T) {
+	tests := []struct {
+		name        string
+		origin      string
+		destination string
+		want        bool
+	}{
+		{name: "should_cross_westbound_over_the_pacific", origin: "LAX", destination: "SYD", want: true},
+		{name: "should_cross_eastbound_over_the_pacific", origin: "AKL", destination: "HNL", want: true},
+		{name: "should_not_cross_over_the_atlantic", origin: "JFK", destination: "LHR"},
+		{name: "should_not_cross_over_the_prime_meridian", origin: "LHR", destination: "NRT"},
+		{name: "should_not_cross_with_unknown_airports", origin: "XXX", destination: "SYD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, timeline.CrossesDateLine(tt.origin, tt.destination))
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stdoutExporter writes every span as a JSON line
+type stdoutExporter struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+// NewStdoutExporter returns an Exporter writing JSON lines to w
+func NewStdoutExporter(w io.Writer) (Exporter, error) {
+	if w == nil {
+		return nil, errors.New("writer")
+	}
+
+	return &stdoutExporter{writer: w}, nil
+}
+
+func (e *stdoutExporter) Export(span SpanData) {
+	line := map[string]interface{}{
+		"trace_id":    span.TraceID.String(),
+		"span_id":     span.SpanID.String(),
+		"name":        span.Name,
+		"kind":        span.Kind,
+		"start":       span.Start.UTC().Format(time.RFC3339Nano),
+		"duration_ms": float64(span.End.Sub(span.Start).Microseconds()) / 1000,
+		"attributes":  span.Attributes,
+	}
+	if span.Parent.IsValid() {
+		line["parent_id"] = span.Parent.String()
+	}
+	if span.Error != "" {
+		line["error"] = span.Error
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_ = json.NewEncoder(e.writer).Encode(line)
+}
+
+func (e *stdoutExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+const (
+	otlpBatchSize     = 256
+	otlpFlushInterval = 5 * time.Second
+)
+
+// otlpExporter sends batches of spans to an OTLP/HTTP collector using the JSON encoding
+type otlpExporter struct {
+	endpoint string
+	service  string
+	client   *http.Client
+
+	mu      sync.Mutex
+	pending []SpanData
+	flush   chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// NewOTLPExporter returns an Exporter sending spans to the OTLP/HTTP endpoint, e.g. http://localhost:4318
+func NewOTLPExporter(endpoint, service string, client *http.Client) (Exporter, error) {
+	switch {
+	case endpoint == "":
+		return nil, errors.New("endpoint")
+	case service == "":
+		return nil, errors.New("service")
+	case client == nil:
+		return nil, errors.New("client")
+	}
+
+	e := &otlpExporter{
+		endpoint: strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		service:  service,
+		client:   client,
+		flush:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go e.run()
+
+	return e, nil
+}
+
+func (e *otlpExporter) Export(span SpanData) {
+	e.mu.Lock()
+	e.pending = append(e.pending, span)
+	full := len(e.pending) >= otlpBatchSize
+	e.mu.Unlock()
+
+	if full {
+		select {
+		case e.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Shutdown sends the pending spans and stops the background sender
+func (e *otlpExporter) Shutdown(ctx context.Context) error {
+	close(e.done)
+	select {
+	case <-e.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return e.send(ctx)
+}
+
+func (e *otlpExporter) run() {
+	defer close(e.stopped)
+
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+		case <-e.flush:
+		}
+		// failed batches are dropped, tracing must never affect the requests
+		_ = e.send(context.Background())
+	}
+}
+
+func (e *otlpExporter) send(ctx context.Context) error {
+	e.mu.Lock()
+	spans := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(e.payload(spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("collector answered %s", resp.Status)
+	}
+
+	return nil
+}
+
+// payload encodes the spans as an OTLP ExportTraceServiceRequest
+func (e *otlpExporter) payload(spans []SpanData) map[string]interface{} {
+	encoded := make([]map[string]interface{}, 0, len(spans))
+	for _, span := range spans {
+		s := map[string]interface{}{
+			"traceId":           span.TraceID.String(),
+			"spanId":            span.SpanID.String(),
+			"name":              span.Name,
+			"kind":              otlpKind(span.Kind),
+			"startTimeUnixNano": strconv.FormatInt(span.Start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(span.End.UnixNano(), 10),
+			"attributes":        otlpAttributes(span.Attributes),
+		}
+		if span.Parent.IsValid() {
+			s["parentSpanId"] = span.Parent.String()
+		}
+		if span.Error != "" {
+			s["status"] = map[string]interface{}{"code": 2, "message": span.Error}
+		}
+		encoded = append(encoded, s)
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []map[string]interface{}{{
+			"resource": map[string]interface{}{
+				"attributes": otlpAttributes(map[string]interface{}{"service.name": e.service}),
+			},
+			"scopeSpans": []map[string]interface{}{{
+				"scope": map[string]interface{}{"name": "github.com/volume/service/user-flight-tracking/tracing"},
+				"spans": encoded,
+			}},
+		}},
+	}
+}
+
+func otlpKind(kind string) int {
+	switch kind {
+	case KindServer:
+		return 2
+	case KindClient:
+		return 3
+	default:
+		return 1
+	}
+}
+
+func otlpAttributes(attributes map[string]interface{}) []map[string]interface{} {
+	encoded := make([]map[string]interface{}, 0, len(attributes))
+	for key, value := range attributes {
+		var v map[string]interface{}
+		switch value := value.(type) {
+		case bool:
+			v = map[string]interface{}{"boolValue": value}
+		case int:
+			v = map[string]interface{}{"intValue": strconv.Itoa(value)}
+		case int64:
+			v = map[string]interface{}{"intValue": strconv.FormatInt(value, 10)}
+		case float64:
+			v = map[string]interface{}{"doubleValue": value}
+		default:
+			v = map[string]interface{}{"stringValue": fmt.Sprint(value)}
+		}
+		encoded = append(encoded, map[string]interface{}{"key": key, "value": v})
+	}
+	return encoded
+}
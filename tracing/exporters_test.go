@@ -0,0 +1,70 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/tracing"
+)
+
+func TestTracing_StdoutExporter(t *testing.T) {
+	var buffer bytes.Buffer
+	exporter, err := tracing.NewStdoutExporter(&buffer)
+	require.NoError(t, err)
+	tracer, err := tracing.NewTracer("user-flight-tracking", exporter)
+	require.NoError(t, err)
+
+	_, span := tracer.Start(context.Background(), "gateway.findPath", tracing.KindInternal)
+	span.SetAttribute("airports", 5)
+	span.End()
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &line))
+	assert.Equal(t, "gateway.findPath", line["name"])
+	assert.Equal(t, span.SpanContext().TraceID.String(), line["trace_id"])
+	assert.Equal(t, float64(5), line["attributes"].(map[string]interface{})["airports"])
+}
+
+func TestTracing_OTLPExporter(t *testing.T) {
+	requests := make(chan map[string]interface{}, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests <- body
+	}))
+	defer collector.Close()
+
+	exporter, err := tracing.NewOTLPExporter(collector.URL, "user-flight-tracking", collector.Client())
+	require.NoError(t, err)
+	tracer, err := tracing.NewTracer("user-flight-tracking", exporter)
+	require.NoError(t, err)
+
+	ctx, root := tracer.Start(context.Background(), "HTTP POST /calculate", tracing.KindServer)
+	_, child := tracer.Start(ctx, "gateway.buildGraph", tracing.KindInternal)
+	child.End()
+	root.End()
+
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	body := <-requests
+	resourceSpans := body["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	scopeSpans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})
+	spans := scopeSpans["spans"].([]interface{})
+	require.Len(t, spans, 2)
+
+	first := spans[0].(map[string]interface{})
+	assert.Equal(t, "gateway.buildGraph", first["name"])
+	assert.Equal(t, root.SpanContext().TraceID.String(), first["traceId"])
+	assert.Equal(t, root.SpanContext().SpanID.String(), first["parentSpanId"])
+	assert.Equal(t, float64(2), spans[1].(map[string]interface{})["kind"])
+}
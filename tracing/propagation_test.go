@@ -0,0 +1,65 @@
+package tracing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/tracing"
+)
+
+func TestTracing_ParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantSampled bool
+		wantError   bool
+	}{
+		{
+			name:        "should_parse_a_sampled_traceparent",
+			value:       "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantSampled: true,
+		},
+		{
+			name:        "should_parse_a_not_sampled_traceparent",
+			value:       "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			wantSampled: false,
+		},
+		{
+			name:      "should_return_error_when_the_trace_id_is_zero",
+			value:     "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			wantError: true,
+		},
+		{
+			name:      "should_return_error_when_the_version_is_invalid",
+			value:     "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantError: true,
+		},
+		{
+			name:      "should_return_error_when_the_ids_are_uppercase",
+			value:     "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01",
+			wantError: true,
+		},
+		{
+			name:      "should_return_error_when_the_value_is_malformed",
+			value:     "00-4bf92f3577b34da6a3ce929d0e0e4736",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := tracing.ParseTraceparent(tt.value)
+			if tt.wantError {
+				assert.Assert(t, err != nil)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+			assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+			assert.Equal(t, tt.wantSampled, sc.Sampled)
+			assert.Equal(t, tt.value, tracing.FormatTraceparent(sc))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Exporter ships finished spans to a backend
+type Exporter interface {
+	Export(span SpanData)
+	Shutdown(ctx context.Context) error
+}
+
+// Tracer creates spans and hands them to its exporter once finished
+type Tracer struct {
+	Service  string
+	Exporter Exporter
+	now      func() time.Time
+}
+
+// NewTracer returns a new instance of Tracer
+func NewTracer(service string, exporter Exporter) (*Tracer, error) {
+	switch {
+	case service == "":
+		return nil, errors.New("service")
+	case exporter == nil:
+		return nil, errors.New("exporter")
+	}
+
+	return &Tracer{
+		Service:  service,
+		Exporter: exporter,
+		now:      time.Now,
+	}, nil
+}
+
+// Start starts a span as a child of the active or remote span of ctx, or as the root of a new trace
+func (t *Tracer) Start(ctx context.Context, name, kind string) (context.Context, *Span) {
+	data := SpanData{
+		Name:       name,
+		Kind:       kind,
+		Start:      t.now(),
+		Attributes: make(map[string]interface{}),
+	}
+
+	if parent := SpanFromContext(ctx); parent != nil {
+		data.TraceID = parent.data.TraceID
+		data.Sampled = parent.data.Sampled
+		data.Parent = parent.data.SpanID
+	} else if remote, ok := ctx.Value(remoteKey{}).(SpanContext); ok && remote.IsValid() {
+		data.TraceID = remote.TraceID
+		data.Sampled = remote.Sampled
+		data.Parent = remote.SpanID
+	} else {
+		data.TraceID = newTraceID()
+		data.Sampled = true
+	}
+	data.SpanID = newSpanID()
+
+	span := &Span{tracer: t, data: data}
+	return ContextWithSpan(ctx, span), span
+}
+
+// Shutdown flushes the spans pending in the exporter
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	return t.Exporter.Shutdown(ctx)
+}
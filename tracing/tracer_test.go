@@ -0,0 +1,111 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/tracing"
+)
+
+// memoryExporter keeps the exported spans for the assertions
+type memoryExporter struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (e *memoryExporter) Export(span tracing.SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+func (e *memoryExporter) Shutdown(context.Context) error { return nil }
+
+func TestTracing_NewTracer(t *testing.T) {
+	tests := []struct {
+		name      string
+		service   string
+		exporter  tracing.Exporter
+		wantError error
+	}{
+		{
+			name:      "should_return_success",
+			service:   "user-flight-tracking",
+			exporter:  &memoryExporter{},
+			wantError: nil,
+		},
+		{
+			name:      "should_return_error_when_the_service_is_empty",
+			service:   "",
+			exporter:  &memoryExporter{},
+			wantError: errors.New("service"),
+		},
+		{
+			name:      "should_return_error_when_the_exporter_is_nil",
+			service:   "user-flight-tracking",
+			exporter:  nil,
+			wantError: errors.New("exporter"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tracing.NewTracer(tt.service, tt.exporter)
+			if err != nil {
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+			}
+		})
+	}
+}
+
+func TestTracing_StartSpan(t *testing.T) {
+	exporter := &memoryExporter{}
+	tracer, err := tracing.NewTracer("user-flight-tracking", exporter)
+	require.NoError(t, err)
+
+	t.Run("should_be_a_no_op_without_tracer", func(t *testing.T) {
+		ctx, span := tracing.StartSpan(context.Background(), "gateway.buildGraph")
+		span.SetAttribute("airports", 5)
+		span.End()
+
+		assert.Assert(t, span == nil)
+		assert.Assert(t, tracing.SpanFromContext(ctx) == nil)
+	})
+
+	t.Run("should_continue_the_remote_trace", func(t *testing.T) {
+		exporter.spans = nil
+		header := http.Header{}
+		header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		ctx := tracing.ContextWithTracer(tracing.Extract(context.Background(), header), tracer)
+		ctx, root := tracer.Start(ctx, "HTTP POST /calculate", tracing.KindServer)
+		_, child := tracing.StartSpan(ctx, "mediator.GetFlightsPath")
+		child.RecordError(errors.New("no initial flight found"))
+		child.End()
+		root.End()
+		root.End()
+
+		require.Len(t, exporter.spans, 2)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", exporter.spans[0].TraceID.String())
+		assert.Equal(t, root.SpanContext().SpanID, exporter.spans[0].Parent)
+		assert.Equal(t, "no initial flight found", exporter.spans[0].Error)
+		assert.Equal(t, "00f067aa0ba902b7", exporter.spans[1].Parent.String())
+	})
+
+	t.Run("should_not_export_spans_of_not_sampled_traces", func(t *testing.T) {
+		exporter.spans = nil
+		header := http.Header{}
+		header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+
+		_, span := tracer.Start(tracing.Extract(context.Background(), header), "HTTP POST /calculate", tracing.KindServer)
+		span.End()
+
+		assert.Equal(t, 0, len(exporter.spans))
+	})
+}
@@ -0,0 +1,45 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned for the deliveries to an address of the internal networks
+var ErrForbiddenAddress = errors.New("forbidden address")
+
+// NewClient returns the client of the deliveries. The addresses are checked once resolved, before connecting, so
+// neither the subscription URLs nor their redirects reach the loopback, private or link-local networks.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: publicAddressesOnly}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// no proxy, it would connect to the addresses on behalf of the client
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: maxConcurrentAttempts,
+		},
+	}
+}
+
+// publicAddressesOnly rejects the connections to the addresses of the internal networks
+func publicAddressesOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	case ip.IsLoopback(), ip.IsPrivate(), ip.IsLinkLocalUnicast(), ip.IsLinkLocalMulticast(), ip.IsMulticast(), ip.IsUnspecified():
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package webhooks_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/webhooks"
+)
+
+func TestWebhooks_NewClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+	client := webhooks.NewClient(time.Second)
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "should_reject_the_loopback_addresses", url: server.URL},
+		{name: "should_reject_the_resolved_addresses", url: "http://localhost:1/"},
+		{name: "should_reject_the_private_addresses", url: "http://10.0.0.1/"},
+		{name: "should_reject_the_link_local_addresses", url: "http://169.254.169.254/latest/meta-data/"},
+		{name: "should_reject_the_ipv6_loopback", url: "http://[::1]:1/"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Get(tt.url)
+			assert.Assert(t, errors.Is(err, webhooks.ErrForbiddenAddress), "unexpected error: %v", err)
+		})
+	}
+}
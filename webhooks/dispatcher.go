@@ -0,0 +1,351 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/volume/service/user-flight-tracking/logging"
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+)
+
+const (
+	// maxConcurrentAttempts is the number of workers sending the deliveries
+	maxConcurrentAttempts = 16
+	// maxPendingDeliveries limits the deliveries queued or waiting for a retry, the next ones are dead-lettered
+	maxPendingDeliveries = 1000
+	// maxDeadLetters limits the abandoned deliveries kept, the oldest are dropped first
+	maxDeadLetters = 1000
+	// userAgent identifies the deliveries
+	userAgent = "user-flight-tracking-webhooks"
+)
+
+// Publisher sends events to the subscriptions of their owner
+type Publisher interface {
+	Publish(ctx context.Context, owner string, event models.WebhookEvent)
+}
+
+// DeadLetter is a delivery abandoned after its last attempt
+type DeadLetter struct {
+	DeliveryID string
+	// Tenant is the tenant of the request that published the event
+	Tenant       string
+	Subscription Subscription
+	Event        models.WebhookEvent
+	Attempts     int
+	LastError    string
+	FailedAt     time.Time
+}
+
+// errQueueFull is the error of the deliveries dead-lettered without an attempt
+var errQueueFull = errors.New("delivery queue full")
+
+// Dispatcher delivers the published events in the background, retrying failed deliveries with exponential backoff.
+// The deliveries are queued to a fixed number of workers.
+type Dispatcher struct {
+	Logger *log.Entry
+	Store  SubscriptionStore
+	Client *http.Client
+	// MaxAttempts is the number of attempts of a delivery before it is dead-lettered
+	MaxAttempts int
+	// MinBackoff and MaxBackoff bound the wait between attempts
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	Now        func() time.Time
+
+	queue       chan *delivery
+	mu          sync.Mutex
+	pending     int
+	deadLetters []DeadLetter
+	closed      bool
+	done        chan struct{}
+	ctx         context.Context
+	abort       context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+// NewDispatcher returns a dispatcher delivering the events to the subscriptions of store
+func NewDispatcher(log *log.Entry, store SubscriptionStore, client *http.Client, maxAttempts int, minBackoff, maxBackoff time.Duration) (*Dispatcher, error) {
+	switch {
+	case log == nil:
+		return nil, errors.New("logger")
+	case store == nil:
+		return nil, errors.New("store")
+	case client == nil:
+		return nil, errors.New("client")
+	case maxAttempts <= 0:
+		return nil, errors.New("maxAttempts")
+	case minBackoff <= 0 || maxBackoff < minBackoff:
+		return nil, errors.New("backoff")
+	}
+
+	ctx, abort := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		Logger:      log,
+		Store:       store,
+		Client:      client,
+		MaxAttempts: maxAttempts,
+		MinBackoff:  minBackoff,
+		MaxBackoff:  maxBackoff,
+		Now:         time.Now,
+		queue:       make(chan *delivery, maxPendingDeliveries),
+		done:        make(chan struct{}),
+		ctx:         ctx,
+		abort:       abort,
+	}
+	for i := 0; i < maxConcurrentAttempts; i++ {
+		go d.work()
+	}
+	return d, nil
+}
+
+// delivery is an event sent to a subscription
+type delivery struct {
+	logger       *log.Entry
+	id           string
+	tenant       string
+	subscription Subscription
+	event        models.WebhookEvent
+	body         []byte
+	attempt      int
+}
+
+// Publish enqueues a delivery of event to every subscription of owner accepting its type.
+// The event id and creation time are set when empty.
+func (d *Dispatcher) Publish(ctx context.Context, owner string, event models.WebhookEvent) {
+	logger := logging.FromContext(ctx, d.Logger)
+
+	subscriptions, err := d.Store.List(ctx, owner)
+	if err != nil {
+		logger.WithError(err).Error("error listing webhook subscriptions")
+		return
+	}
+
+	if event.ID == "" {
+		event.ID = newID()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = d.Now().UTC()
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		logger.WithError(err).Error("error encoding webhook event")
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscription.Accepts(event.Type) {
+			continue
+		}
+
+		deliveryID := newID()
+		delivery := &delivery{
+			logger: logger.WithFields(log.Fields{
+				"subscription": subscription.ID,
+				"event":        event.ID,
+				"event_type":   event.Type,
+				"delivery":     deliveryID,
+			}),
+			id:           deliveryID,
+			tenant:       tenancy.ID(ctx),
+			subscription: subscription,
+			event:        event,
+			body:         body,
+		}
+		if d.pending >= maxPendingDeliveries {
+			delivery.logger.Error("webhook delivery dead-lettered")
+			d.deadLetter(delivery, errQueueFull)
+			continue
+		}
+
+		// the queue holds every pending delivery, sending never blocks
+		d.pending++
+		d.wg.Add(1)
+		d.queue <- delivery
+	}
+}
+
+// DeadLetters returns the abandoned deliveries of owner, oldest first
+func (d *Dispatcher) DeadLetters(owner string) []DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var deadLetters []DeadLetter
+	for _, deadLetter := range d.deadLetters {
+		if deadLetter.Subscription.Owner == owner {
+			deadLetters = append(deadLetters, deadLetter)
+		}
+	}
+	return deadLetters
+}
+
+// Erase removes the dead letters of the events about userID in the tenant of ctx, returning their number
+func (d *Dispatcher) Erase(ctx context.Context, userID string) (int, error) {
+	tenant := tenancy.ID(ctx)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	kept := d.deadLetters[:0]
+	for _, deadLetter := range d.deadLetters {
+		if deadLetter.Tenant != tenant || deadLetter.Event.Data.UserID != userID {
+			kept = append(kept, deadLetter)
+		}
+	}
+	erased := len(d.deadLetters) - len(kept)
+	for i := len(kept); i < len(d.deadLetters); i++ {
+		d.deadLetters[i] = DeadLetter{}
+	}
+	d.deadLetters = kept
+
+	return erased, nil
+}
+
+// Shutdown stops the retries and waits for the attempts in flight, aborting them once ctx is done
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	close(d.done)
+	d.mu.Unlock()
+
+	stopped := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(d.queue)
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		d.abort()
+		return ctx.Err()
+	}
+}
+
+// work sends the queued deliveries until the dispatcher is shut down
+func (d *Dispatcher) work() {
+	for delivery := range d.queue {
+		d.deliver(delivery)
+	}
+}
+
+// deliver attempts a delivery, waiting for its retry in the background or dead-lettering it after the last attempt
+func (d *Dispatcher) deliver(delivery *delivery) {
+	delivery.attempt++
+	logger := delivery.logger.WithField("attempt", delivery.attempt)
+
+	err := d.attempt(delivery.subscription, delivery.event, delivery.id, delivery.body)
+	if err == nil {
+		logger.Debug("webhook delivered")
+		d.finish(delivery, nil)
+		return
+	}
+	logger.WithError(err).Warn("webhook delivery failed")
+	if delivery.attempt >= d.MaxAttempts {
+		d.finish(delivery, err)
+		return
+	}
+
+	go func() {
+		select {
+		case <-time.After(d.backoff(delivery.attempt)):
+			d.queue <- delivery
+		case <-d.done:
+			d.finish(delivery, fmt.Errorf("retries stopped by shutdown: %w", err))
+		}
+	}()
+}
+
+// finish ends a pending delivery, dead-lettering it when it failed
+func (d *Dispatcher) finish(delivery *delivery, err error) {
+	defer d.wg.Done()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pending--
+	if err != nil {
+		delivery.logger.WithField("attempts", delivery.attempt).Error("webhook delivery dead-lettered")
+		d.deadLetter(delivery, err)
+	}
+}
+
+// deadLetter keeps an abandoned delivery, the lock must be held
+func (d *Dispatcher) deadLetter(delivery *delivery, err error) {
+	if len(d.deadLetters) >= maxDeadLetters {
+		d.deadLetters = d.deadLetters[1:]
+	}
+	d.deadLetters = append(d.deadLetters, DeadLetter{
+		DeliveryID:   delivery.id,
+		Tenant:       delivery.tenant,
+		Subscription: delivery.subscription,
+		Event:        delivery.event,
+		Attempts:     delivery.attempt,
+		LastError:    err.Error(),
+		FailedAt:     d.Now().UTC(),
+	})
+}
+
+// attempt sends a signed delivery, any response other than 2xx is a failure
+func (d *Dispatcher) attempt(subscription Subscription, event models.WebhookEvent, deliveryID string, body []byte) error {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(EventIDHeader, event.ID)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, d.Now(), body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff returns the exponential wait before the retry following attempt, with jitter
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	wait := d.MinBackoff << (attempt - 1)
+	if wait <= 0 || wait > d.MaxBackoff {
+		wait = d.MaxBackoff
+	}
+	return wait/2 + time.Duration(mathrand.Int63n(int64(wait/2)+1))
+}
+
+func newID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
@@ -0,0 +1,253 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/models"
+	"github.com/volume/service/user-flight-tracking/tenancy"
+	"github.com/volume/service/user-flight-tracking/webhooks"
+)
+
+const secret = "0123456789abcdef"
+
+// receiver records the deliveries of a local webhook endpoint, failing the first ones
+type receiver struct {
+	mu         sync.Mutex
+	failures   int
+	attempts   int
+	deliveries []*http.Request
+	events     []models.WebhookEvent
+	bodies     [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.attempts++
+	if rc.attempts <= rc.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var event models.WebhookEvent
+	_ = json.Unmarshal(body, &event)
+	rc.deliveries = append(rc.deliveries, r)
+	rc.events = append(rc.events, event)
+	rc.bodies = append(rc.bodies, body)
+}
+
+func (rc *receiver) delivered() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.deliveries)
+}
+
+func TestWebhooks_NewDispatcher(t *testing.T) {
+	var (
+		logger = log.NewEntry(log.New())
+		store  = webhooks.NewMemorySubscriptionStore()
+		client = http.DefaultClient
+	)
+
+	tests := []struct {
+		name        string
+		logger      *log.Entry
+		store       webhooks.SubscriptionStore
+		client      *http.Client
+		maxAttempts int
+		minBackoff  time.Duration
+		maxBackoff  time.Duration
+		wantError   error
+	}{
+		{name: "should_return_success", logger: logger, store: store, client: client, maxAttempts: 1, minBackoff: time.Second, maxBackoff: time.Second},
+		{name: "should_return_error_when_the_logger_is_nil", store: store, client: client, maxAttempts: 1, minBackoff: time.Second, maxBackoff: time.Second, wantError: errors.New("logger")},
+		{name: "should_return_error_when_the_store_is_nil", logger: logger, client: client, maxAttempts: 1, minBackoff: time.Second, maxBackoff: time.Second, wantError: errors.New("store")},
+		{name: "should_return_error_when_the_client_is_nil", logger: logger, store: store, maxAttempts: 1, minBackoff: time.Second, maxBackoff: time.Second, wantError: errors.New("client")},
+		{name: "should_return_error_without_attempts", logger: logger, store: store, client: client, minBackoff: time.Second, maxBackoff: time.Second, wantError: errors.New("maxAttempts")},
+		{name: "should_return_error_when_the_backoff_is_inverted", logger: logger, store: store, client: client, maxAttempts: 1, minBackoff: time.Minute, maxBackoff: time.Second, wantError: errors.New("backoff")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := webhooks.NewDispatcher(tt.logger, tt.store, tt.client, tt.maxAttempts, tt.minBackoff, tt.maxBackoff)
+			if tt.wantError != nil {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWebhooks_Publish(t *testing.T) {
+	setup := func(t *testing.T, rc *receiver, maxAttempts int) (*webhooks.Dispatcher, webhooks.SubscriptionStore, *httptest.Server) {
+		server := httptest.NewServer(rc)
+		t.Cleanup(server.Close)
+
+		store := webhooks.NewMemorySubscriptionStore()
+		d, err := webhooks.NewDispatcher(log.NewEntry(log.New()), store, server.Client(), maxAttempts, time.Millisecond, 5*time.Millisecond)
+		require.NoError(t, err)
+		return d, store, server
+	}
+
+	event := models.WebhookEvent{
+		Type: models.WebhookEventPathComputed,
+		Data: models.WebhookEventData{Legs: 2, Start: "SFO", End: "EWR", Path: []string{"SFO", "ATL", "EWR"}},
+	}
+
+	t.Run("should_deliver_signed_events_to_the_matching_subscriptions", func(t *testing.T) {
+		rc := &receiver{}
+		d, store, server := setup(t, rc, 1)
+
+		subscriptions := []webhooks.Subscription{
+			{ID: "computed", Owner: "api_key:batch", URL: server.URL, Events: []string{models.WebhookEventPathComputed}, Secret: secret},
+			{ID: "failed", Owner: "api_key:batch", URL: server.URL, Events: []string{models.WebhookEventPathFailed}, Secret: secret},
+			{ID: "other", Owner: "api_key:other", URL: server.URL, Events: []string{models.WebhookEventPathComputed}, Secret: secret},
+		}
+		for _, subscription := range subscriptions {
+			require.NoError(t, store.Add(context.Background(), subscription))
+		}
+
+		d.Publish(context.Background(), "api_key:batch", event)
+		require.NoError(t, d.Shutdown(context.Background()))
+
+		require.Equal(t, 1, rc.delivered())
+		delivery := rc.deliveries[0]
+		assert.Equal(t, models.WebhookEventPathComputed, delivery.Header.Get(webhooks.EventHeader))
+		assert.Equal(t, rc.events[0].ID, delivery.Header.Get(webhooks.EventIDHeader))
+		assert.Assert(t, delivery.Header.Get(webhooks.DeliveryHeader) != "")
+		assert.NilError(t, webhooks.Verify(secret, delivery.Header.Get(webhooks.SignatureHeader), rc.bodies[0], time.Minute, time.Now()))
+		assert.DeepEqual(t, event.Data, rc.events[0].Data)
+		assert.Assert(t, !rc.events[0].CreatedAt.IsZero())
+	})
+
+	t.Run("should_retry_failed_deliveries", func(t *testing.T) {
+		rc := &receiver{failures: 2}
+		d, store, server := setup(t, rc, 3)
+		require.NoError(t, store.Add(context.Background(), webhooks.Subscription{
+			ID: "computed", URL: server.URL, Events: []string{models.WebhookEventPathComputed}, Secret: secret,
+		}))
+
+		d.Publish(context.Background(), "", event)
+		waitFor(t, func() bool { return rc.delivered() == 1 })
+		require.NoError(t, d.Shutdown(context.Background()))
+
+		assert.Equal(t, 3, rc.attempts)
+		assert.Equal(t, 0, len(d.DeadLetters("")))
+	})
+
+	t.Run("should_dead_letter_after_the_last_attempt", func(t *testing.T) {
+		rc := &receiver{failures: 10}
+		d, store, server := setup(t, rc, 3)
+		require.NoError(t, store.Add(context.Background(), webhooks.Subscription{
+			ID: "computed", Owner: "api_key:batch", URL: server.URL, Events: []string{models.WebhookEventPathComputed}, Secret: secret,
+		}))
+
+		d.Publish(context.Background(), "api_key:batch", event)
+		waitFor(t, func() bool { return len(d.DeadLetters("api_key:batch")) == 1 })
+		require.NoError(t, d.Shutdown(context.Background()))
+
+		deadLetter := d.DeadLetters("api_key:batch")[0]
+		assert.Equal(t, 3, rc.attempts)
+		assert.Equal(t, 3, deadLetter.Attempts)
+		assert.Equal(t, "computed", deadLetter.Subscription.ID)
+		assert.Equal(t, "unexpected status 503", deadLetter.LastError)
+		assert.Equal(t, models.WebhookEventPathComputed, deadLetter.Event.Type)
+		assert.Equal(t, 0, len(d.DeadLetters("api_key:other")))
+	})
+
+	t.Run("should_dead_letter_when_the_queue_is_full", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-release }))
+		t.Cleanup(server.Close)
+		store := webhooks.NewMemorySubscriptionStore()
+		d, err := webhooks.NewDispatcher(log.NewEntry(log.New()), store, server.Client(), 1, time.Millisecond, time.Millisecond)
+		require.NoError(t, err)
+
+		// the workers wait for the receiver, the last delivery finds the queue full
+		for i := 0; i <= 1000; i++ {
+			require.NoError(t, store.Add(context.Background(), webhooks.Subscription{
+				ID: fmt.Sprint(i), Owner: "api_key:batch", URL: server.URL, Events: []string{models.WebhookEventPathComputed}, Secret: secret,
+			}))
+		}
+		d.Publish(context.Background(), "api_key:batch", event)
+		deadLetters := d.DeadLetters("api_key:batch")
+		close(release)
+		require.NoError(t, d.Shutdown(context.Background()))
+
+		require.Equal(t, 1, len(deadLetters))
+		assert.Equal(t, 0, deadLetters[0].Attempts)
+		assert.Equal(t, "delivery queue full", deadLetters[0].LastError)
+		assert.Equal(t, 1, len(d.DeadLetters("api_key:batch")))
+	})
+
+	t.Run("should_erase_the_dead_letters_of_a_traveler", func(t *testing.T) {
+		rc := &receiver{failures: 10}
+		d, store, server := setup(t, rc, 1)
+		require.NoError(t, store.Add(context.Background(), webhooks.Subscription{
+			ID: "computed", Owner: "acme/api_key:batch", URL: server.URL, Events: []string{models.WebhookEventPathComputed}, Secret: secret,
+		}))
+
+		acme := tenancy.NewContext(context.Background(), tenancy.Tenant{ID: "acme"})
+		for _, userID := range []string{"u-1", "u-2"} {
+			event := event
+			event.Data.UserID = userID
+			d.Publish(acme, "acme/api_key:batch", event)
+		}
+		waitFor(t, func() bool { return len(d.DeadLetters("acme/api_key:batch")) == 2 })
+		require.NoError(t, d.Shutdown(context.Background()))
+
+		erased, err := d.Erase(context.Background(), "u-1")
+		require.NoError(t, err)
+		assert.Equal(t, 0, erased, "the dead letters of another tenant should be kept")
+
+		erased, err = d.Erase(acme, "u-1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, erased)
+
+		deadLetters := d.DeadLetters("acme/api_key:batch")
+		require.Equal(t, 1, len(deadLetters))
+		assert.Equal(t, "u-2", deadLetters[0].Event.Data.UserID)
+		assert.Equal(t, "acme", deadLetters[0].Tenant)
+	})
+
+	t.Run("should_not_deliver_after_shutdown", func(t *testing.T) {
+		rc := &receiver{}
+		d, store, server := setup(t, rc, 1)
+		require.NoError(t, store.Add(context.Background(), webhooks.Subscription{
+			ID: "computed", URL: server.URL, Events: []string{models.WebhookEventPathComputed}, Secret: secret,
+		}))
+
+		require.NoError(t, d.Shutdown(context.Background()))
+		d.Publish(context.Background(), "", event)
+
+		assert.Equal(t, 0, rc.delivered())
+	})
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		require.True(t, time.Now().Before(deadline), "condition not met in time")
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,47 @@
+package webhooks_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/volume/service/user-flight-tracking/webhooks"
+)
+
+func TestWebhooks_Verify(t *testing.T) {
+	var (
+		secret = "0123456789abcdef"
+		sentAt = time.Unix(1686650400, 0)
+		body   = []byte(`{"id":"1","type":"path.computed"}`)
+		header = webhooks.Sign(secret, sentAt, body)
+	)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		body    []byte
+		now     time.Time
+		wantErr error
+	}{
+		{name: "should_accept_a_valid_signature", secret: secret, header: header, body: body, now: sentAt.Add(time.Minute)},
+		{name: "should_accept_one_of_several_signatures", secret: secret, header: "t=1686650400,v1=00," + header[len("t=1686650400,"):], body: body, now: sentAt},
+		{name: "should_reject_a_tampered_body", secret: secret, header: header, body: []byte(`{"id":"2"}`), now: sentAt, wantErr: webhooks.ErrInvalidSignature},
+		{name: "should_reject_another_secret", secret: "fedcba9876543210", header: header, body: body, now: sentAt, wantErr: webhooks.ErrInvalidSignature},
+		{name: "should_reject_an_old_signature", secret: secret, header: header, body: body, now: sentAt.Add(10 * time.Minute), wantErr: webhooks.ErrInvalidSignature},
+		{name: "should_reject_a_malformed_header", secret: secret, header: "v1=abc", body: body, now: sentAt, wantErr: webhooks.ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := webhooks.Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now)
+			if tt.wantErr != nil {
+				assert.Assert(t, errors.Is(err, tt.wantErr))
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
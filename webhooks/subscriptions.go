@@ -0,0 +1,98 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned for unknown subscriptions
+var ErrNotFound = errors.New("subscription not found")
+
+// Subscription describes where and how the events of an owner are delivered
+type Subscription struct {
+	ID string
+	// Owner identifies the caller that registered the subscription, empty for anonymous callers
+	Owner  string
+	URL    string
+	Events []string
+	// Secret is the key of the delivery signatures
+	Secret    string
+	CreatedAt time.Time
+}
+
+// Accepts reports whether the subscription receives events of the given type
+func (s Subscription) Accepts(eventType string) bool {
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// SubscriptionStore keeps the subscriptions, implementations must be safe for concurrent use
+type SubscriptionStore interface {
+	Add(ctx context.Context, subscription Subscription) error
+	// Delete removes the subscription of owner, returning ErrNotFound when there is none
+	Delete(ctx context.Context, owner, id string) error
+	// List returns the subscriptions of owner, oldest first
+	List(ctx context.Context, owner string) ([]Subscription, error)
+}
+
+// memorySubscriptionStore keeps the subscriptions of a single instance in memory
+type memorySubscriptionStore struct {
+	mu            sync.Mutex
+	subscriptions map[string]map[string]Subscription
+}
+
+// NewMemorySubscriptionStore returns a SubscriptionStore local to the process
+func NewMemorySubscriptionStore() SubscriptionStore {
+	return &memorySubscriptionStore{subscriptions: make(map[string]map[string]Subscription)}
+}
+
+func (s *memorySubscriptionStore) Add(_ context.Context, subscription Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owned, ok := s.subscriptions[subscription.Owner]
+	if !ok {
+		owned = make(map[string]Subscription)
+		s.subscriptions[subscription.Owner] = owned
+	}
+	owned[subscription.ID] = subscription
+
+	return nil
+}
+
+func (s *memorySubscriptionStore) Delete(_ context.Context, owner, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscriptions[owner][id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.subscriptions[owner], id)
+
+	return nil
+}
+
+func (s *memorySubscriptionStore) List(_ context.Context, owner string) ([]Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscriptions := make([]Subscription, 0, len(s.subscriptions[owner]))
+	for _, subscription := range s.subscriptions[owner] {
+		subscriptions = append(subscriptions, subscription)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].CreatedAt.Equal(subscriptions[j].CreatedAt) {
+			return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+		}
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+
+	return subscriptions, nil
+}